@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Access Request Handlers ---
+
+// GetAccessRequestsHandler lists access requests, optionally filtered by employee, status,
+// kind, or the approval step they wait for.
+func GetAccessRequestsHandler(c *gin.Context) {
+	page, err := paginationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := services.AccessRequestFilter{
+		EmployeeID: c.Query("employeeId"),
+		Status:     c.Query("status"),
+		Kind:       c.Query("kind"),
+		Step:       c.Query("step"),
+	}
+
+	requests, err := services.GetAccessRequests(c.Request.Context(), c.GetString("tenantId"), filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetAccessRequestByIDHandler returns one access request with its approvals.
+func GetAccessRequestByIDHandler(c *gin.Context) {
+	request, err := services.GetAccessRequest(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// ApproveAccessRequestHandler approves the step a request is waiting for.
+func ApproveAccessRequestHandler(c *gin.Context) {
+	decideAccessRequest(c, true)
+}
+
+// RejectAccessRequestHandler rejects the step a request is waiting for, ending the request.
+func RejectAccessRequestHandler(c *gin.Context) {
+	decideAccessRequest(c, false)
+}
+
+func decideAccessRequest(c *gin.Context, approve bool) {
+	request, ok := bindDecision(c)
+	if !ok {
+		return
+	}
+
+	decided, err := services.DecideAccessRequest(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), approve, request.Comment)
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, decided)
+}
+
+// CancelAccessRequestHandler withdraws a request that has not been carried out yet.
+func CancelAccessRequestHandler(c *gin.Context) {
+	cancelled, err := services.CancelAccessRequest(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"))
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cancelled)
+}
+
+// CompleteAccessRequestHandler records that an approved request was carried out, making
+// the access granted or revoked.
+func CompleteAccessRequestHandler(c *gin.Context) {
+	completed, err := services.CompleteAccessRequest(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"))
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, completed)
+}
+
+// RequestAccessRevocationHandler opens a request to revoke granted access.
+func RequestAccessRevocationHandler(c *gin.Context) {
+	var request struct {
+		Justification string `json:"justification" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revocation, err := services.RequestAccessRevocation(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), request.Justification)
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, revocation)
+}
+
+// --- Employee Access Request Handlers ---
+
+// CreateAccessRequestHandler asks for an access level to be granted to an employee.
+func CreateAccessRequestHandler(c *gin.Context) {
+	var input services.AccessRequestInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := services.CreateAccessRequest(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), &input)
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, request)
+}
+
+// GetEmployeeAccessRequestsHandler lists every access request of an employee.
+func GetEmployeeAccessRequestsHandler(c *gin.Context) {
+	filter := services.AccessRequestFilter{EmployeeID: c.Param("id")}
+	requests, err := services.GetAccessRequests(c.Request.Context(), c.GetString("tenantId"), filter, services.Pagination{})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+	"github.com/your-username/onboarding/utils"
+)
+
+// reportFormats maps each analytics report format besides JSON to its MIME type.
+var reportFormats = map[string]string{
+	"csv":  exportContentTypes["csv"],
+	"xlsx": exportContentTypes["xlsx"],
+}
+
+// --- Analytics Handlers ---
+
+// dateRangeFromQuery reads the optional "from" and "to" dates (YYYY-MM-DD) of a report.
+func dateRangeFromQuery(c *gin.Context) (services.DateRange, bool) {
+	var dates services.DateRange
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &dates.From}, {"to", &dates.To}} {
+		name := bound.name
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a date such as 2026-03-01"})
+			return dates, false
+		}
+		*bound.target = parsed
+	}
+	if !dates.From.IsZero() && !dates.To.IsZero() && dates.To.Before(dates.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return dates, false
+	}
+	return dates, true
+}
+
+// reportFormatFromQuery reads the optional "format" of a report: json (the default), csv or xlsx.
+func reportFormatFromQuery(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", "json")
+	if _, ok := reportFormats[format]; !ok && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of json, csv or xlsx"})
+		return "", false
+	}
+	return format, true
+}
+
+// groupByFromQuery reads the optional "groupBy" of a report, a key of services.AnalyticsGroups.
+func groupByFromQuery(c *gin.Context) (string, bool) {
+	groupBy := c.Query("groupBy")
+	if _, ok := services.AnalyticsGroups[groupBy]; groupBy != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "groupBy must be one of department, location, employmentType, team, jobRole or costCenter"})
+		return "", false
+	}
+	return groupBy, true
+}
+
+// writeReport sends the rows of a report as JSON, or as a CSV or XLSX download.
+func writeReport[T services.ReportRow](c *gin.Context, format, name string, columns []string, rows []T) {
+	if format == "json" {
+		c.JSON(http.StatusOK, rows)
+		return
+	}
+
+	c.Header("Content-Type", reportFormats[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	c.Status(http.StatusOK)
+	writer, err := utils.NewRowWriter(format, c.Writer, columns)
+	if err == nil {
+		for _, row := range rows {
+			if err = writer.WriteRow(row.Cells()); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+	}
+	if err != nil {
+		// The status line is already sent, so all we can do is log and cut the download short.
+		log.Printf("Writing the %s report for tenant %s failed: %v", name, c.GetString("tenantId"), err)
+	}
+}
+
+// GetHiresReportHandler counts new hires per month of their onboarding date. from and to
+// limit the dates and groupBy splits each month by department, location, employment type,
+// team, job role or cost center.
+func GetHiresReportHandler(c *gin.Context) {
+	format, ok := reportFormatFromQuery(c)
+	if !ok {
+		return
+	}
+	dates, ok := dateRangeFromQuery(c)
+	if !ok {
+		return
+	}
+	groupBy, ok := groupByFromQuery(c)
+	if !ok {
+		return
+	}
+	rows, err := services.GetHiresReport(c.Request.Context(), c.GetString("tenantId"), dates, groupBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the hires report"})
+		return
+	}
+	writeReport(c, format, "hires", services.HiresReportColumns, rows)
+}
+
+// GetUpcomingReportHandler lists the employees onboarding in the next days (30 by default).
+func GetUpcomingReportHandler(c *gin.Context) {
+	format, ok := reportFormatFromQuery(c)
+	if !ok {
+		return
+	}
+	days := services.DefaultUpcomingDays
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > services.MaxUpcomingDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be an integer from 1 to %d", services.MaxUpcomingDays)})
+			return
+		}
+		days = parsed
+	}
+	rows, err := services.GetUpcomingReport(c.Request.Context(), c.GetString("tenantId"), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the upcoming onboardings report"})
+		return
+	}
+	writeReport(c, format, "upcoming-onboardings", services.UpcomingReportColumns, rows)
+}
+
+// GetCompletionReportHandler reports how many days onboarding took for the employees who
+// completed it between from and to, optionally per group.
+func GetCompletionReportHandler(c *gin.Context) {
+	format, ok := reportFormatFromQuery(c)
+	if !ok {
+		return
+	}
+	dates, ok := dateRangeFromQuery(c)
+	if !ok {
+		return
+	}
+	groupBy, ok := groupByFromQuery(c)
+	if !ok {
+		return
+	}
+	rows, err := services.GetCompletionReport(c.Request.Context(), c.GetString("tenantId"), dates, groupBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the time-to-complete report"})
+		return
+	}
+	writeReport(c, format, "time-to-complete", services.CompletionReportColumns, rows)
+}
+
+// GetSpanOfControlReportHandler lists the number of direct reports of every manager in the
+// org chart.
+func GetSpanOfControlReportHandler(c *gin.Context) {
+	format, ok := reportFormatFromQuery(c)
+	if !ok {
+		return
+	}
+	rows, err := services.GetSpanOfControlReport(c.Request.Context(), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the span of control report"})
+		return
+	}
+	writeReport(c, format, "span-of-control", services.SpanOfControlColumns, rows)
+}
+
+// GetEntityUsageReportHandler counts the employees referencing each entity. entityType
+// limits it to one entity type, and from and to to employees onboarding in that period.
+func GetEntityUsageReportHandler(c *gin.Context) {
+	format, ok := reportFormatFromQuery(c)
+	if !ok {
+		return
+	}
+	dates, ok := dateRangeFromQuery(c)
+	if !ok {
+		return
+	}
+	entityType := c.Query("entityType")
+	if entityType != "" && !isEmployeeReference(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown entity type " + strconv.Quote(entityType)})
+		return
+	}
+	rows, err := services.GetEntityUsageReport(c.Request.Context(), c.GetString("tenantId"), entityType, dates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the entity usage report"})
+		return
+	}
+	writeReport(c, format, "entity-usage", services.EntityUsageColumns, rows)
+}
+
+// isEmployeeReference reports whether employees can reference entities of the type.
+func isEmployeeReference(slug string) bool {
+	for _, ref := range services.EmployeeReferences {
+		if ref.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// approvalError answers with the status that matches an error of an access or change request.
+func approvalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAccessRequestNotFound), errors.Is(err, services.ErrChangeRequestNotFound), errors.Is(err, services.ErrEmployeeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// decisionRequest is the body of approvals and rejections. It may be left out.
+type decisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// bindDecision reads the optional decision body.
+func bindDecision(c *gin.Context) (decisionRequest, bool) {
+	var request decisionRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return request, false
+	}
+	return request, true
+}
+
+// respondChangeHeld answers with 202 Accepted and the change request if an approval
+// policy held an update back, and reports whether it did.
+func respondChangeHeld(c *gin.Context, err error) bool {
+	var held *services.ChangeHeldError
+	if !errors.As(err, &held) {
+		return false
+	}
+	c.JSON(http.StatusAccepted, held.Request)
+	return true
+}
+
+// --- Approval Policy Handlers ---
+
+// GetApprovalPoliciesHandler lists the tenant's approval policies.
+func GetApprovalPoliciesHandler(c *gin.Context) {
+	policies, err := services.GetApprovalPolicies(c.Request.Context(), c.GetString("tenantId"), c.Query("entityType"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch approval policies"})
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+// CreateApprovalPolicyHandler adds an approval policy.
+func CreateApprovalPolicyHandler(c *gin.Context) {
+	var input services.ApprovalPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := services.CreateApprovalPolicy(c.Request.Context(), c.GetString("tenantId"), &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, policy)
+}
+
+// UpdateApprovalPolicyHandler replaces an approval policy.
+func UpdateApprovalPolicyHandler(c *gin.Context) {
+	var input services.ApprovalPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := services.UpdateApprovalPolicy(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), &input)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrApprovalPolicyNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeleteApprovalPolicyHandler removes an approval policy.
+func DeleteApprovalPolicyHandler(c *gin.Context) {
+	if err := services.DeleteApprovalPolicy(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Approval policy deleted successfully"})
+}
+
+// --- Change Request Handlers ---
+
+// GetChangeRequestsHandler lists change requests, optionally filtered by entity, status, or
+// the approval step they wait for.
+func GetChangeRequestsHandler(c *gin.Context) {
+	page, err := paginationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := services.ChangeRequestFilter{
+		EntityType: c.Query("entityType"),
+		EntityID:   c.Query("entityId"),
+		Status:     c.Query("status"),
+		Step:       c.Query("step"),
+	}
+
+	requests, err := services.GetChangeRequests(c.Request.Context(), c.GetString("tenantId"), filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetChangeRequestByIDHandler returns one change request with its diff and approvals.
+func GetChangeRequestByIDHandler(c *gin.Context) {
+	request, err := services.GetChangeRequest(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// ApproveChangeRequestHandler approves the step a change is waiting for, applying the
+// change after the last step.
+func ApproveChangeRequestHandler(c *gin.Context) {
+	decideChangeRequest(c, true)
+}
+
+// RejectChangeRequestHandler rejects the step a change is waiting for, dropping the change.
+func RejectChangeRequestHandler(c *gin.Context) {
+	decideChangeRequest(c, false)
+}
+
+func decideChangeRequest(c *gin.Context, approve bool) {
+	request, ok := bindDecision(c)
+	if !ok {
+		return
+	}
+
+	decided, err := services.DecideChangeRequest(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), approve, request.Comment)
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, decided)
+}
+
+// CancelChangeRequestHandler withdraws a pending change.
+func CancelChangeRequestHandler(c *gin.Context) {
+	cancelled, err := services.CancelChangeRequest(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"))
+	if err != nil {
+		approvalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cancelled)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Hardware Inventory Handlers ---
+
+// CreateAssetUnitHandler adds a unit of a hardware model to the stock.
+func CreateAssetUnitHandler(c *gin.Context) {
+	var input services.AssetUnitInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := services.CreateAssetUnit(c.Request.Context(), c.GetString("tenantId"), &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, unit)
+}
+
+// GetAssetUnitsHandler lists the tenant's units, optionally filtered by model, status or employee.
+func GetAssetUnitsHandler(c *gin.Context) {
+	page, err := paginationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := services.AssetUnitFilter{
+		ModelID:    c.Query("modelId"),
+		Status:     c.Query("status"),
+		EmployeeID: c.Query("employeeId"),
+	}
+
+	units, err := services.GetAssetUnits(c.Request.Context(), c.GetString("tenantId"), filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, units)
+}
+
+// GetAssetUnitByIDHandler returns one unit.
+func GetAssetUnitByIDHandler(c *gin.Context) {
+	unit, err := services.GetAssetUnit(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, unit)
+}
+
+// UpdateAssetUnitHandler replaces the description of a unit.
+func UpdateAssetUnitHandler(c *gin.Context) {
+	var input services.AssetUnitInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.UpdateAssetUnit(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), &input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Asset unit updated successfully"})
+}
+
+// DeleteAssetUnitHandler removes a unit that is not assigned.
+func DeleteAssetUnitHandler(c *gin.Context) {
+	if err := services.DeleteAssetUnit(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Asset unit deleted successfully"})
+}
+
+// SetAssetUnitStatusHandler sends an unassigned unit to repair, back to stock, or into retirement.
+func SetAssetUnitStatusHandler(c *gin.Context) {
+	var request struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.SetAssetUnitStatus(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), request.Status); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Asset unit status updated successfully"})
+}
+
+// AssignAssetUnitHandler hands a unit in stock to an employee.
+func AssignAssetUnitHandler(c *gin.Context) {
+	var request struct {
+		EmployeeID string `json:"employeeId" binding:"required"`
+		Notes      string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assignment, err := services.AssignAssetUnit(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), request.EmployeeID, c.GetString("userId"), request.Notes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// ReturnAssetUnitHandler takes an assigned unit back from its employee.
+func ReturnAssetUnitHandler(c *gin.Context) {
+	var input services.AssetReturn
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assignment, err := services.ReturnAssetUnit(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// GetAssetUnitAssignmentsHandler lists who held a unit and when.
+func GetAssetUnitAssignmentsHandler(c *gin.Context) {
+	assignments, err := services.GetAssetUnitAssignments(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
+
+// GetLowStockReportHandler compares the stock of every hardware model with the needs of
+// upcoming onboardings. The days query parameter sets how far ahead to look.
+func GetLowStockReportHandler(c *gin.Context) {
+	days := services.DefaultLowStockDays
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		days = parsed
+	}
+
+	report, err := services.GetLowStockReport(c.Request.Context(), c.GetString("tenantId"), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the low-stock report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// --- Employee Asset Handlers ---
+
+// GetEmployeeAssetsHandler lists the units an employee currently holds.
+func GetEmployeeAssetsHandler(c *gin.Context) {
+	filter := services.AssetUnitFilter{EmployeeID: c.Param("id"), Status: services.AssetAssigned}
+	units, err := services.GetAssetUnits(c.Request.Context(), c.GetString("tenantId"), filter, services.Pagination{})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, units)
+}
+
+// GetEmployeeAssetAssignmentsHandler lists every unit an employee has held.
+func GetEmployeeAssetAssignmentsHandler(c *gin.Context) {
+	assignments, err := services.GetEmployeeAssetAssignments(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Buddy Handlers ---
+
+// GetBuddyLoadsHandler lists every buddy with the number of new hires they look after.
+func GetBuddyLoadsHandler(c *gin.Context) {
+	loads, err := services.GetBuddyLoads(c.Request.Context(), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch buddy loads"})
+		return
+	}
+	c.JSON(http.StatusOK, loads)
+}
+
+// GetBuddyAssignmentsHandler lists buddy assignments, optionally for one buddy or new hire.
+func GetBuddyAssignmentsHandler(c *gin.Context) {
+	page, err := paginationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := services.BuddyAssignmentFilter{
+		BuddyID:    c.Query("buddyId"),
+		EmployeeID: c.Query("employeeId"),
+		OpenOnly:   c.Query("open") == "true",
+	}
+
+	assignments, err := services.GetBuddyAssignments(c.Request.Context(), c.GetString("tenantId"), filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
+
+// AddBuddyFeedbackHandler records an admin's rating of a buddy assignment.
+func AddBuddyFeedbackHandler(c *gin.Context) {
+	var input services.BuddyFeedbackInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assignment, err := services.AddBuddyFeedback(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// --- Employee Buddy Handlers ---
+
+// GetBuddySuggestionsHandler ranks the buddies with spare capacity for an employee.
+func GetBuddySuggestionsHandler(c *gin.Context) {
+	limit := 5
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := services.SuggestBuddies(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), limit)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// AssignBuddyHandler makes a buddy look after an employee. A buddy at capacity is only
+// assigned with "force": true.
+func AssignBuddyHandler(c *gin.Context) {
+	var request struct {
+		BuddyID string `json:"buddyId" binding:"required"`
+		Force   bool   `json:"force"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assignment, err := services.AssignBuddy(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), request.BuddyID, c.GetString("userId"), request.Force)
+	if respondChangeHeld(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
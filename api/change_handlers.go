@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// changeHeartbeatInterval is how often a comment is sent on an idle stream, so that
+// proxies do not close it.
+const changeHeartbeatInterval = 15 * time.Second
+
+// --- Real-time Handlers ---
+
+// StreamChangesHandler streams create, update and delete notifications for the tenant's
+// employees and entities as Server-Sent Events. Every event has an ID; a client that
+// reconnects with the Last-Event-ID header (or ?lastEventId=) receives the changes it missed.
+func StreamChangesHandler(c *gin.Context) {
+	resumeAfter := c.GetHeader("Last-Event-ID")
+	if resumeAfter == "" {
+		resumeAfter = c.Query("lastEventId")
+	}
+
+	ctx := c.Request.Context()
+	notifications := make(chan services.ChangeNotification)
+	done := make(chan error, 1)
+	go func() {
+		done <- services.WatchChanges(ctx, c.GetString("tenantId"), resumeAfter, func(n services.ChangeNotification) error {
+			select {
+			case notifications <- n:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	// Fail with a normal JSON error if the feed cannot start; otherwise open the stream.
+	select {
+	case err := <-done:
+		writeChangeFeedError(c, err)
+		return
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable response buffering in nginx
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(changeHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-done:
+			// The feed ended, e.g. because the resume position expired. Tell the client why;
+			// it reconnects on its own.
+			if err != nil {
+				data, _ := json.Marshal(gin.H{"error": err.Error()})
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", data)
+				c.Writer.Flush()
+			}
+			return
+		case n := <-notifications:
+			data, err := json.Marshal(n)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %s\nevent: change\ndata: %s\n\n", n.ID, data)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeChangeFeedError responds with the reason the change feed could not be opened.
+func writeChangeFeedError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrChangeFeedUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidResumeToken):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open the change feed"})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
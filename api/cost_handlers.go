@@ -0,0 +1,54 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Cost Center Handlers ---
+
+// monthFromQuery reads a month such as "2026-03" from the query, or returns fallback.
+func monthFromQuery(c *gin.Context, name string, fallback time.Time) (time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return fallback, true
+	}
+	month, err := time.Parse("2006-01", raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a month such as 2026-03"})
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// GetCostReportHandler compares planned onboardings per cost center and month with the
+// headcount budgets. It covers the months from "from" to "to", both included, and
+// defaults to the current month and the five after it.
+func GetCostReportHandler(c *gin.Context) {
+	now := time.Now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from, ok := monthFromQuery(c, "from", thisMonth)
+	if !ok {
+		return
+	}
+	to, ok := monthFromQuery(c, "to", from.AddDate(0, 5, 0))
+	if !ok {
+		return
+	}
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	if months < 1 || months > services.MaxCostReportMonths {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("to must not be before from, and the report covers at most %d months", services.MaxCostReportMonths)})
+		return
+	}
+
+	report, err := services.GetCostReport(c.Request.Context(), c.GetString("tenantId"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the cost report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Document Template Handlers (admin only) ---
+
+// GetDocumentTemplatesHandler lists the documents the tenant requires from new hires.
+func GetDocumentTemplatesHandler(c *gin.Context) {
+	templates, err := services.GetDocumentTemplates(c.Request.Context(), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch document templates"})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// CreateDocumentTemplateHandler adds a document template.
+func CreateDocumentTemplateHandler(c *gin.Context) {
+	var input services.DocumentTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := services.CreateDocumentTemplate(c.Request.Context(), c.GetString("tenantId"), &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateDocumentTemplateHandler replaces a document template.
+func UpdateDocumentTemplateHandler(c *gin.Context) {
+	var input services.DocumentTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.UpdateDocumentTemplate(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), &input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document template updated successfully"})
+}
+
+// DeleteDocumentTemplateHandler removes a document template.
+func DeleteDocumentTemplateHandler(c *gin.Context) {
+	if err := services.DeleteDocumentTemplate(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document template deleted successfully"})
+}
+
+// --- Employee Document Handlers ---
+
+// GetEmployeeDocumentsHandler lists the requested and uploaded documents of an employee.
+func GetEmployeeDocumentsHandler(c *gin.Context) {
+	documents, err := services.GetEmployeeDocuments(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, documents)
+}
+
+// RequestEmployeeDocumentHandler asks an existing employee for the document of a template.
+func RequestEmployeeDocumentHandler(c *gin.Context) {
+	var request struct {
+		TemplateID string `json:"templateId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	document, err := services.RequestDocument(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), request.TemplateID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, document)
+}
+
+// UploadEmployeeDocumentHandler stores a document for an employee. Pass the documentId
+// form field to upload a requested or rejected document.
+func UploadEmployeeDocumentHandler(c *gin.Context) {
+	uploadDocument(c, c.Param("id"), c.GetString("userId"))
+}
+
+// DownloadEmployeeDocumentHandler sends the file of an employee's document.
+func DownloadEmployeeDocumentHandler(c *gin.Context) {
+	serveDocument(c, c.Param("documentId"), c.Param("id"))
+}
+
+// ApproveEmployeeDocumentHandler accepts a submitted document.
+func ApproveEmployeeDocumentHandler(c *gin.Context) {
+	err := services.ReviewEmployeeDocument(c.Request.Context(), c.Param("documentId"), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), true, "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document approved"})
+}
+
+// RejectEmployeeDocumentHandler sends a submitted document back to the employee.
+func RejectEmployeeDocumentHandler(c *gin.Context) {
+	var request struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := services.ReviewEmployeeDocument(c.Request.Context(), c.Param("documentId"), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), false, request.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document rejected"})
+}
+
+// DeleteEmployeeDocumentHandler removes a document and its file.
+func DeleteEmployeeDocumentHandler(c *gin.Context) {
+	if err := services.DeleteEmployeeDocument(c.Request.Context(), c.Param("documentId"), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully"})
+}
+
+// --- Document Helpers ---
+
+// uploadDocument reads the "file" form field and stores it for the employee.
+func uploadDocument(c *gin.Context, employeeID, uploadedBy string) {
+	// Leave room for the multipart framing; the service enforces the exact limit.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxDocumentSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the 'file' form field (max 10 MB)"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read the uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	upload := &services.DocumentUpload{
+		DocumentID: c.PostForm("documentId"),
+		Name:       c.PostForm("name"),
+		FileName:   fileHeader.Filename,
+		UploadedBy: uploadedBy,
+	}
+	document, err := services.UploadEmployeeDocument(c.Request.Context(), employeeID, c.GetString("tenantId"), upload, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, document)
+}
+
+// serveDocument streams the file of an employee's document as an attachment.
+func serveDocument(c *gin.Context, documentID, employeeID string) {
+	document, content, err := services.OpenEmployeeDocument(c.Request.Context(), documentID, employeeID, c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": strings.TrimSpace(document.FileName)})
+	if disposition == "" {
+		disposition = "attachment" // The file name cannot be encoded
+	}
+	c.Header("Content-Type", document.ContentType)
+	c.Header("Content-Disposition", disposition)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		log.Printf("Could not send document %s: %v", document.ID.Hex(), err)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// exportContentTypes maps each supported export format to its MIME type.
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"ndjson": "application/x-ndjson",
+}
+
+// --- Export Handlers ---
+
+// ExportHandler returns the handler that streams all employees or entities of one type
+// as CSV (default), XLSX or NDJSON. It honours the same limit, offset, filter and view
+// parameters as the list endpoints, and ?denormalize=true replaces reference IDs with entity names.
+func ExportHandler(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := c.GetString("tenantId")
+
+		format := c.DefaultQuery("format", "csv")
+		contentType, ok := exportContentTypes[format]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of csv, xlsx or ndjson"})
+			return
+		}
+		page, err := listPageFromQuery(c, entityType)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		opts := services.ExportOptions{
+			Format:      format,
+			Denormalize: c.Query("denormalize") == "true",
+			Page:        page,
+		}
+
+		download := newDownload(c, contentType, fmt.Sprintf("%s.%s", entityType, format))
+		if err := services.Export(c.Request.Context(), download, tenantID, entityType, opts); err != nil {
+			// Once rows have been streamed the status line is already sent,
+			// so the best we can do is log the failure and cut the download short.
+			if download.started {
+				log.Printf("Export of %s for tenant %s failed: %v", entityType, tenantID, err)
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export " + entityType})
+			return
+		}
+		download.start()
+	}
+}
+
+// download writes a file attachment. The attachment headers are only set with the first
+// bytes, so an error that occurs before can still be sent as a normal JSON response.
+type download struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func newDownload(c *gin.Context, contentType, filename string) *download {
+	return &download{c: c, contentType: contentType, filename: filename}
+}
+
+// start sends the attachment headers, if that has not happened yet.
+func (d *download) start() {
+	if d.started {
+		return
+	}
+	d.started = true
+	d.c.Header("Content-Type", d.contentType)
+	d.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, d.filename))
+	d.c.Status(http.StatusOK)
+}
+
+func (d *download) Write(p []byte) (int, error) {
+	d.start()
+	return d.c.Writer.Write(p)
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -121,6 +123,16 @@ func LoginHandler(c *gin.Context) {
 	})
 }
 
+// RefreshTokenHandler exchanges a valid, unexpired JWT for a new one of the same session.
+func RefreshTokenHandler(c *gin.Context) {
+	token, err := services.RefreshToken(c.GetString("userId"), c.GetInt64("loginAt"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
 // --- Protected Handlers (Require JWT) ---
 func CreateEmployeeHandler(c *gin.Context) {
 	// --- 1. Fetch Tenant Permissions ---
@@ -157,13 +169,13 @@ func CreateEmployeeHandler(c *gin.Context) {
 
 	var missingFields []string
 	// Check for fundamental fields first
-	if _, ok := employeeData["firstName"]; !ok || employeeData["firstName"] == "" {
+	if val, ok := employeeData["firstName"].(string); !ok || val == "" {
 		missingFields = append(missingFields, "firstName")
 	}
-	if _, ok := employeeData["lastName"]; !ok || employeeData["lastName"] == "" {
+	if val, ok := employeeData["lastName"].(string); !ok || val == "" {
 		missingFields = append(missingFields, "lastName")
 	}
-	if _, ok := employeeData["email"]; !ok || employeeData["email"] == "" {
+	if val, ok := employeeData["email"].(string); !ok || val == "" {
 		missingFields = append(missingFields, "email")
 	}
 
@@ -190,12 +202,18 @@ func CreateEmployeeHandler(c *gin.Context) {
 	// We can now safely create the employee struct
 	var employee models.Employee
 	// This is a simple way to map, for more complex scenarios a library like 'mapstructure' could be used.
+	// The required string fields were checked above, so these assertions cannot fail.
 	employee.FirstName = employeeData["firstName"].(string)
 	employee.LastName = employeeData["lastName"].(string)
 	employee.Email = employeeData["email"].(string)
 
 	if val, ok := employeeData["phoneNumber"]; ok {
-		employee.PhoneNumber = val.(string)
+		phoneNumber, isString := val.(string)
+		if !isString {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "phoneNumber must be a string"})
+			return
+		}
+		employee.PhoneNumber = phoneNumber
 	} else {
 		employee.PhoneNumber = "" // Default to empty if not provided
 	}
@@ -218,7 +236,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 
 	// Assign optional fields if they exist
 	if val, ok := employeeData["locationId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for locationId"})
 			return
@@ -226,7 +244,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.LocationID = id
 	}
 	if val, ok := employeeData["departmentId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for departmentId"})
 			return
@@ -234,7 +252,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.DepartmentID = id
 	}
 	if val, ok := employeeData["managerId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for managerId"})
 			return
@@ -242,7 +260,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.ManagerID = id
 	}
 	if val, ok := employeeData["jobRoleId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for jobRoleId"})
 			return
@@ -250,7 +268,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.JobRoleID = id
 	}
 	if val, ok := employeeData["employmentTypeId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for employmentTypeId"})
 			return
@@ -258,7 +276,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.EmploymentTypeID = id
 	}
 	if val, ok := employeeData["teamId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for teamId"})
 			return
@@ -266,7 +284,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.TeamID = id
 	}
 	if val, ok := employeeData["costCenterId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for costCenterId"})
 			return
@@ -274,7 +292,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.CostCenterID = id
 	}
 	if val, ok := employeeData["hardwareAssetId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for hardwareAssetId"})
 			return
@@ -282,7 +300,7 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.HardwareAssetID = id
 	}
 	if val, ok := employeeData["onboardingBuddyId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for onboardingBuddyId"})
 			return
@@ -290,17 +308,29 @@ func CreateEmployeeHandler(c *gin.Context) {
 		employee.OnboardingBuddyID = id
 	}
 	if val, ok := employeeData["accessLevelId"]; ok {
-		id, err := primitive.ObjectIDFromHex(val.(string))
+		id, err := services.ObjectIDValue(val)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for accessLevelId"})
 			return
 		}
 		employee.AccessLevelID = id
 	}
+	if val, ok := employeeData["reportsToId"]; ok {
+		id, err := services.ObjectIDValue(val)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for reportsToId"})
+			return
+		}
+		employee.ReportsToID = id
+	}
 
 	employee.TenantID = tenantID // Set tenantID from the JWT context
 
 	createdEmployee, err := services.CreateEmployee(&employee)
+	if errors.Is(err, services.ErrUnknownManager) || errors.Is(err, services.ErrAccessLevelReadOnly) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create employee: " + err.Error()})
 		return
@@ -310,7 +340,12 @@ func CreateEmployeeHandler(c *gin.Context) {
 
 func GetEmployeesHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	employees, err := services.GetEmployeesByTenant(tenantID)
+	page, err := listPageFromQuery(c, "employees")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	employees, err := services.GetEmployeesByTenant(tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch employees"})
 		return
@@ -338,8 +373,16 @@ func UpdateEmployeeHandler(c *gin.Context) {
 		return
 	}
 
-	if err := services.UpdateEmployee(id, tenantID, updateData); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	err := services.UpdateEmployee(id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrEmployeeNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Employee updated successfully"})
@@ -348,13 +391,45 @@ func UpdateEmployeeHandler(c *gin.Context) {
 func DeleteEmployeeHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	if err := services.DeleteEmployee(id, tenantID); err != nil {
+	if err := services.DeleteEmployee(id, tenantID, c.GetString("userId")); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Employee deleted successfully"})
 }
 
+// paginationFromQuery reads the optional "limit" and "offset" query parameters.
+// Both default to zero, which returns every document.
+func paginationFromQuery(c *gin.Context) (services.Pagination, error) {
+	var page services.Pagination
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit < 0 {
+			return page, errors.New("limit must be a non-negative integer")
+		}
+		page.Limit = limit
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || offset < 0 {
+			return page, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = offset
+	}
+	return page, nil
+}
+
+// listPageFromQuery reads the pagination of a list of employees or an entity type (a slug),
+// plus the optional "filter" expression and saved "view" that narrow the list.
+func listPageFromQuery(c *gin.Context, entityType string) (services.Pagination, error) {
+	page, err := paginationFromQuery(c)
+	if err != nil {
+		return page, err
+	}
+	page.Filter, err = services.ListFilter(c.Request.Context(), c.GetString("tenantId"), c.GetString("userId"), entityType, c.Query("view"), c.Query("filter"))
+	return page, err
+}
+
 // --- Dynamic Entity Handlers ---
 // The pattern for all dynamic entities is the same. We define a few here as examples.
 
@@ -377,7 +452,12 @@ func CreateLocationHandler(c *gin.Context) {
 
 func GetLocationsHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.Location](c.Request.Context(), "locations", tenantID)
+	page, err := listPageFromQuery(c, "locations")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.Location](c.Request.Context(), "locations", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch locations"})
 		return
@@ -405,7 +485,10 @@ func UpdateLocationHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.Location](c.Request.Context(), "locations", id, tenantID, updateData)
+	err := services.UpdateEntity[models.Location](c.Request.Context(), "locations", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -416,7 +499,7 @@ func UpdateLocationHandler(c *gin.Context) {
 func DeleteLocationHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.Location](c.Request.Context(), "locations", id, tenantID)
+	err := services.DeleteEntity[models.Location](c.Request.Context(), "locations", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -432,6 +515,10 @@ func CreateDepartmentHandler(c *gin.Context) {
 		return
 	}
 	entity.TenantID = c.GetString("tenantId")
+	if err := services.CheckDepartmentParent(c.Request.Context(), entity.TenantID, "", entity.ParentID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	createdEntity, err := services.CreateEntity(c.Request.Context(), "departments", &entity)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create department"})
@@ -442,7 +529,12 @@ func CreateDepartmentHandler(c *gin.Context) {
 
 func GetDepartmentsHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.Department](c.Request.Context(), "departments", tenantID)
+	page, err := listPageFromQuery(c, "departments")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.Department](c.Request.Context(), "departments", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch departments"})
 		return
@@ -470,7 +562,24 @@ func UpdateDepartmentHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.Department](c.Request.Context(), "departments", id, tenantID, updateData)
+	// Departments nest, so a new parent must not be the department itself or one below it.
+	if value, ok := updateData["parentId"]; ok {
+		parentID, err := services.ObjectIDValue(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for parentId"})
+			return
+		}
+		if err := services.CheckDepartmentParent(c.Request.Context(), tenantID, id, parentID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updateData["parentId"] = parentID
+	}
+
+	err := services.UpdateEntity[models.Department](c.Request.Context(), "departments", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -481,7 +590,7 @@ func UpdateDepartmentHandler(c *gin.Context) {
 func DeleteDepartmentHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.Department](c.Request.Context(), "departments", id, tenantID)
+	err := services.DeleteDepartment(c.Request.Context(), id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -507,7 +616,12 @@ func CreateManagersHandler(c *gin.Context) {
 
 func GetManagersHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.Manager](c.Request.Context(), "managers", tenantID)
+	page, err := listPageFromQuery(c, "managers")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.Manager](c.Request.Context(), "managers", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch managers"})
 		return
@@ -535,7 +649,10 @@ func UpdateManagerHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.Manager](c.Request.Context(), "managers", id, tenantID, updateData)
+	err := services.UpdateEntity[models.Manager](c.Request.Context(), "managers", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -546,7 +663,7 @@ func UpdateManagerHandler(c *gin.Context) {
 func DeleteManagerHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.Manager](c.Request.Context(), "managers", id, tenantID)
+	err := services.DeleteEntity[models.Manager](c.Request.Context(), "managers", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -572,7 +689,12 @@ func CreateJobRoleHandler(c *gin.Context) {
 
 func GetJobRolesHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.JobRole](c.Request.Context(), "job_roles", tenantID)
+	page, err := listPageFromQuery(c, "job-roles")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.JobRole](c.Request.Context(), "job_roles", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job roles"})
 		return
@@ -600,7 +722,10 @@ func UpdateJobRoleHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.JobRole](c.Request.Context(), "job_roles", id, tenantID, updateData)
+	err := services.UpdateEntity[models.JobRole](c.Request.Context(), "job_roles", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -611,7 +736,7 @@ func UpdateJobRoleHandler(c *gin.Context) {
 func DeleteJobRoleHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.JobRole](c.Request.Context(), "job_roles", id, tenantID)
+	err := services.DeleteEntity[models.JobRole](c.Request.Context(), "job_roles", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -637,7 +762,12 @@ func CreateEmploymentTypeHandler(c *gin.Context) {
 
 func GetEmploymentTypesHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.EmploymentType](c.Request.Context(), "employment_types", tenantID)
+	page, err := listPageFromQuery(c, "employement-types")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.EmploymentType](c.Request.Context(), "employment_types", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch employment types"})
 		return
@@ -665,7 +795,10 @@ func UpdateEmploymentTypeHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.EmploymentType](c.Request.Context(), "employment_types", id, tenantID, updateData)
+	err := services.UpdateEntity[models.EmploymentType](c.Request.Context(), "employment_types", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -676,7 +809,7 @@ func UpdateEmploymentTypeHandler(c *gin.Context) {
 func DeleteEmploymentTypeHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.EmploymentType](c.Request.Context(), "employment_types", id, tenantID)
+	err := services.DeleteEntity[models.EmploymentType](c.Request.Context(), "employment_types", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -702,7 +835,12 @@ func CreateTeamHandler(c *gin.Context) {
 
 func GetTeamsHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.Team](c.Request.Context(), "teams", tenantID)
+	page, err := listPageFromQuery(c, "teams")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.Team](c.Request.Context(), "teams", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teams"})
 		return
@@ -730,7 +868,10 @@ func UpdateTeamHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.Team](c.Request.Context(), "teams", id, tenantID, updateData)
+	err := services.UpdateEntity[models.Team](c.Request.Context(), "teams", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -741,7 +882,7 @@ func UpdateTeamHandler(c *gin.Context) {
 func DeleteTeamHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.Team](c.Request.Context(), "teams", id, tenantID)
+	err := services.DeleteEntity[models.Team](c.Request.Context(), "teams", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -767,7 +908,12 @@ func CreateCostCenterHandler(c *gin.Context) {
 
 func GetCostCentersHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.CostCenter](c.Request.Context(), "cost_centers", tenantID)
+	page, err := listPageFromQuery(c, "costs")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.CostCenter](c.Request.Context(), "cost_centers", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cost centers"})
 		return
@@ -795,7 +941,10 @@ func UpdateCostCenterHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.CostCenter](c.Request.Context(), "cost_centers", id, tenantID, updateData)
+	err := services.UpdateEntity[models.CostCenter](c.Request.Context(), "cost_centers", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -806,7 +955,7 @@ func UpdateCostCenterHandler(c *gin.Context) {
 func DeleteCostCenterHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.CostCenter](c.Request.Context(), "cost_centers", id, tenantID)
+	err := services.DeleteEntity[models.CostCenter](c.Request.Context(), "cost_centers", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -832,7 +981,12 @@ func CreateHardwareAssetHandler(c *gin.Context) {
 
 func GetHardwareAssetsHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.HardwareAsset](c.Request.Context(), "hardware_assets", tenantID)
+	page, err := listPageFromQuery(c, "hardware-assets")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.HardwareAsset](c.Request.Context(), "hardware_assets", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hardware assets"})
 		return
@@ -860,7 +1014,10 @@ func UpdateHardwareAssetHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.HardwareAsset](c.Request.Context(), "hardware_assets", id, tenantID, updateData)
+	err := services.UpdateEntity[models.HardwareAsset](c.Request.Context(), "hardware_assets", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -871,7 +1028,7 @@ func UpdateHardwareAssetHandler(c *gin.Context) {
 func DeleteHardwareAssetHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.HardwareAsset](c.Request.Context(), "hardware_assets", id, tenantID)
+	err := services.DeleteEntity[models.HardwareAsset](c.Request.Context(), "hardware_assets", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -897,7 +1054,12 @@ func CreateOnboardingBuddyHandler(c *gin.Context) {
 
 func GetOnboardingBuddiesHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.OnboardingBuddy](c.Request.Context(), "onboarding_buddies", tenantID)
+	page, err := listPageFromQuery(c, "onboarding-buddy")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.OnboardingBuddy](c.Request.Context(), "onboarding_buddies", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch onboarding buddies"})
 		return
@@ -925,7 +1087,10 @@ func UpdateOnboardingBuddyHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.OnboardingBuddy](c.Request.Context(), "onboarding_buddies", id, tenantID, updateData)
+	err := services.UpdateEntity[models.OnboardingBuddy](c.Request.Context(), "onboarding_buddies", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -936,7 +1101,7 @@ func UpdateOnboardingBuddyHandler(c *gin.Context) {
 func DeleteOnboardingBuddyHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.OnboardingBuddy](c.Request.Context(), "onboarding_buddies", id, tenantID)
+	err := services.DeleteEntity[models.OnboardingBuddy](c.Request.Context(), "onboarding_buddies", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -962,7 +1127,12 @@ func CreateAccessLevelHandler(c *gin.Context) {
 
 func GetAccessLevelsHandler(c *gin.Context) {
 	tenantID := c.GetString("tenantId")
-	entities, err := services.GetEntitiesByTenant[models.AccessLevel](c.Request.Context(), "access_levels", tenantID)
+	page, err := listPageFromQuery(c, "access-levels")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entities, err := services.GetEntitiesByTenant[models.AccessLevel](c.Request.Context(), "access_levels", tenantID, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access levels"})
 		return
@@ -990,7 +1160,10 @@ func UpdateAccessLevelHandler(c *gin.Context) {
 		return
 	}
 
-	err := services.UpdateEntity[models.AccessLevel](c.Request.Context(), "access_levels", id, tenantID, updateData)
+	err := services.UpdateEntity[models.AccessLevel](c.Request.Context(), "access_levels", id, tenantID, c.GetString("userId"), updateData)
+	if respondChangeHeld(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -1001,7 +1174,7 @@ func UpdateAccessLevelHandler(c *gin.Context) {
 func DeleteAccessLevelHandler(c *gin.Context) {
 	id := c.Param("id")
 	tenantID := c.GetString("tenantId")
-	err := services.DeleteEntity[models.AccessLevel](c.Request.Context(), "access_levels", id, tenantID)
+	err := services.DeleteEntity[models.AccessLevel](c.Request.Context(), "access_levels", id, tenantID, c.GetString("userId"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
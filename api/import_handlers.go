@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+	"github.com/your-username/onboarding/utils"
+)
+
+// maxImportFileSize caps the size of uploaded import files (10 MB).
+const maxImportFileSize = 10 << 20
+
+// --- Bulk Import Handlers ---
+
+// ImportHandler returns the handler that accepts a CSV or XLSX file for employees or
+// an entity type. With ?dryRun=true it only returns the validation report. Otherwise, if
+// every row is valid (or ?skipInvalidRows=true is set), it starts an asynchronous import job.
+func ImportHandler(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := c.GetString("tenantId")
+
+		// 1. Read the uploaded file.
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the 'file' form field (max 10 MB)"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read the uploaded file"})
+			return
+		}
+		defer file.Close()
+
+		rows, err := utils.ReadSpreadsheet(fileHeader.Filename, file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Could not parse the uploaded file: " + err.Error()})
+			return
+		}
+
+		var opts services.ImportOptions
+		if mapping := c.PostForm("mapping"); mapping != "" {
+			if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "mapping must be a JSON object of column name to field name"})
+				return
+			}
+		}
+
+		// 2. Validate every row.
+		report, err := services.PrepareImport(c.Request.Context(), tenantID, entityType, rows, opts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if c.Query("dryRun") == "true" {
+			c.JSON(http.StatusOK, report)
+			return
+		}
+		if len(report.Errors) > 0 && c.Query("skipInvalidRows") != "true" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "The file contains invalid rows. Fix them or retry with skipInvalidRows=true.",
+				"report": report,
+			})
+			return
+		}
+
+		// 3. Commit the valid rows in the background.
+		job, err := services.StartImport(tenantID, c.GetString("userId"), fileHeader.Filename, report)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// GetImportsHandler lists the import jobs of the current tenant.
+func GetImportsHandler(c *gin.Context) {
+	jobs, err := services.GetImportJobsByTenant(c.Request.Context(), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch import jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetImportByIDHandler returns the status and progress of one import job.
+func GetImportByIDHandler(c *gin.Context) {
+	job, err := services.GetImportJob(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
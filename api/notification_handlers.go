@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/notifications"
+)
+
+// --- Notification Handlers (admin only) ---
+
+// GetNotificationTemplatesHandler lists the tenant's own email templates.
+func GetNotificationTemplatesHandler(c *gin.Context) {
+	templates, err := notifications.GetTemplates(c.Request.Context(), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification templates"})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetDefaultNotificationTemplatesHandler lists the built-in templates, which apply to every
+// trigger for which the tenant has no template of its own.
+func GetDefaultNotificationTemplatesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, notifications.DefaultTemplates)
+}
+
+// CreateNotificationTemplateHandler adds an email template.
+func CreateNotificationTemplateHandler(c *gin.Context) {
+	var input notifications.TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := notifications.CreateTemplate(c.Request.Context(), c.GetString("tenantId"), &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateNotificationTemplateHandler replaces an email template.
+func UpdateNotificationTemplateHandler(c *gin.Context) {
+	var input notifications.TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := notifications.UpdateTemplate(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), &input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification template updated successfully"})
+}
+
+// DeleteNotificationTemplateHandler removes an email template.
+func DeleteNotificationTemplateHandler(c *gin.Context) {
+	if err := notifications.DeleteTemplate(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification template deleted successfully"})
+}
+
+// PreviewNotificationTemplateHandler renders a template for an employee without sending it.
+func PreviewNotificationTemplateHandler(c *gin.Context) {
+	var request struct {
+		notifications.TemplateInput
+		EmployeeID string `json:"employeeId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subject, body, to, err := notifications.PreviewTemplate(c.Request.Context(), c.GetString("tenantId"), request.EmployeeID, &request.TemplateInput)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if to == nil {
+		to = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"to": to, "subject": subject, "body": body})
+}
+
+// GetNotificationMessagesHandler lists queued and sent emails, newest first.
+// Use ?status=failed to list emails that could not be delivered.
+func GetNotificationMessagesHandler(c *gin.Context) {
+	page, err := paginationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	messages, err := notifications.GetMessages(c.Request.Context(), c.GetString("tenantId"), c.Query("status"), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification messages"})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// RetryNotificationMessageHandler queues a failed email again.
+func RetryNotificationMessageHandler(c *gin.Context) {
+	if err := notifications.RetryMessage(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Message queued again"})
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// orgChartFormats maps each org chart export format to its MIME type and file extension.
+var orgChartFormats = map[string]struct{ contentType, extension string }{
+	"dot":     {"text/vnd.graphviz; charset=utf-8", "dot"},
+	"mermaid": {"text/plain; charset=utf-8", "mmd"},
+	"svg":     {"image/svg+xml", "svg"},
+}
+
+// --- Org Chart Handlers ---
+
+// depthFromQuery reads the optional "depth" query parameter; 0 means unlimited.
+func depthFromQuery(c *gin.Context) (int, bool) {
+	raw := c.Query("depth")
+	if raw == "" {
+		return 0, true
+	}
+	depth, err := strconv.Atoi(raw)
+	if err != nil || depth < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "depth must be a non-negative integer"})
+		return 0, false
+	}
+	return depth, true
+}
+
+// GetOrgChartHandler returns the whole reporting tree of the tenant.
+func GetOrgChartHandler(c *gin.Context) {
+	depth, ok := depthFromQuery(c)
+	if !ok {
+		return
+	}
+	chart, err := services.GetOrgChart(c.Request.Context(), c.GetString("tenantId"), depth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the org chart"})
+		return
+	}
+	c.JSON(http.StatusOK, chart)
+}
+
+// ExportOrgChartHandler renders the reporting tree as Graphviz DOT, Mermaid or SVG (the
+// default). departmentId and locationId narrow it down, labels picks the lines of each box
+// and depth limits the levels shown.
+func ExportOrgChartHandler(c *gin.Context) {
+	tenantID := c.GetString("tenantId")
+
+	format := c.DefaultQuery("format", "svg")
+	output, ok := orgChartFormats[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of dot, mermaid or svg"})
+		return
+	}
+	depth, ok := depthFromQuery(c)
+	if !ok {
+		return
+	}
+	labels, err := services.ParseOrgChartLabels(c.Query("labels"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := services.OrgChartExportOptions{Format: format, Labels: labels, Depth: depth}
+	for param, target := range map[string]*primitive.ObjectID{"departmentId": &opts.DepartmentID, "locationId": &opts.LocationID} {
+		if raw := c.Query(param); raw != "" {
+			if *target, err = primitive.ObjectIDFromHex(raw); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format for " + param})
+				return
+			}
+		}
+	}
+
+	// The chart is built completely before anything is written, so a failure while loading
+	// it is still reported as JSON.
+	download := newDownload(c, output.contentType, "org-chart."+output.extension)
+	if err := services.ExportOrgChart(c.Request.Context(), download, tenantID, opts); err != nil {
+		if download.started {
+			log.Printf("Org chart export for tenant %s failed: %v", tenantID, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export the org chart"})
+		return
+	}
+	download.start()
+}
+
+// GetReportingSubtreeHandler returns an employee with everyone below them.
+func GetReportingSubtreeHandler(c *gin.Context) {
+	depth, ok := depthFromQuery(c)
+	if !ok {
+		return
+	}
+	node, err := services.GetReportingSubtree(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), depth)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, node)
+}
+
+// GetChainOfCommandHandler returns an employee and their managers up to the top.
+func GetChainOfCommandHandler(c *gin.Context) {
+	chain, err := services.GetChainOfCommand(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, chain)
+}
+
+// GetDepartmentTreeHandler returns the departments nested by parent, with their teams.
+func GetDepartmentTreeHandler(c *gin.Context) {
+	tree, err := services.GetDepartmentTree(c.Request.Context(), c.GetString("tenantId"), "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the department tree"})
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetDepartmentSubtreeHandler returns a department with everything below it.
+func GetDepartmentSubtreeHandler(c *gin.Context) {
+	tree, err := services.GetDepartmentTree(c.Request.Context(), c.GetString("tenantId"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tree[0])
+}
+
+// GetDepartmentChainHandler returns a department and its parents up to the top.
+func GetDepartmentChainHandler(c *gin.Context) {
+	chain, err := services.GetDepartmentChain(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, chain)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/notifications"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Portal Link Handlers (admin only) ---
+
+// CreatePortalLinkHandler issues a magic link to the pre-boarding portal and emails it to
+// the employee. The URL is also returned, so it can be shared another way. Older links of
+// the employee stop working.
+func CreatePortalLinkHandler(c *gin.Context) {
+	tenantID := c.GetString("tenantId")
+	link, token, err := services.CreatePortalLink(c.Request.Context(), c.Param("id"), tenantID, c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	url := services.PortalURL(token)
+	emailQueued := false
+	if employee, err := services.GetEmployeeByID(c.Param("id"), tenantID); err == nil {
+		if err := notifications.QueuePortalInvite(c.Request.Context(), *employee, link, token); err != nil {
+			log.Printf("Could not queue the portal invitation for employee %s: %v", employee.ID.Hex(), err)
+		} else {
+			emailQueued = true
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"url":         url,
+		"expiresAt":   link.ExpiresAt,
+		"emailQueued": emailQueued,
+	})
+}
+
+// RevokePortalLinksHandler invalidates the employee's magic links.
+func RevokePortalLinksHandler(c *gin.Context) {
+	if err := services.RevokePortalLinks(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Portal links revoked successfully"})
+}
+
+// --- Portal Handlers (new hires) ---
+
+// StartPortalSessionHandler exchanges the token of a magic link for a portal token.
+func StartPortalSessionHandler(c *gin.Context) {
+	var request struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, expiresAt, err := services.StartPortalSession(c.Request.Context(), request.Token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token, "expiresAt": expiresAt})
+}
+
+// GetPortalProfileHandler shows the new hire their onboarding details.
+func GetPortalProfileHandler(c *gin.Context) {
+	profile, err := services.GetPortalProfile(c.Request.Context(), c.GetString("employeeId"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdatePortalProfileHandler saves the personal details entered by the new hire.
+func UpdatePortalProfileHandler(c *gin.Context) {
+	var input services.PortalProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := services.UpdatePortalProfile(c.Request.Context(), c.GetString("employeeId"), c.GetString("tenantId"), &input)
+	// The new hire only learns that the details wait for approval, not the change request.
+	var held *services.ChangeHeldError
+	if errors.As(err, &held) {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Profile changes will be saved once they are approved"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully"})
+}
+
+// GetPortalDocumentsHandler lists the new hire's documents.
+func GetPortalDocumentsHandler(c *gin.Context) {
+	documents, err := services.GetEmployeeDocuments(c.Request.Context(), c.GetString("employeeId"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, documents)
+}
+
+// UploadPortalDocumentHandler stores a document uploaded by the new hire. Pass the
+// documentId form field to upload a requested or rejected document.
+func UploadPortalDocumentHandler(c *gin.Context) {
+	uploadDocument(c, c.GetString("employeeId"), "portal")
+}
+
+// DownloadPortalDocumentHandler sends one of the new hire's documents.
+func DownloadPortalDocumentHandler(c *gin.Context) {
+	serveDocument(c, c.Param("id"), c.GetString("employeeId"))
+}
+
+// AddPortalBuddyFeedbackHandler records the new hire's rating of their buddy.
+func AddPortalBuddyFeedbackHandler(c *gin.Context) {
+	var input services.BuddyFeedbackInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := services.AddPortalBuddyFeedback(c.Request.Context(), c.GetString("employeeId"), c.GetString("tenantId"), &input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Feedback sent successfully"})
+}
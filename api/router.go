@@ -1,11 +1,13 @@
 package api
 
 import (
+	"log"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/your-username/onboarding/auth"
+	"github.com/your-username/onboarding/config"
 )
 
 func Default() gin.HandlerFunc {
@@ -25,23 +27,52 @@ func SetupRouter() *gin.Engine {
 
 	router.Use(Default())
 
+	// Every request is validated against openapi.yaml. Responses are only
+	// validated in the test environment, where spec drift should fail loudly.
+	specValidator, err := NewSpecValidator(config.AppConfig.OpenAPISpecPath, config.AppConfig.AppEnv == "test")
+	if err != nil {
+		log.Fatalf("Could not load OpenAPI spec: %v", err)
+	}
+
 	// --- Public Routes ---
 	// No authentication required for these.f
 	public := router.Group("/public")
+	public.Use(specValidator.Middleware())
 	{
 		public.POST("/signup", TenantSignupHandler)
 	}
 
 	// --- Authentication Routes ---
 	authRoutes := router.Group("/auth")
+	authRoutes.Use(specValidator.Middleware())
 	{
 		authRoutes.POST("/login", LoginHandler)
+		authRoutes.POST("/refresh", auth.AuthMiddleware(), RefreshTokenHandler)
+	}
+
+	// --- Pre-boarding Portal Routes ---
+	// New hires sign in with a magic link instead of a user account.
+	portal := router.Group("/portal")
+	portal.Use(specValidator.Middleware())
+	{
+		portal.POST("/session", StartPortalSessionHandler)
+
+		newHire := portal.Group("")
+		newHire.Use(auth.PortalMiddleware())
+		{
+			newHire.GET("/me", GetPortalProfileHandler)
+			newHire.PUT("/me", UpdatePortalProfileHandler)
+			newHire.GET("/documents", GetPortalDocumentsHandler)
+			newHire.POST("/documents", UploadPortalDocumentHandler)
+			newHire.GET("/documents/:id", DownloadPortalDocumentHandler)
+			newHire.POST("/buddy/feedback", AddPortalBuddyFeedbackHandler)
+		}
 	}
 
 	// --- Protected API Routes ---
 	// All routes in this group will be protected by the JWT AuthMiddleware.
 	api := router.Group("/api/v1")
-	api.Use(auth.AuthMiddleware())
+	api.Use(auth.AuthMiddleware(), specValidator.Middleware())
 	{
 		// Employee CRUD
 		employees := api.Group("/employees")
@@ -52,6 +83,33 @@ func SetupRouter() *gin.Engine {
 			employees.GET("/:id", GetEmployeeByIDHandler)
 			employees.PUT("/:id", UpdateEmployeeHandler)
 			employees.DELETE("/:id", DeleteEmployeeHandler)
+			employees.POST("/import", ImportHandler("employees"))
+			employees.GET("/export", ExportHandler("employees"))
+			employees.GET("/trash", GetTrashHandler("employees"))
+			employees.POST("/:id/restore", RestoreHandler("employees"))
+			employees.GET("/:id/versions", GetVersionsHandler("employees"))
+			employees.GET("/:id/versions/:version", GetVersionHandler("employees"))
+			employees.GET("/:id/as-of", GetRecordAsOfHandler("employees"))
+			employees.GET("/:id/diff", DiffVersionsHandler("employees"))
+			employees.GET("/:id/timeline", GetEmployeeTimelineHandler)
+			employees.GET("/:id/scheduled-changes", GetScheduledChangesHandler)
+			employees.POST("/:id/scheduled-changes", ScheduleEmployeeChangeHandler)
+			employees.POST("/:id/scheduled-changes/:changeId/cancel", CancelScheduledChangeHandler)
+			employees.GET("/:id/documents", GetEmployeeDocumentsHandler)
+			employees.POST("/:id/documents", UploadEmployeeDocumentHandler)
+			employees.POST("/:id/documents/requests", RequestEmployeeDocumentHandler)
+			employees.GET("/:id/documents/:documentId", DownloadEmployeeDocumentHandler)
+			employees.DELETE("/:id/documents/:documentId", auth.RequireAdmin(), DeleteEmployeeDocumentHandler)
+			employees.POST("/:id/documents/:documentId/approve", auth.RequireAdmin(), ApproveEmployeeDocumentHandler)
+			employees.POST("/:id/documents/:documentId/reject", auth.RequireAdmin(), RejectEmployeeDocumentHandler)
+			employees.GET("/:id/assets", auth.RequireEntityAccess("hardware-assets"), GetEmployeeAssetsHandler)
+			employees.GET("/:id/asset-assignments", auth.RequireEntityAccess("hardware-assets"), GetEmployeeAssetAssignmentsHandler)
+			employees.GET("/:id/buddy-suggestions", auth.RequireEntityAccess("onboarding-buddy"), GetBuddySuggestionsHandler)
+			employees.POST("/:id/buddy", auth.RequireEntityAccess("onboarding-buddy"), AssignBuddyHandler)
+			employees.GET("/:id/access-requests", auth.RequireEntityAccess("access-levels"), GetEmployeeAccessRequestsHandler)
+			employees.POST("/:id/access-requests", auth.RequireEntityAccess("access-levels"), CreateAccessRequestHandler)
+			employees.POST("/:id/portal-link", auth.RequireAdmin(), CreatePortalLinkHandler)
+			employees.DELETE("/:id/portal-link", auth.RequireAdmin(), RevokePortalLinksHandler)
 		}
 
 		users := api.Group("/users")
@@ -60,6 +118,179 @@ func SetupRouter() *gin.Engine {
 			users.GET("", GetUsersHandler)
 		}
 
+		// Export, import and cloning of whole tenants, and their settings.
+		tenant := api.Group("/tenant")
+		tenant.Use(auth.RequireAdmin())
+		{
+			tenant.GET("/export", ExportTenantHandler)
+			tenant.POST("/import", ImportTenantHandler)
+			tenant.POST("/clone", CloneTenantHandler)
+			tenant.GET("/settings", GetTenantSettingsHandler)
+			tenant.PUT("/settings", UpdateTenantSettingsHandler)
+		}
+
+		// Server-Sent Events with the tenant's employee and entity changes.
+		api.GET("/changes", StreamChangesHandler)
+
+		// Outbound webhooks, delivered by the dispatcher started in main.
+		webhooks := api.Group("/webhooks")
+		webhooks.Use(auth.RequireAdmin())
+		{
+			webhooks.POST("", CreateWebhookSubscriptionHandler)
+			webhooks.GET("", GetWebhookSubscriptionsHandler)
+			webhooks.GET("/deliveries", GetWebhookDeliveriesHandler)
+			webhooks.POST("/deliveries/:id/redeliver", RedeliverWebhookHandler)
+			webhooks.GET("/:id", GetWebhookSubscriptionByIDHandler)
+			webhooks.PUT("/:id", UpdateWebhookSubscriptionHandler)
+			webhooks.DELETE("/:id", DeleteWebhookSubscriptionHandler)
+			webhooks.POST("/:id/ping", PingWebhookSubscriptionHandler)
+		}
+
+		// Reporting lines between employees and the department hierarchy.
+		org := api.Group("/org")
+		{
+			orgEmployees := org.Group("")
+			orgEmployees.Use(auth.RequireEntityAccess("employees"))
+			{
+				orgEmployees.GET("/chart", GetOrgChartHandler)
+				orgEmployees.GET("/chart/export", ExportOrgChartHandler)
+				orgEmployees.GET("/employees/:id/subtree", GetReportingSubtreeHandler)
+				orgEmployees.GET("/employees/:id/chain", GetChainOfCommandHandler)
+			}
+			orgDepartments := org.Group("/departments")
+			orgDepartments.Use(auth.RequireEntityAccess("departments"))
+			{
+				orgDepartments.GET("", GetDepartmentTreeHandler)
+				orgDepartments.GET("/:id/subtree", GetDepartmentSubtreeHandler)
+				orgDepartments.GET("/:id/chain", GetDepartmentChainHandler)
+			}
+		}
+
+		// Search across employees and every entity type the tenant has enabled.
+		api.GET("/search", SearchHandler)
+
+		// Saved filters for the list endpoints, private to their owner or shared with the tenant.
+		views := api.Group("/views")
+		{
+			views.GET("", GetSavedViewsHandler)
+			views.POST("", CreateSavedViewHandler)
+			views.GET("/:id", GetSavedViewByIDHandler)
+			views.PUT("/:id", UpdateSavedViewHandler)
+			views.DELETE("/:id", DeleteSavedViewHandler)
+		}
+
+		// Onboarding metrics, as JSON or as CSV and XLSX downloads.
+		analytics := api.Group("/analytics")
+		analytics.Use(auth.RequireEntityAccess("employees"))
+		{
+			analytics.GET("/hires", GetHiresReportHandler)
+			analytics.GET("/upcoming", GetUpcomingReportHandler)
+			analytics.GET("/time-to-complete", GetCompletionReportHandler)
+			analytics.GET("/span-of-control", GetSpanOfControlReportHandler)
+			analytics.GET("/entity-usage", GetEntityUsageReportHandler)
+		}
+
+		// Individual hardware units, their assignments and the stock report. The models
+		// themselves are the hardware-assets entity.
+		inventory := api.Group("/inventory")
+		inventory.Use(auth.RequireEntityAccess("hardware-assets"))
+		{
+			inventory.GET("/units", GetAssetUnitsHandler)
+			inventory.POST("/units", CreateAssetUnitHandler)
+			inventory.GET("/units/:id", GetAssetUnitByIDHandler)
+			inventory.PUT("/units/:id", UpdateAssetUnitHandler)
+			inventory.DELETE("/units/:id", DeleteAssetUnitHandler)
+			inventory.POST("/units/:id/status", SetAssetUnitStatusHandler)
+			inventory.POST("/units/:id/assign", AssignAssetUnitHandler)
+			inventory.POST("/units/:id/return", ReturnAssetUnitHandler)
+			inventory.GET("/units/:id/assignments", GetAssetUnitAssignmentsHandler)
+			inventory.GET("/low-stock", GetLowStockReportHandler)
+		}
+
+		// Buddy workload, assignment history and feedback. The buddies themselves are the
+		// onboarding-buddy entity.
+		buddies := api.Group("/buddies")
+		buddies.Use(auth.RequireEntityAccess("onboarding-buddy"))
+		{
+			buddies.GET("/load", GetBuddyLoadsHandler)
+			buddies.GET("/assignments", GetBuddyAssignmentsHandler)
+			buddies.POST("/assignments/:id/feedback", AddBuddyFeedbackHandler)
+		}
+
+		// Requests to grant and revoke access levels, and their approvals. The levels
+		// themselves are the access-levels entity.
+		accessRequests := api.Group("/access-requests")
+		accessRequests.Use(auth.RequireEntityAccess("access-levels"))
+		{
+			accessRequests.GET("", GetAccessRequestsHandler)
+			accessRequests.GET("/:id", GetAccessRequestByIDHandler)
+			accessRequests.POST("/:id/approve", ApproveAccessRequestHandler)
+			accessRequests.POST("/:id/reject", RejectAccessRequestHandler)
+			accessRequests.POST("/:id/cancel", CancelAccessRequestHandler)
+			accessRequests.POST("/:id/complete", CompleteAccessRequestHandler)
+			accessRequests.POST("/:id/revoke", RequestAccessRevocationHandler)
+		}
+
+		// Approval policies, and the changes they hold back until they are signed off.
+		approvalPolicies := api.Group("/approval-policies")
+		approvalPolicies.Use(auth.RequireAdmin())
+		{
+			approvalPolicies.GET("", GetApprovalPoliciesHandler)
+			approvalPolicies.POST("", CreateApprovalPolicyHandler)
+			approvalPolicies.PUT("/:id", UpdateApprovalPolicyHandler)
+			approvalPolicies.DELETE("/:id", DeleteApprovalPolicyHandler)
+		}
+		changeRequests := api.Group("/change-requests")
+		{
+			changeRequests.GET("", GetChangeRequestsHandler)
+			changeRequests.GET("/:id", GetChangeRequestByIDHandler)
+			changeRequests.POST("/:id/approve", ApproveChangeRequestHandler)
+			changeRequests.POST("/:id/reject", RejectChangeRequestHandler)
+			changeRequests.POST("/:id/cancel", CancelChangeRequestHandler)
+		}
+
+		// Documents every new hire is asked to provide.
+		documentTemplates := api.Group("/document-templates")
+		documentTemplates.Use(auth.RequireAdmin())
+		{
+			documentTemplates.GET("", GetDocumentTemplatesHandler)
+			documentTemplates.POST("", CreateDocumentTemplateHandler)
+			documentTemplates.PUT("/:id", UpdateDocumentTemplateHandler)
+			documentTemplates.DELETE("/:id", DeleteDocumentTemplateHandler)
+		}
+
+		// Email notification templates and the outgoing email queue.
+		notificationRoutes := api.Group("/notifications")
+		notificationRoutes.Use(auth.RequireAdmin())
+		{
+			notificationRoutes.GET("/templates", GetNotificationTemplatesHandler)
+			notificationRoutes.POST("/templates", CreateNotificationTemplateHandler)
+			notificationRoutes.GET("/templates/defaults", GetDefaultNotificationTemplatesHandler)
+			notificationRoutes.POST("/templates/preview", PreviewNotificationTemplateHandler)
+			notificationRoutes.PUT("/templates/:id", UpdateNotificationTemplateHandler)
+			notificationRoutes.DELETE("/templates/:id", DeleteNotificationTemplateHandler)
+			notificationRoutes.GET("/messages", GetNotificationMessagesHandler)
+			notificationRoutes.POST("/messages/:id/retry", RetryNotificationMessageHandler)
+		}
+
+		// Background jobs of the whole platform, run by the scheduler started in main.
+		jobs := api.Group("/system/jobs")
+		jobs.Use(auth.RequirePlatformAdmin())
+		{
+			jobs.GET("", GetScheduledJobsHandler)
+			jobs.GET("/:id/runs", GetJobRunsHandler)
+			jobs.POST("/:id/run", TriggerJobHandler)
+			jobs.POST("/:id/pause", PauseJobHandler)
+			jobs.POST("/:id/resume", ResumeJobHandler)
+		}
+
+		// Status of bulk imports started via POST /<resource>/import.
+		imports := api.Group("/imports")
+		{
+			imports.GET("", GetImportsHandler)
+			imports.GET("/:id", GetImportByIDHandler)
+		}
+
 		// --- CRUD routes for all dynamic entities ---
 		// Using a helper function to keep this section clean.
 		createEntityRoutes(api, "locations", CreateLocationHandler, GetLocationsHandler, GetLocationByIDHandler, UpdateLocationHandler, DeleteLocationHandler)
@@ -69,6 +300,7 @@ func SetupRouter() *gin.Engine {
 		createEntityRoutes(api, "employement-types", CreateEmploymentTypeHandler, GetEmploymentTypesHandler, GetEmploymentTypeByIDHandler, UpdateEmploymentTypeHandler, DeleteEmploymentTypeHandler)
 		createEntityRoutes(api, "teams", CreateTeamHandler, GetTeamsHandler, GetTeamByIDHandler, UpdateTeamHandler, DeleteTeamHandler)
 		createEntityRoutes(api, "costs", CreateCostCenterHandler, GetCostCentersHandler, GetCostCenterByIDHandler, UpdateCostCenterHandler, DeleteCostCenterHandler)
+		api.GET("/costs/report", auth.RequireEntityAccess("costs"), GetCostReportHandler)
 		createEntityRoutes(api, "hardware-assets", CreateHardwareAssetHandler, GetHardwareAssetsHandler, GetHardwareAssetByIDHandler, UpdateHardwareAssetHandler, DeleteHardwareAssetHandler)
 		createEntityRoutes(api, "onboarding-buddy", CreateOnboardingBuddyHandler, GetOnboardingBuddiesHandler, GetOnboardingBuddyByIDHandler, UpdateOnboardingBuddyHandler, DeleteOnboardingBuddyHandler)
 		createEntityRoutes(api, "access-levels", CreateAccessLevelHandler, GetAccessLevelsHandler, GetAccessLevelByIDHandler, UpdateAccessLevelHandler, DeleteAccessLevelHandler)
@@ -86,5 +318,13 @@ func createEntityRoutes(group *gin.RouterGroup, resource string, create, getAll,
 		entityGroup.GET("/:id", getByID)
 		entityGroup.PUT("/:id", update)
 		entityGroup.DELETE("/:id", del)
+		entityGroup.POST("/import", ImportHandler(resource))
+		entityGroup.GET("/export", ExportHandler(resource))
+		entityGroup.GET("/trash", GetTrashHandler(resource))
+		entityGroup.POST("/:id/restore", RestoreHandler(resource))
+		entityGroup.GET("/:id/versions", GetVersionsHandler(resource))
+		entityGroup.GET("/:id/versions/:version", GetVersionHandler(resource))
+		entityGroup.GET("/:id/as-of", GetRecordAsOfHandler(resource))
+		entityGroup.GET("/:id/diff", DiffVersionsHandler(resource))
 	}
 }
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// scheduledChangeError responds with the status that matches a scheduled change error.
+func scheduledChangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrEmployeeNotFound), errors.Is(err, services.ErrScheduledChangeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// --- Scheduled Change Handlers ---
+
+// ScheduleEmployeeChangeHandler schedules an update of an employee for a later day.
+func ScheduleEmployeeChangeHandler(c *gin.Context) {
+	var input services.ScheduledChangeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	change, err := services.ScheduleEmployeeChange(c.Request.Context(), c.GetString("tenantId"), c.Param("id"), c.GetString("userId"), &input)
+	if err != nil {
+		scheduledChangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, change)
+}
+
+// GetScheduledChangesHandler lists the scheduled changes of an employee, optionally
+// filtered by status.
+func GetScheduledChangesHandler(c *gin.Context) {
+	changes, err := services.GetScheduledChanges(c.Request.Context(), c.GetString("tenantId"), c.Param("id"), c.Query("status"))
+	if err != nil {
+		if errors.Is(err, services.ErrEmployeeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled changes"})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// CancelScheduledChangeHandler withdraws a scheduled change before it takes effect.
+func CancelScheduledChangeHandler(c *gin.Context) {
+	change, err := services.CancelScheduledChange(c.Request.Context(), c.GetString("tenantId"), c.Param("id"), c.Param("changeId"), c.GetString("userId"))
+	if err != nil {
+		scheduledChangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, change)
+}
+
+// GetEmployeeTimelineHandler returns the history of an employee together with the changes
+// scheduled for it, newest first.
+func GetEmployeeTimelineHandler(c *gin.Context) {
+	timeline, err := services.GetEmployeeTimeline(c.Request.Context(), c.GetString("tenantId"), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrEmployeeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch the employee timeline"})
+		return
+	}
+	c.JSON(http.StatusOK, timeline)
+}
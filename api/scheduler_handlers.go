@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/scheduler"
+)
+
+// --- Scheduler Handlers (platform admins only) ---
+
+// GetScheduledJobsHandler lists the recurring and one-off background jobs.
+func GetScheduledJobsHandler(c *gin.Context) {
+	jobs, err := scheduler.GetJobs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetJobRunsHandler lists the run history of a job, newest first.
+func GetJobRunsHandler(c *gin.Context) {
+	page, err := paginationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	runs, err := scheduler.GetJobRuns(c.Request.Context(), c.Param("id"), page.Limit, page.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job runs"})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// TriggerJobHandler runs a job as soon as a replica picks it up, regardless of its schedule.
+func TriggerJobHandler(c *gin.Context) {
+	if err := scheduler.TriggerJob(c.Request.Context(), c.Param("id"), c.GetString("userId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Job triggered"})
+}
+
+// PauseJobHandler stops a job from running on its schedule.
+func PauseJobHandler(c *gin.Context) {
+	if err := scheduler.SetJobPaused(c.Request.Context(), c.Param("id"), true); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Job paused"})
+}
+
+// ResumeJobHandler lets a paused job run on its schedule again.
+func ResumeJobHandler(c *gin.Context) {
+	if err := scheduler.SetJobPaused(c.Request.Context(), c.Param("id"), false); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Job resumed"})
+}
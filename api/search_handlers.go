@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Search Handlers ---
+
+// SearchHandler searches employees and entities by name, email, phone number and the other
+// text fields of each entity type. q is the query, types a comma-separated list of the
+// types to search ("employees" or entity slugs) and limit the number of hits per type.
+func SearchHandler(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	var types []string
+	if raw := c.Query("types"); raw != "" {
+		for _, entityType := range strings.Split(raw, ",") {
+			entityType = strings.TrimSpace(entityType)
+			if _, ok := services.LookupEntity(entityType); !ok && entityType != "employees" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown type " + strconv.Quote(entityType)})
+				return
+			}
+			types = append(types, entityType)
+		}
+	}
+	limit := services.DefaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > services.MaxSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be an integer from 1 to %d", services.MaxSearchLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	result, err := services.Search(c.Request.Context(), c.GetString("tenantId"), query, types, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearch) || errors.Is(err, services.ErrSearchTooLong) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
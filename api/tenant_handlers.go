@@ -0,0 +1,107 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// maxTenantArchiveSize caps the size of uploaded tenant archives (200 MB).
+const maxTenantArchiveSize = 200 << 20
+
+// --- Tenant Portability Handlers (admin only) ---
+
+// ExportTenantHandler streams a zip archive with all data of the current tenant.
+func ExportTenantHandler(c *gin.Context) {
+	tenantID := c.GetString("tenantId")
+
+	download := newDownload(c, "application/zip", fmt.Sprintf("tenant-%s.zip", tenantID))
+	if err := services.ExportTenant(c.Request.Context(), download, tenantID); err != nil {
+		if download.started {
+			log.Printf("Tenant export for %s failed: %v", tenantID, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export tenant"})
+		return
+	}
+	download.start()
+}
+
+// ImportTenantHandler creates a new tenant from an uploaded tenant archive.
+// The caller's own tenant is not modified.
+func ImportTenantHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTenantArchiveSize)
+
+	var opts services.TenantImportOptions
+	if err := c.ShouldBind(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A tenant archive is required in the 'file' form field"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read the uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	result, err := services.ImportTenant(c.Request.Context(), file, fileHeader.Size, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// CloneTenantHandler copies the current tenant into a new one, e.g. a sandbox.
+// Clones are created with the "trial" status unless another status is given.
+func CloneTenantHandler(c *gin.Context) {
+	var opts services.TenantImportOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if opts.Status == "" {
+		opts.Status = "trial"
+	}
+
+	result, err := services.CloneTenant(c.Request.Context(), c.GetString("tenantId"), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetTenantSettingsHandler returns the settings of the current tenant.
+func GetTenantSettingsHandler(c *gin.Context) {
+	settings, err := services.GetTenantSettings(c.Request.Context(), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tenant settings"})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateTenantSettingsHandler replaces the settings of the current tenant, such as how long
+// deleted records stay in the trash.
+func UpdateTenantSettingsHandler(c *gin.Context) {
+	var settings services.TenantSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated, err := services.UpdateTenantSettings(c.Request.Context(), c.GetString("tenantId"), &settings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
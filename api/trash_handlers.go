@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Trash Handlers ---
+
+// GetTrashHandler returns the handler that lists the deleted employees or entities of one
+// type, most recently deleted first. They can be restored until the purge job removes them.
+func GetTrashHandler(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := paginationFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		records, err := services.GetTrash(c.Request.Context(), c.GetString("tenantId"), entityType, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted " + entityType})
+			return
+		}
+		c.JSON(http.StatusOK, records)
+	}
+}
+
+// RestoreHandler returns the handler that takes a deleted employee or entity of one type
+// out of the trash.
+func RestoreHandler(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		record, err := services.RestoreFromTrash(c.Request.Context(), c.GetString("tenantId"), entityType, c.Param("id"))
+		if err != nil {
+			if errors.Is(err, services.ErrNotInTrash) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore the record"})
+			return
+		}
+		c.JSON(http.StatusOK, record)
+	}
+}
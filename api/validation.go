@@ -0,0 +1,185 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	// The spec uses "format: email" for employee and manager addresses.
+	// kin-openapi does not validate it unless we opt in.
+	openapi3.DefineStringFormatValidator("email", openapi3.NewRegexpFormatValidator(openapi3.FormatOfStringForEmail))
+}
+
+// SpecValidator validates incoming requests (and optionally outgoing responses)
+// against the OpenAPI document that ships with the repository.
+type SpecValidator struct {
+	router            routers.Router
+	validateResponses bool
+}
+
+// NewSpecValidator loads and validates the OpenAPI document at specPath.
+// When validateResponses is true, every response is also checked against the spec,
+// which is intended for test environments to catch drift between handlers and openapi.yaml.
+func NewSpecValidator(specPath string, validateResponses bool) (*SpecValidator, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromFile(specPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, err
+	}
+
+	// The spec lists "http://localhost:8080" as its server. Clearing the servers makes
+	// the router match on path alone, so validation works behind any host or proxy.
+	doc.Servers = nil
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpecValidator{router: router, validateResponses: validateResponses}, nil
+}
+
+// Middleware returns the Gin handler that performs the validation.
+// Routes that are not described in the spec are passed through untouched.
+func (v *SpecValidator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := v.router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		// 1. Validate the request. Authentication is handled by auth.AuthMiddleware,
+		// so the security requirements in the spec are skipped here.
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), requestInput); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Request does not match the API specification",
+				"details": validationDetails(err, "", ""),
+			})
+			return
+		}
+
+		if !v.validateResponses || isEventStream(route) {
+			c.Next()
+			return
+		}
+
+		// 2. Buffer the response so it can be checked before it reaches the client.
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		// Only JSON bodies are checked against schemas; file downloads are checked by status and content type.
+		mediaType, _, _ := mime.ParseMediaType(writer.Header().Get("Content-Type"))
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 writer.Status(),
+			Header:                 writer.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(writer.body.Bytes())),
+			Options: &openapi3filter.Options{
+				MultiError:          true,
+				ExcludeResponseBody: mediaType != "application/json",
+			},
+		}
+		if err := openapi3filter.ValidateResponse(context.Background(), responseInput); err != nil {
+			log.Printf("Response for %s %s does not match the API specification: %v", c.Request.Method, route.Path, err)
+			c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+			c.Writer.WriteHeader(http.StatusInternalServerError)
+			body, _ := json.Marshal(gin.H{
+				"error":   "Response does not match the API specification",
+				"details": validationDetails(err, "response", ""),
+			})
+			c.Writer.Write(body)
+			return
+		}
+		c.Writer.Write(writer.body.Bytes())
+	}
+}
+
+// isEventStream reports whether the route responds with Server-Sent Events. Such responses
+// never end, so they cannot be buffered for validation.
+func isEventStream(route *routers.Route) bool {
+	response := route.Operation.Responses.Status(http.StatusOK)
+	return response != nil && response.Value != nil && response.Value.Content.Get("text/event-stream") != nil
+}
+
+// bufferedResponseWriter captures the response body instead of sending it,
+// so the response validator can inspect it first.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// validationDetails flattens kin-openapi errors into a list of
+// {"in", "field", "message"} objects suitable for a 400 response body.
+func validationDetails(err error, in, field string) []gin.H {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var details []gin.H
+		for _, inner := range e {
+			details = append(details, validationDetails(inner, in, field)...)
+		}
+		return details
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			in, field = e.Parameter.In, e.Parameter.Name
+		} else if e.RequestBody != nil {
+			in = "body"
+		}
+		if e.Err != nil {
+			return validationDetails(e.Err, in, field)
+		}
+		return []gin.H{{"in": in, "field": field, "message": e.Reason}}
+	case *openapi3filter.ResponseError:
+		if e.Err != nil {
+			return validationDetails(e.Err, in, field)
+		}
+		return []gin.H{{"in": in, "field": field, "message": e.Reason}}
+	case *openapi3.SchemaError:
+		if pointer := e.JSONPointer(); len(pointer) > 0 {
+			field = strings.Join(pointer, ".")
+		}
+		return []gin.H{{"in": in, "field": field, "message": e.Reason}}
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return validationDetails(schemaErr, in, field)
+	}
+	return []gin.H{{"in": in, "field": field, "message": err.Error()}}
+}
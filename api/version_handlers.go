@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// --- Version Handlers ---
+
+// versionFromParam reads a version number from the path or query.
+func versionFromParam(c *gin.Context, name, raw string) (int, bool) {
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a positive version number"})
+		return 0, false
+	}
+	return version, true
+}
+
+// respondVersionError answers with 404 for records or versions that do not exist.
+func respondVersionError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrVersionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch the record history"})
+}
+
+// GetVersionsHandler returns the handler that lists the versions of an employee or entity
+// of one type, newest first.
+func GetVersionsHandler(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := paginationFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		versions, err := services.GetVersions(c.Request.Context(), c.GetString("tenantId"), entityType, c.Param("id"), page)
+		if err != nil {
+			respondVersionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, versions)
+	}
+}
+
+// GetVersionHandler returns the handler that fetches one version of an employee or entity.
+func GetVersionHandler(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		number, ok := versionFromParam(c, "version", c.Param("version"))
+		if !ok {
+			return
+		}
+		version, err := services.GetVersion(c.Request.Context(), c.GetString("tenantId"), entityType, c.Param("id"), number)
+		if err != nil {
+			respondVersionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, version)
+	}
+}
+
+// GetRecordAsOfHandler returns the handler that fetches the version of an employee or entity
+// that was current at the "date" query parameter: a day (YYYY-MM-DD), meaning its end in
+// UTC, or an RFC3339 time.
+func GetRecordAsOfHandler(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.Query("date")
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			day, dayErr := time.Parse("2006-01-02", raw)
+			if dayErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "date must be a date such as 2026-03-01 or an RFC3339 time"})
+				return
+			}
+			at = day.AddDate(0, 0, 1).Add(-time.Millisecond)
+		}
+		version, err := services.GetVersionAsOf(c.Request.Context(), c.GetString("tenantId"), entityType, c.Param("id"), at)
+		if err != nil {
+			respondVersionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, version)
+	}
+}
+
+// DiffVersionsHandler returns the handler that compares the "from" and "to" versions of an
+// employee or entity.
+func DiffVersionsHandler(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, ok := versionFromParam(c, "from", c.Query("from"))
+		if !ok {
+			return
+		}
+		to, ok := versionFromParam(c, "to", c.Query("to"))
+		if !ok {
+			return
+		}
+		diff, err := services.DiffVersions(c.Request.Context(), c.GetString("tenantId"), entityType, c.Param("id"), from, to)
+		if err != nil {
+			respondVersionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, diff)
+	}
+}
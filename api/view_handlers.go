@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/services"
+)
+
+// viewError answers with the status that matches an error of a saved view.
+func viewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSavedViewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// --- Saved View Handlers ---
+
+// GetSavedViewsHandler lists the user's own views and the tenant's shared views,
+// optionally for one entity type.
+func GetSavedViewsHandler(c *gin.Context) {
+	views, err := services.GetSavedViews(c.Request.Context(), c.GetString("tenantId"), c.GetString("userId"), c.Query("entityType"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved views"})
+		return
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+// CreateSavedViewHandler saves a filter as a view of the user, shared with the tenant if
+// requested.
+func CreateSavedViewHandler(c *gin.Context) {
+	var input services.SavedViewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := services.CreateSavedView(c.Request.Context(), c.GetString("tenantId"), c.GetString("userId"), &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, view)
+}
+
+// GetSavedViewByIDHandler returns one of the user's own or shared views.
+func GetSavedViewByIDHandler(c *gin.Context) {
+	view, err := services.GetSavedView(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// UpdateSavedViewHandler replaces a view. Shared views of other users may only be changed
+// by admins.
+func UpdateSavedViewHandler(c *gin.Context) {
+	var input services.SavedViewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := services.UpdateSavedView(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId"), &input)
+	if err != nil {
+		viewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// DeleteSavedViewHandler removes a view. Shared views of other users may only be removed
+// by admins.
+func DeleteSavedViewHandler(c *gin.Context) {
+	if err := services.DeleteSavedView(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), c.GetString("userId")); err != nil {
+		viewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Saved view deleted successfully"})
+}
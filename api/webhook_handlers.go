@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/onboarding/models"
+	"github.com/your-username/onboarding/services"
+)
+
+// webhookSubscriptionWithSecret is returned once, when a subscription is created.
+// Afterwards the secret is never exposed again.
+type webhookSubscriptionWithSecret struct {
+	*models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// --- Webhook Handlers (admin only) ---
+
+// CreateWebhookSubscriptionHandler registers a URL that receives the selected events.
+func CreateWebhookSubscriptionHandler(c *gin.Context) {
+	var data services.WebhookSubscriptionData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := services.CreateWebhookSubscription(c.Request.Context(), c.GetString("tenantId"), &data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, webhookSubscriptionWithSecret{subscription, subscription.Secret})
+}
+
+// GetWebhookSubscriptionsHandler lists the webhook subscriptions of the current tenant.
+func GetWebhookSubscriptionsHandler(c *gin.Context) {
+	subscriptions, err := services.GetWebhookSubscriptions(c.Request.Context(), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook subscriptions"})
+		return
+	}
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetWebhookSubscriptionByIDHandler returns one webhook subscription.
+func GetWebhookSubscriptionByIDHandler(c *gin.Context) {
+	subscription, err := services.GetWebhookSubscription(c.Request.Context(), c.Param("id"), c.GetString("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateWebhookSubscriptionHandler changes the URL, events or active flag of a subscription.
+func UpdateWebhookSubscriptionHandler(c *gin.Context) {
+	var data services.WebhookSubscriptionData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.UpdateWebhookSubscription(c.Request.Context(), c.Param("id"), c.GetString("tenantId"), &data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook subscription updated successfully"})
+}
+
+// DeleteWebhookSubscriptionHandler removes a webhook subscription.
+func DeleteWebhookSubscriptionHandler(c *gin.Context) {
+	if err := services.DeleteWebhookSubscription(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook subscription deleted successfully"})
+}
+
+// PingWebhookSubscriptionHandler queues a test event for one subscription.
+func PingWebhookSubscriptionHandler(c *gin.Context) {
+	if err := services.PingWebhookSubscription(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Ping queued for delivery"})
+}
+
+// GetWebhookDeliveriesHandler lists webhook deliveries, newest first.
+// Use ?status=failed to list the dead letters.
+func GetWebhookDeliveriesHandler(c *gin.Context) {
+	page, err := paginationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, err := services.GetWebhookDeliveries(c.Request.Context(), c.GetString("tenantId"), c.Query("status"), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook deliveries"})
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhookHandler queues a delivery again, typically one from the dead letters.
+func RedeliverWebhookHandler(c *gin.Context) {
+	if err := services.RedeliverWebhook(c.Request.Context(), c.Param("id"), c.GetString("tenantId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Delivery queued again"})
+}
@@ -18,17 +18,40 @@ import (
 type Claims struct {
 	UserID   string `json:"userId"`
 	TenantID string `json:"tenantId"`
+	// Scope limits what a token may be used for. User tokens have no scope;
+	// pre-boarding portal tokens have ScopePortal and carry an EmployeeID instead of a UserID.
+	Scope      string `json:"scope,omitempty"`
+	EmployeeID string `json:"employeeId,omitempty"`
+	// OrigIat is when the user logged in, as a Unix time. Refreshed tokens keep it, so a
+	// session cannot be extended past MaxSessionDuration.
+	OrigIat int64 `json:"orig_iat,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a new JWT for a given user and tenant.
-func GenerateToken(userID, tenantID string) (string, error) {
-	// Set the token's expiration time. Here, we'll use 24 hours.
+// ScopePortal marks tokens issued to new hires for the pre-boarding portal.
+const ScopePortal = "portal"
+
+// portalTokenDuration is how long a portal session lasts. New hires get a new one
+// by opening their magic link again.
+const portalTokenDuration = 2 * time.Hour
+
+// MaxSessionDuration is how long a session lasts from the login at most, however often
+// its token is refreshed.
+const MaxSessionDuration = 7 * 24 * time.Hour
+
+// GenerateToken generates a new JWT for a given user and tenant, for a session that
+// started with a login at loginAt.
+func GenerateToken(userID, tenantID string, loginAt time.Time) (string, error) {
+	// Set the token's expiration time. Here, we'll use 24 hours, but never past the end of the session.
 	expirationTime := time.Now().Add(24 * time.Hour)
+	if end := loginAt.Add(MaxSessionDuration); end.Before(expirationTime) {
+		expirationTime = end
+	}
 
 	claims := &Claims{
 		UserID:   userID,
 		TenantID: tenantID,
+		OrigIat:  loginAt.Unix(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			// ExpiresAt is a NumericDate type, so we need to convert the time.
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
@@ -42,32 +65,64 @@ func GenerateToken(userID, tenantID string) (string, error) {
 	return token.SignedString([]byte(config.AppConfig.JwtSecretKey))
 }
 
+// GeneratePortalToken generates a pre-boarding portal token for an employee. It is only
+// accepted by PortalMiddleware, never by AuthMiddleware.
+func GeneratePortalToken(employeeID, tenantID string) (string, time.Time, error) {
+	expirationTime := time.Now().Add(portalTokenDuration)
+	claims := &Claims{
+		TenantID:   tenantID,
+		Scope:      ScopePortal,
+		EmployeeID: employeeID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(config.AppConfig.JwtSecretKey))
+	return signed, expirationTime, err
+}
+
+// parseBearerToken reads and validates the JWT in the Authorization header.
+// On failure it aborts the request and returns nil.
+func parseBearerToken(c *gin.Context) *Claims {
+	// 1. Get the token from the Authorization header.
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return nil
+	}
+
+	// The header should be in the format "Bearer <token>".
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format, must be 'Bearer <token>'"})
+		return nil
+	}
+
+	// 2. Parse and validate the token.
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// This function provides the key for validation.
+		return []byte(config.AppConfig.JwtSecretKey), nil
+	})
+
+	if err != nil || !token.Valid {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return nil
+	}
+	return claims
+}
+
 // AuthMiddleware is the Gin middleware for authenticating requests.
 // It will be applied to all routes that require a user to be logged in.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 1. Get the token from the Authorization header.
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			return
-		}
-
-		// The header should be in the format "Bearer <token>".
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format, must be 'Bearer <token>'"})
+		// 1-2. Read and validate the token. Scoped tokens (e.g. portal tokens) are not user sessions.
+		claims := parseBearerToken(c)
+		if claims == nil {
 			return
 		}
-
-		// 2. Parse and validate the token.
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// This function provides the key for validation.
-			return []byte(config.AppConfig.JwtSecretKey), nil
-		})
-
-		if err != nil || !token.Valid {
+		if claims.Scope != "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			return
 		}
@@ -76,6 +131,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// This makes the tenantId and userId available to the actual handlers.
 		c.Set("tenantId", claims.TenantID)
 		c.Set("userId", claims.UserID)
+		c.Set("loginAt", claims.OrigIat)
 
 		// 4. Call the next handler in the chain.
 		c.Next()
@@ -123,3 +179,62 @@ func RequireEntityAccess(entitySlug string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireAdmin is a middleware that only lets users with the "admin" role through.
+// It must run after AuthMiddleware, which puts the userId into the context.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := primitive.ObjectIDFromHex(c.GetString("userId"))
+
+		var user models.User
+		err := db.GetCollection("users").FindOne(c.Request.Context(), bson.M{"_id": userID}).Decode(&user)
+		if err != nil || user.Role != "admin" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Only admins can perform this action."})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePlatformAdmin only lets admins of the platform operator's own tenant
+// (PLATFORM_TENANT_ID) through. It guards operations that affect every tenant,
+// such as the background job scheduler. It must run after AuthMiddleware.
+func RequirePlatformAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		platformTenantID := config.AppConfig.PlatformTenantID
+		if platformTenantID == "" || c.GetString("tenantId") != platformTenantID {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Only platform admins can perform this action."})
+			return
+		}
+
+		userID, _ := primitive.ObjectIDFromHex(c.GetString("userId"))
+		var user models.User
+		err := db.GetCollection("users").FindOne(c.Request.Context(), bson.M{"_id": userID}).Decode(&user)
+		if err != nil || user.Role != "admin" || user.TenantID != platformTenantID {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Only platform admins can perform this action."})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// PortalMiddleware authenticates new hires on the pre-boarding portal. It only accepts
+// portal tokens and puts the tenantId and employeeId into the context.
+func PortalMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := parseBearerToken(c)
+		if claims == nil {
+			return
+		}
+		if claims.Scope != ScopePortal || claims.EmployeeID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "A portal token is required"})
+			return
+		}
+
+		c.Set("tenantId", claims.TenantID)
+		c.Set("employeeId", claims.EmployeeID)
+		c.Next()
+	}
+}
@@ -0,0 +1,294 @@
+package client_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/your-username/onboarding/api"
+	"github.com/your-username/onboarding/auth"
+	"github.com/your-username/onboarding/client"
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"github.com/your-username/onboarding/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The contract tests drive the real router through the in-process client. With
+// APP_ENV=test every response is validated against openapi.yaml, so a handler that drifts
+// from the spec fails here with a 500. Tests that need data run against MONGO_URI, in a
+// database of their own that is dropped afterwards, and are skipped without MongoDB.
+
+var (
+	router    http.Handler
+	haveMongo bool
+)
+
+// server returns a client that sends its requests straight into the router.
+func server(opts ...client.Option) *client.Client {
+	return client.New("http://onboarding.test", append([]client.Option{client.WithHandler(router)}, opts...)...)
+}
+
+func TestMain(m *testing.M) {
+	os.Setenv("APP_ENV", "test")
+	os.Setenv("OPENAPI_SPEC_PATH", "../openapi.yaml")
+	config.LoadConfig()
+	config.AppConfig.DatabaseName = fmt.Sprintf("onboarding_contract_%d", time.Now().UnixNano())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(config.AppConfig.MongoURI).SetServerSelectionTimeout(2*time.Second))
+	if err == nil {
+		err = mongoClient.Ping(ctx, nil)
+	}
+	cancel()
+	if err == nil {
+		haveMongo = true
+		db.MongoClient = mongoClient
+		services.RegisterEventSubscribers()
+		if err := events.Start(context.Background()); err != nil {
+			log.Fatalf("Could not start the event bus: %v", err)
+		}
+	} else {
+		log.Printf("MongoDB is not available, skipping the contract tests that need data: %v", err)
+	}
+	router = api.SetupRouter()
+
+	code := m.Run()
+	if haveMongo {
+		db.MongoClient.Database(config.AppConfig.DatabaseName).Drop(context.Background())
+	}
+	os.Exit(code)
+}
+
+func requireMongo(t *testing.T) {
+	t.Helper()
+	if !haveMongo {
+		t.Skip("MongoDB is not available")
+	}
+}
+
+// --- Without Data ---
+
+func TestSignupRejectsIncompleteRequest(t *testing.T) {
+	_, err := server().Signup(context.Background(), &client.SignupRequest{CompanyName: "Acme"})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("expected a bad request, got %v", err)
+	}
+}
+
+func TestProtectedRoutesRequireAToken(t *testing.T) {
+	_, err := server().Employees().List(context.Background(), nil)
+	if !client.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestInvalidTokenIsRejected(t *testing.T) {
+	_, err := server(client.WithToken("not-a-jwt")).Locations().Get(context.Background(), "507f1f77bcf86cd799439011")
+	if !client.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestRefreshStopsAtTheEndOfTheSession(t *testing.T) {
+	sign := func(loginAt int64) string {
+		claims := &auth.Claims{
+			UserID:           "507f1f77bcf86cd799439012",
+			TenantID:         "507f1f77bcf86cd799439011",
+			OrigIat:          loginAt,
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.AppConfig.JwtSecretKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		loginAt int64
+	}{
+		{"session over", time.Now().Add(-auth.MaxSessionDuration - time.Minute).Unix()},
+		{"no login time", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server(client.WithToken(sign(tt.loginAt))).RefreshToken(context.Background())
+			var apiErr *client.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != services.ErrSessionExpired.Error() {
+				t.Fatalf("expected the session to have expired, got %v", err)
+			}
+		})
+	}
+}
+
+// --- With Data ---
+
+// signup creates a tenant and returns a client logged in as its admin.
+func signup(t *testing.T) (*client.Client, *client.LoginResponse) {
+	t.Helper()
+	ctx := context.Background()
+	c := server()
+	username := fmt.Sprintf("admin-%d", time.Now().UnixNano())
+	if _, err := c.Signup(ctx, &client.SignupRequest{CompanyName: "Acme", Username: username, Password: "correct horse battery"}); err != nil {
+		t.Fatalf("signup: %v", err)
+	}
+	login, err := c.Login(ctx, username, "correct horse battery")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return c, login
+}
+
+func TestLoginWithWrongPassword(t *testing.T) {
+	requireMongo(t)
+	c, login := signup(t)
+	_, err := server().Login(context.Background(), login.User.Username, "wrong")
+	if !client.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+	if c.Token() == "" {
+		t.Fatal("the admin client lost its token")
+	}
+}
+
+func TestEntityLifecycle(t *testing.T) {
+	requireMongo(t)
+	ctx := context.Background()
+	c, _ := signup(t)
+	locations := c.Locations()
+
+	location := &models.Location{Address: "Main Street 1", PostalCode: "10115"}
+	location.Name = "Berlin"
+	created, err := locations.Create(ctx, location)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := created.ID.Hex()
+
+	if err := locations.Update(ctx, id, map[string]interface{}{"name": "Berlin Mitte"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	fetched, err := locations.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if fetched.Name != "Berlin Mitte" {
+		t.Fatalf("expected the updated name, got %q", fetched.Name)
+	}
+
+	all, err := locations.ListAll(ctx, 1)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("expected one location, got %d", len(all))
+	}
+
+	if err := locations.Delete(ctx, id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := locations.Get(ctx, id); !client.IsNotFound(err) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestTenantsAreIsolated(t *testing.T) {
+	requireMongo(t)
+	ctx := context.Background()
+	first, _ := signup(t)
+	second, _ := signup(t)
+
+	location := &models.Location{}
+	location.Name = "Hamburg"
+	created, err := first.Locations().Create(ctx, location)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := second.Locations().Get(ctx, created.ID.Hex()); !client.IsNotFound(err) {
+		t.Fatalf("expected another tenant's location to be not found, got %v", err)
+	}
+}
+
+func TestTenantImportCannotWriteIntoAnotherTenant(t *testing.T) {
+	requireMongo(t)
+	ctx := context.Background()
+	victim, _ := signup(t)
+	attacker, _ := signup(t)
+
+	location := &models.Location{}
+	location.Name = "Hamburg"
+	victimLocation, err := victim.Locations().Create(ctx, location)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// The archive places a location in the victim's tenant and points a team at the
+	// victim's location.
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	files := map[string]string{
+		"manifest.json":    `{"schemaVersion": 1, "tenantId": "507f1f77bcf86cd799439011"}`,
+		"locations.ndjson": fmt.Sprintf(`{"_id": {"$oid": "507f1f77bcf86cd799439021"}, "tenantId": %q, "name": "Injected"}`, victimLocation.TenantID),
+		"teams.ndjson":     fmt.Sprintf(`{"_id": {"$oid": "507f1f77bcf86cd799439022"}, "tenantId": %q, "name": "Team", "departmentId": {"$oid": %q}}`, victimLocation.TenantID, victimLocation.ID.Hex()),
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("companyName", "Imported")
+	form.WriteField("adminUsername", fmt.Sprintf("imported-%d", time.Now().UnixNano()))
+	form.WriteField("adminPassword", "correct horse battery")
+	part, _ := form.CreateFormFile("file", "archive.zip")
+	part.Write(archive.Bytes())
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tenant/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+attacker.Token())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("import: %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	locations, err := victim.Locations().List(ctx, nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, l := range locations {
+		if l.Name == "Injected" {
+			t.Fatal("the import wrote a location into another tenant")
+		}
+	}
+	var team models.Team
+	err = db.GetCollection("teams").FindOne(ctx, bson.M{"name": "Team", "tenantId": bson.M{"$ne": victimLocation.TenantID}}).Decode(&team)
+	if err != nil {
+		t.Fatalf("the imported team is missing: %v", err)
+	}
+	if !team.DepartmentID.IsZero() {
+		t.Fatalf("the imported team still refers to %s of another tenant", team.DepartmentID.Hex())
+	}
+}
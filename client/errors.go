@@ -0,0 +1,72 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors that an *APIError matches with errors.Is, based on its status code.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrServer       = errors.New("server error")
+)
+
+// ValidationDetail is one entry of the "details" list returned by the
+// OpenAPI validation middleware.
+type ValidationDetail struct {
+	In      string `json:"in"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError is returned for every response with a 4xx or 5xx status code.
+type APIError struct {
+	StatusCode    int                `json:"-"`
+	Message       string             `json:"error"`
+	MissingFields []string           `json:"missing_fields,omitempty"`
+	Details       []ValidationDetail `json:"details,omitempty"`
+}
+
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = http.StatusText(statusCode)
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("onboarding api: %d %s", e.StatusCode, e.Message)
+}
+
+// Is lets callers write errors.Is(err, client.ErrNotFound).
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// IsNotFound reports whether err is a 404 from the API.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized reports whether err is a 401 from the API.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// WithHandler routes every request straight into an http.Handler instead of the network.
+// Passing api.SetupRouter() with APP_ENV=test runs the real handlers behind the
+// OpenAPI response validator, so any drift between a handler and openapi.yaml
+// surfaces as a 500 *APIError whose Details name the offending fields.
+func WithHandler(handler http.Handler) Option {
+	return func(c *Client) {
+		c.httpClient = &http.Client{Transport: handlerTransport{handler: handler}}
+	}
+}
+
+// handlerTransport is an http.RoundTripper that serves requests in-process.
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, req)
+	return recorder.Result(), nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestGeneratedClientIsUpToDate fails when openapi.yaml changed without go generate.
+func TestGeneratedClientIsUpToDate(t *testing.T) {
+	want, err := generate("../../../openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../resources_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("client/resources_gen.go is out of date with openapi.yaml, run go generate ./client")
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/your-username/onboarding/models"
+)
+
+// Resource provides CRUD calls for one collection under /api/v1.
+// The same generic type serves employees and every dynamic entity,
+// mirroring the generic services on the server side.
+type Resource[T any] struct {
+	client *Client
+	path   string
+}
+
+// ListOptions selects a page of results. A zero Limit returns every item.
+type ListOptions struct {
+	Limit  int64
+	Offset int64
+}
+
+func (o *ListOptions) query() url.Values {
+	if o == nil {
+		return nil
+	}
+	query := url.Values{}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(o.Limit, 10))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.FormatInt(o.Offset, 10))
+	}
+	return query
+}
+
+// Create posts a new item and returns it as stored by the server, including its ID.
+func (r *Resource[T]) Create(ctx context.Context, item *T) (*T, error) {
+	var created T
+	if err := r.client.do(ctx, http.MethodPost, r.path, nil, item, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// List returns the items of the current tenant, optionally restricted to one page.
+func (r *Resource[T]) List(ctx context.Context, opts *ListOptions) ([]T, error) {
+	var items []T
+	if err := r.client.do(ctx, http.MethodGet, r.path, opts.query(), nil, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListAll walks through every page of size pageSize and returns all items.
+func (r *Resource[T]) ListAll(ctx context.Context, pageSize int64) ([]T, error) {
+	if pageSize <= 0 {
+		return r.List(ctx, nil)
+	}
+
+	var all []T
+	for offset := int64(0); ; offset += pageSize {
+		page, err := r.List(ctx, &ListOptions{Limit: pageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if int64(len(page)) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// Get fetches a single item by ID.
+func (r *Resource[T]) Get(ctx context.Context, id string) (*T, error) {
+	var item T
+	if err := r.client.do(ctx, http.MethodGet, r.path+"/"+url.PathEscape(id), nil, nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Update applies a partial update. Only the given fields are changed.
+func (r *Resource[T]) Update(ctx context.Context, id string, fields map[string]interface{}) error {
+	return r.client.do(ctx, http.MethodPut, r.path+"/"+url.PathEscape(id), nil, fields, nil)
+}
+
+// Delete removes an item by ID.
+func (r *Resource[T]) Delete(ctx context.Context, id string) error {
+	return r.client.do(ctx, http.MethodDelete, r.path+"/"+url.PathEscape(id), nil, nil, nil)
+}
+
+func newResource[T any](c *Client, slug string) *Resource[T] {
+	return &Resource[T]{client: c, path: "/api/v1/" + slug}
+}
+
+// --- Users ---
+
+// CreateUser creates a user in the caller's tenant. Only admins may call it.
+func (c *Client) CreateUser(ctx context.Context, req *CreateUserRequest) (*models.User, error) {
+	var user models.User
+	if err := c.do(ctx, http.MethodPost, "/api/v1/users", nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers returns all users of the caller's tenant.
+func (c *Client) ListUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	if err := c.do(ctx, http.MethodGet, "/api/v1/users", nil, nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// The accessors of the employees and the dynamic entities are generated from openapi.yaml,
+// see resources_gen.go.
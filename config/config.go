@@ -3,15 +3,54 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	MongoURI     string
-	DatabaseName string
-	JwtSecretKey string
+	MongoURI        string
+	DatabaseName    string
+	JwtSecretKey    string
+	AppEnv          string // e.g., "development", "test", "production"
+	OpenAPISpecPath string // Path to the OpenAPI document used for request validation
+	EventStore      string // "memory" or "mongo"; with "mongo", async event subscribers survive restarts
+	// SearchIndex is "mongo" (query the collections) or "memory" (an embedded index kept in
+	// the process, for single-instance deployments).
+	SearchIndex string
+	// PlatformTenantID is the operator's own tenant. Its admins manage platform-wide
+	// settings such as background jobs. Platform endpoints are disabled when empty.
+	PlatformTenantID string
+	TrialPeriodDays  int // Trial tenants expire this many days after signing up
+	BuddyPeriodDays  int // A buddy looks after a new hire for this many days after the onboarding date
+	// PortalURL is the page of the pre-boarding portal; magic links add "?token=..." to it.
+	PortalURL string
+	// WebhookAllowedNetworks lists hosts, IP addresses and CIDR ranges that webhooks may be
+	// sent to although they are not public, e.g. "localhost,127.0.0.0/8" for a local test
+	// receiver. It is empty by default, so webhooks only reach public addresses.
+	WebhookAllowedNetworks []string
+
+	// Uploaded files. BlobStore is "local" (files below BlobDir) or "s3" (any S3-compatible
+	// service; point S3Endpoint at a local MinIO for development).
+	BlobStore   string
+	BlobDir     string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
+
+	// Outgoing email. MailSink is "smtp" or "capture" (keep messages in memory, for testing);
+	// when empty, SMTP is used if SMTPHost is set, and otherwise emails stay queued.
+	MailSink     string
+	MailFrom     string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
 }
 
 // AppConfig is a global variable that holds the loaded configuration.
@@ -26,10 +65,55 @@ func LoadConfig() {
 	}
 
 	AppConfig = Config{
-		MongoURI:     getEnv("MONGO_URI", "mongodb://localhost:27017"),
-		DatabaseName: getEnv("DATABASE_NAME", "onboarding_db"),
-		JwtSecretKey: getEnv("JWT_SECRET_KEY", "default_secret"),
+		MongoURI:               getEnv("MONGO_URI", "mongodb://localhost:27017"),
+		DatabaseName:           getEnv("DATABASE_NAME", "onboarding_db"),
+		JwtSecretKey:           getEnv("JWT_SECRET_KEY", "default_secret"),
+		AppEnv:                 getEnv("APP_ENV", "development"),
+		OpenAPISpecPath:        getEnv("OPENAPI_SPEC_PATH", "openapi.yaml"),
+		EventStore:             getEnv("EVENT_STORE", "memory"),
+		SearchIndex:            getEnv("SEARCH_INDEX", "mongo"),
+		PlatformTenantID:       getEnv("PLATFORM_TENANT_ID", ""),
+		TrialPeriodDays:        getEnvInt("TRIAL_PERIOD_DAYS", 30),
+		BuddyPeriodDays:        getEnvInt("BUDDY_PERIOD_DAYS", 90),
+		PortalURL:              getEnv("PORTAL_URL", "http://localhost:3000/portal"),
+		WebhookAllowedNetworks: getEnvList("WEBHOOK_ALLOWED_NETWORKS"),
+		BlobStore:              getEnv("BLOB_STORE", "local"),
+		BlobDir:                getEnv("BLOB_DIR", "data/blobs"),
+		S3Endpoint:             getEnv("S3_ENDPOINT", ""),
+		S3Region:               getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:               getEnv("S3_BUCKET", ""),
+		S3AccessKey:            getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:            getEnv("S3_SECRET_KEY", ""),
+		S3UseSSL:               getEnv("S3_USE_SSL", "true") == "true",
+		MailSink:               getEnv("MAIL_SINK", ""),
+		MailFrom:               getEnv("MAIL_FROM", "onboarding@localhost"),
+		SMTPHost:               getEnv("SMTP_HOST", ""),
+		SMTPPort:               getEnv("SMTP_PORT", "587"),
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+	}
+}
+
+// getEnvInt reads an integer environment variable, falling back if it is unset or invalid.
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		log.Printf("Invalid value for %s, using %d", key, fallback)
+	}
+	return fallback
+}
+
+// getEnvList reads a comma-separated environment variable. Blank entries are dropped.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
 	}
+	return values
 }
 
 // getEnv is a helper function to read an environment variable or return a fallback value.
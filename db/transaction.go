@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	transactionsOnce      sync.Once
+	transactionsSupported bool
+)
+
+// Transaction runs fn in a transaction if the deployment supports them, which replica sets
+// and sharded clusters do and a standalone server does not; there fn runs on its own.
+// fn must do all its reads and writes with the context it is given, and may be called more
+// than once when the transaction is retried. Inside a session, fn joins it.
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil || !supportsTransactions(ctx) {
+		return fn(ctx)
+	}
+	session, err := MongoClient.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// supportsTransactions asks the server once whether it is part of a replica set or a
+// sharded cluster.
+func supportsTransactions(ctx context.Context) bool {
+	transactionsOnce.Do(func() {
+		var hello struct {
+			SetName string `bson:"setName"`
+			Msg     string `bson:"msg"`
+		}
+		if err := MongoClient.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+			log.Printf("Could not detect transaction support, writing without transactions: %v", err)
+			return
+		}
+		transactionsSupported = hello.SetName != "" || hello.Msg == "isdbgrid"
+		if !transactionsSupported {
+			log.Println("MongoDB is a standalone server, writing without transactions")
+		}
+	})
+	return transactionsSupported
+}
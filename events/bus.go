@@ -0,0 +1,273 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mode controls how a subscriber is called.
+type Mode int
+
+const (
+	// Sync subscribers run inside Publish or Record, before it returns. Use it for side effects that
+	// must not be lost even if the process stops right after the write, such as the webhook outbox.
+	Sync Mode = iota
+	// Async subscribers run in a background worker per subscriber. Without a durable store,
+	// events still queued when the process stops are lost.
+	Async
+)
+
+const (
+	// asyncQueueSize is the number of events buffered per async subscriber without a store.
+	// When the queue is full, the event is dropped for that subscriber and an error is
+	// returned, so a slow subscriber never holds up writes. Use a store to lose no events.
+	asyncQueueSize = 1024
+	// maxHandlerAttempts is how often a durable subscriber retries an event before skipping it.
+	maxHandlerAttempts = 5
+	// durablePollInterval is how often durable subscribers check the store for new events.
+	durablePollInterval = time.Second
+)
+
+// Handler processes one event.
+type Handler func(ctx context.Context, env Envelope) error
+
+type subscription struct {
+	name    string
+	mode    Mode
+	types   map[string]bool // Empty means all types
+	handler Handler
+	queue   chan Envelope // Async subscribers without a store
+	wake    chan struct{} // Async subscribers with a store
+}
+
+func (s *subscription) wants(eventType string) bool {
+	return len(s.types) == 0 || s.types[eventType]
+}
+
+// Bus dispatches published events to subscribers.
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []*subscription
+	store         Store
+	started       bool
+}
+
+// NewBus creates a bus. With a non-nil store every event is persisted before it is dispatched,
+// and async subscribers read from the store, so they resume where they left off after a restart.
+func NewBus(store Store) *Bus {
+	return &Bus{store: store}
+}
+
+// Subscribe registers a handler for the given event types, or for all types if none are given.
+// The name identifies the subscriber's position in a durable store and must stay stable
+// across releases. Subscribers must be registered before Start.
+func (b *Bus) Subscribe(name string, mode Mode, handler Handler, types ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started {
+		panic(fmt.Sprintf("events: subscriber %q registered after the bus was started", name))
+	}
+
+	sub := &subscription{name: name, mode: mode, types: map[string]bool{}, handler: handler}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	if mode == Async {
+		if b.store != nil {
+			sub.wake = make(chan struct{}, 1)
+		} else {
+			sub.queue = make(chan Envelope, asyncQueueSize)
+		}
+	}
+	b.subscriptions = append(b.subscriptions, sub)
+}
+
+// Start runs the async subscribers until ctx is cancelled. Events published before
+// Start are queued (without a store, up to asyncQueueSize) and delivered once it is called.
+func (b *Bus) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.started = true
+
+	for _, sub := range b.subscriptions {
+		if sub.mode != Async {
+			continue
+		}
+		if b.store != nil {
+			if err := b.store.Register(ctx, sub.name); err != nil {
+				return fmt.Errorf("registering subscriber %q: %w", sub.name, err)
+			}
+			go b.runDurable(ctx, sub)
+		} else {
+			go b.runQueue(ctx, sub)
+		}
+	}
+	return nil
+}
+
+// Publish records the event and dispatches it right away. The write the event describes has
+// already happened, so errors are returned for logging only; callers must not undo their
+// write because of them. Writes that must not commit without their events use Record and
+// Dispatch instead.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	env, err := b.Record(ctx, event)
+	return errors.Join(err, b.Dispatch(env))
+}
+
+// Record persists the event (if the bus has a store) and calls the sync subscribers. Both
+// use ctx, so when it carries a MongoDB transaction they commit or roll back together with
+// the write the event describes. The returned envelope is handed to Dispatch after the commit.
+func (b *Bus) Record(ctx context.Context, event Event) (Envelope, error) {
+	env := Envelope{
+		ID:         primitive.NewObjectID(),
+		Type:       event.EventType(),
+		TenantID:   event.EventTenantID(),
+		OccurredAt: time.Now().UTC(),
+		Event:      event,
+	}
+
+	var errs []error
+	if b.store != nil {
+		if err := b.store.Append(ctx, env); err != nil {
+			errs = append(errs, fmt.Errorf("storing %s event: %w", env.Type, err))
+		}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subscriptions {
+		if sub.mode != Sync || !sub.wants(env.Type) {
+			continue
+		}
+		if err := sub.handler(ctx, env); err != nil {
+			errs = append(errs, fmt.Errorf("subscriber %q: %w", sub.name, err))
+		}
+	}
+	return env, errors.Join(errs...)
+}
+
+// Dispatch hands a recorded event to the async subscribers. It never blocks.
+func (b *Bus) Dispatch(env Envelope) error {
+	var errs []error
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subscriptions {
+		if sub.mode != Async || !sub.wants(env.Type) {
+			continue
+		}
+		if sub.queue != nil {
+			select {
+			case sub.queue <- env:
+			default:
+				errs = append(errs, fmt.Errorf("subscriber %q: queue is full, dropped %s %s", sub.name, env.Type, env.ID.Hex()))
+			}
+			continue
+		}
+		// Durable subscribers read the event from the store; just wake them up.
+		select {
+		case sub.wake <- struct{}{}:
+		default:
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runQueue delivers events from an in-memory queue.
+func (b *Bus) runQueue(ctx context.Context, sub *subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case env := <-sub.queue:
+			if err := sub.handler(ctx, env); err != nil {
+				log.Printf("Event subscriber %q failed on %s %s: %v", sub.name, env.Type, env.ID.Hex(), err)
+			}
+		}
+	}
+}
+
+// runDurable delivers events from the store in order, acknowledging each one once it was
+// handled. Delivery is at-least-once: an event may be handled again after a crash.
+func (b *Bus) runDurable(ctx context.Context, sub *subscription) {
+	ticker := time.NewTicker(durablePollInterval)
+	defer ticker.Stop()
+
+	attempts := map[primitive.ObjectID]int{}
+	for {
+		envs, err := b.store.ReadAfter(ctx, sub.name, 100)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Event subscriber %q could not read events: %v", sub.name, err)
+		}
+
+		for _, env := range envs {
+			if sub.wants(env.Type) {
+				if err := sub.handler(ctx, env); err != nil {
+					attempts[env.ID]++
+					if attempts[env.ID] < maxHandlerAttempts {
+						log.Printf("Event subscriber %q failed on %s %s, will retry: %v", sub.name, env.Type, env.ID.Hex(), err)
+						break // Retry this event on the next tick
+					}
+					log.Printf("Event subscriber %q skipped %s %s after %d attempts: %v", sub.name, env.Type, env.ID.Hex(), maxHandlerAttempts, err)
+				}
+				delete(attempts, env.ID)
+			}
+			if err := b.store.Ack(ctx, sub.name, env.ID); err != nil {
+				log.Printf("Event subscriber %q could not acknowledge %s: %v", sub.name, env.ID.Hex(), err)
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// --- Default Bus ---
+
+// bus is the process-wide bus used by the services layer.
+var bus = NewBus(nil)
+
+// UseStore replaces the default bus with one that persists events in store.
+// It must be called before any subscriber is registered.
+func UseStore(store Store) {
+	bus = NewBus(store)
+}
+
+// Subscribe registers a subscriber on the default bus.
+func Subscribe(name string, mode Mode, handler Handler, types ...string) {
+	bus.Subscribe(name, mode, handler, types...)
+}
+
+// Start runs the async subscribers of the default bus.
+func Start(ctx context.Context) error {
+	return bus.Start(ctx)
+}
+
+// Publish publishes an event on the default bus and logs any subscriber errors.
+func Publish(ctx context.Context, event Event) {
+	if err := bus.Publish(ctx, event); err != nil {
+		log.Printf("Publishing %s event: %v", event.EventType(), err)
+	}
+}
+
+// Record records an event on the default bus. See Bus.Record.
+func Record(ctx context.Context, event Event) (Envelope, error) {
+	return bus.Record(ctx, event)
+}
+
+// Dispatch dispatches a recorded event on the default bus and logs any subscriber errors.
+func Dispatch(env Envelope) {
+	if err := bus.Dispatch(env); err != nil {
+		log.Printf("Dispatching %s event: %v", env.Type, err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package events is the in-process domain event bus. The services layer publishes a
+// typed event after every successful write, and side effects such as webhooks subscribe
+// to the events they care about instead of being called from each handler.
+package events
+
+import (
+	"time"
+
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event types. They double as the public webhook event names.
+const (
+	TypeEmployeeCreated           = "employee.created"
+	TypeEmployeeUpdated           = "employee.updated"
+	TypeEmployeeDepartmentChanged = "employee.department_changed"
+	TypeEmployeeDeleted           = "employee.deleted"
+	TypeEmployeeRestored          = "employee.restored"
+	TypeEntityCreated             = "entity.created"
+	TypeEntityUpdated             = "entity.updated"
+	TypeEntityDeleted             = "entity.deleted"
+	TypeEntityRestored            = "entity.restored"
+	TypeUserCreated               = "user.created"
+	TypeTenantSignedUp            = "tenant.signed_up"
+)
+
+// Event is implemented by every domain event.
+type Event interface {
+	// EventType returns one of the Type constants.
+	EventType() string
+	// EventTenantID returns the tenant the event belongs to.
+	EventTenantID() string
+}
+
+// Envelope wraps an event with the metadata assigned when it is published.
+type Envelope struct {
+	ID         primitive.ObjectID
+	Type       string
+	TenantID   string
+	OccurredAt time.Time
+	Event      Event
+}
+
+// EmployeeCreated is published when an employee is created, one by one or by an import.
+type EmployeeCreated struct {
+	Employee models.Employee `bson:"employee"`
+}
+
+// EmployeeUpdated is published when fields of an employee change.
+type EmployeeUpdated struct {
+	TenantID   string `bson:"tenantId" json:"-"`
+	EmployeeID string `bson:"employeeId" json:"id"`
+	Changes    bson.M `bson:"changes" json:"changes"`
+}
+
+// EmployeeDepartmentChanged is published in addition to EmployeeUpdated when an employee moves department.
+type EmployeeDepartmentChanged struct {
+	TenantID             string      `bson:"tenantId" json:"-"`
+	EmployeeID           string      `bson:"employeeId" json:"id"`
+	PreviousDepartmentID string      `bson:"previousDepartmentId" json:"previousDepartmentId"`
+	DepartmentID         interface{} `bson:"departmentId" json:"departmentId"`
+}
+
+// EmployeeDeleted is published when an employee is deleted, i.e. offboarded. The employee
+// stays in the trash until it is restored or purged.
+type EmployeeDeleted struct {
+	Employee models.Employee `bson:"employee"`
+}
+
+// EmployeeRestored is published when a deleted employee is restored from the trash.
+type EmployeeRestored struct {
+	Employee models.Employee `bson:"employee"`
+}
+
+// EntityCreated is published when an entity (location, team, ...) is created.
+type EntityCreated struct {
+	TenantID   string `bson:"tenantId" json:"-"`
+	EntityType string `bson:"entityType" json:"entityType"` // The entity slug, e.g. "job-roles"
+	Entity     bson.M `bson:"entity" json:"entity"`
+}
+
+// EntityUpdated is published when fields of an entity change.
+type EntityUpdated struct {
+	TenantID   string `bson:"tenantId" json:"-"`
+	EntityType string `bson:"entityType" json:"entityType"`
+	EntityID   string `bson:"entityId" json:"id"`
+	Changes    bson.M `bson:"changes" json:"changes"`
+}
+
+// EntityDeleted is published when an entity is deleted, i.e. moved to the trash.
+type EntityDeleted struct {
+	TenantID   string `bson:"tenantId" json:"-"`
+	EntityType string `bson:"entityType" json:"entityType"`
+	EntityID   string `bson:"entityId" json:"id"`
+}
+
+// EntityRestored is published when a deleted entity is restored from the trash.
+type EntityRestored struct {
+	TenantID   string `bson:"tenantId" json:"-"`
+	EntityType string `bson:"entityType" json:"entityType"`
+	Entity     bson.M `bson:"entity" json:"entity"`
+}
+
+// UserCreated is published when a user is added to a tenant. It deliberately
+// carries no password hash, since events may be persisted.
+type UserCreated struct {
+	UserID   string `bson:"userId" json:"id"`
+	TenantID string `bson:"tenantId" json:"tenantId"`
+	Username string `bson:"username" json:"username"`
+	Role     string `bson:"role" json:"role"`
+}
+
+// TenantSignedUp is published when a company signs up through the public endpoint.
+type TenantSignedUp struct {
+	Tenant      models.Tenant `bson:"tenant" json:"tenant"`
+	AdminUserID string        `bson:"adminUserId" json:"adminUserId"`
+}
+
+func (EmployeeCreated) EventType() string           { return TypeEmployeeCreated }
+func (EmployeeUpdated) EventType() string           { return TypeEmployeeUpdated }
+func (EmployeeDepartmentChanged) EventType() string { return TypeEmployeeDepartmentChanged }
+func (EmployeeDeleted) EventType() string           { return TypeEmployeeDeleted }
+func (EmployeeRestored) EventType() string          { return TypeEmployeeRestored }
+func (EntityCreated) EventType() string             { return TypeEntityCreated }
+func (EntityUpdated) EventType() string             { return TypeEntityUpdated }
+func (EntityDeleted) EventType() string             { return TypeEntityDeleted }
+func (EntityRestored) EventType() string            { return TypeEntityRestored }
+func (UserCreated) EventType() string               { return TypeUserCreated }
+func (TenantSignedUp) EventType() string            { return TypeTenantSignedUp }
+
+func (e EmployeeCreated) EventTenantID() string           { return e.Employee.TenantID }
+func (e EmployeeUpdated) EventTenantID() string           { return e.TenantID }
+func (e EmployeeDepartmentChanged) EventTenantID() string { return e.TenantID }
+func (e EmployeeDeleted) EventTenantID() string           { return e.Employee.TenantID }
+func (e EmployeeRestored) EventTenantID() string          { return e.Employee.TenantID }
+func (e EntityCreated) EventTenantID() string             { return e.TenantID }
+func (e EntityUpdated) EventTenantID() string             { return e.TenantID }
+func (e EntityDeleted) EventTenantID() string             { return e.TenantID }
+func (e EntityRestored) EventTenantID() string            { return e.TenantID }
+func (e UserCreated) EventTenantID() string               { return e.TenantID }
+func (e TenantSignedUp) EventTenantID() string            { return e.Tenant.ID.Hex() }
+
+// newEvent returns an empty event of the given type, so that stored events can be decoded
+// back into their Go type. Every event type must be listed here.
+func newEvent(eventType string) (Event, bool) {
+	switch eventType {
+	case TypeEmployeeCreated:
+		return &EmployeeCreated{}, true
+	case TypeEmployeeUpdated:
+		return &EmployeeUpdated{}, true
+	case TypeEmployeeDepartmentChanged:
+		return &EmployeeDepartmentChanged{}, true
+	case TypeEmployeeDeleted:
+		return &EmployeeDeleted{}, true
+	case TypeEmployeeRestored:
+		return &EmployeeRestored{}, true
+	case TypeEntityCreated:
+		return &EntityCreated{}, true
+	case TypeEntityUpdated:
+		return &EntityUpdated{}, true
+	case TypeEntityDeleted:
+		return &EntityDeleted{}, true
+	case TypeEntityRestored:
+		return &EntityRestored{}, true
+	case TypeUserCreated:
+		return &UserCreated{}, true
+	case TypeTenantSignedUp:
+		return &TenantSignedUp{}, true
+	}
+	return nil, false
+}
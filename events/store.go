@@ -0,0 +1,169 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Store persists events so that async subscribers survive restarts.
+type Store interface {
+	// Append persists a published event.
+	Append(ctx context.Context, env Envelope) error
+	// Register creates the subscriber's position at the end of the log if it has none yet,
+	// so that a new subscriber does not replay the whole history.
+	Register(ctx context.Context, subscriber string) error
+	// ReadAfter returns up to limit events after the subscriber's position, oldest first.
+	ReadAfter(ctx context.Context, subscriber string, limit int64) ([]Envelope, error)
+	// Ack moves the subscriber's position to the given event.
+	Ack(ctx context.Context, subscriber string, eventID primitive.ObjectID) error
+}
+
+const (
+	// EventRetention is how long the MongoDB store keeps events.
+	EventRetention = 7 * 24 * time.Hour
+	// settleDelay hides events this recent from readers. Event IDs from different server
+	// replicas are only ordered to the second, so a reader could otherwise move past an
+	// event that another replica is still inserting.
+	settleDelay = 2 * time.Second
+)
+
+// storedEvent is the document layout of the MongoDB store.
+type storedEvent struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Type       string             `bson:"type"`
+	TenantID   string             `bson:"tenantId"`
+	OccurredAt time.Time          `bson:"occurredAt"`
+	Data       bson.Raw           `bson:"data"`
+}
+
+// MongoStore keeps events in the "domain_events" collection and subscriber positions
+// in "event_subscribers". Events expire after a week.
+type MongoStore struct {
+	events      *mongo.Collection
+	subscribers *mongo.Collection
+}
+
+// NewMongoStore creates the store and its expiry index.
+func NewMongoStore(ctx context.Context) (*MongoStore, error) {
+	store := &MongoStore{
+		events:      db.GetCollection("domain_events"),
+		subscribers: db.GetCollection("event_subscribers"),
+	}
+	_, err := store.events.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "occurredAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(EventRetention.Seconds())),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *MongoStore) Append(ctx context.Context, env Envelope) error {
+	data, err := bson.Marshal(env.Event)
+	if err != nil {
+		return err
+	}
+	_, err = s.events.InsertOne(ctx, storedEvent{
+		ID:         env.ID,
+		Type:       env.Type,
+		TenantID:   env.TenantID,
+		OccurredAt: env.OccurredAt,
+		Data:       data,
+	})
+	return err
+}
+
+func (s *MongoStore) Register(ctx context.Context, subscriber string) error {
+	// Start after everything that is already in the log.
+	position := primitive.NewObjectIDFromTimestamp(time.Now().Add(-settleDelay))
+	_, err := s.subscribers.UpdateByID(ctx, subscriber,
+		bson.M{"$setOnInsert": bson.M{"position": position}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *MongoStore) ReadAfter(ctx context.Context, subscriber string, limit int64) ([]Envelope, error) {
+	var state struct {
+		Position primitive.ObjectID `bson:"position"`
+	}
+	if err := s.subscribers.FindOne(ctx, bson.M{"_id": subscriber}).Decode(&state); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": bson.M{
+		"$gt": state.Position,
+		"$lt": primitive.NewObjectIDFromTimestamp(time.Now().Add(-settleDelay)),
+	}}
+	return s.find(ctx, filter, limit)
+}
+
+// find returns the events matching filter, oldest first.
+func (s *MongoStore) find(ctx context.Context, filter bson.M, limit int64) ([]Envelope, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := s.events.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var stored []storedEvent
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+
+	envs := make([]Envelope, 0, len(stored))
+	for _, doc := range stored {
+		event, err := decodeEvent(doc.Type, doc.Data)
+		if err != nil {
+			return nil, fmt.Errorf("event %s: %w", doc.ID.Hex(), err)
+		}
+		envs = append(envs, Envelope{
+			ID:         doc.ID,
+			Type:       doc.Type,
+			TenantID:   doc.TenantID,
+			OccurredAt: doc.OccurredAt,
+			Event:      event,
+		})
+	}
+	return envs, nil
+}
+
+// ReadTenantAfter returns up to limit events of one tenant and of the given types that
+// were stored after the event with the given ID, oldest first. Unlike ReadAfter it keeps
+// no position, so any number of readers can follow the log of a tenant.
+func (s *MongoStore) ReadTenantAfter(ctx context.Context, tenantID string, types []string, after primitive.ObjectID, limit int64) ([]Envelope, error) {
+	filter := bson.M{
+		"tenantId": tenantID,
+		"type":     bson.M{"$in": types},
+		"_id": bson.M{
+			"$gt": after,
+			"$lt": primitive.NewObjectIDFromTimestamp(time.Now().Add(-settleDelay)),
+		},
+	}
+	return s.find(ctx, filter, limit)
+}
+
+func (s *MongoStore) Ack(ctx context.Context, subscriber string, eventID primitive.ObjectID) error {
+	_, err := s.subscribers.UpdateByID(ctx, subscriber, bson.M{"$set": bson.M{"position": eventID}})
+	return err
+}
+
+// decodeEvent turns stored data back into the event value that was published,
+// so subscribers see the same types whether or not the bus has a store.
+func decodeEvent(eventType string, data bson.Raw) (Event, error) {
+	ptr, ok := newEvent(eventType)
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+	if err := bson.Unmarshal(data, ptr); err != nil {
+		return nil, err
+	}
+	return reflect.ValueOf(ptr).Elem().Interface().(Event), nil
+}
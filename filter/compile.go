@@ -0,0 +1,290 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldKind says how a field is stored and which operators it supports.
+type FieldKind int
+
+const (
+	// TextField is a string. = and != ignore case; ~ and !~ test for a part of the text.
+	TextField FieldKind = iota
+	// NumberField supports the comparison operators.
+	NumberField
+	// DateField accepts dates and relative dates. Comparing with a whole day, such as
+	// 2026-03-01 or today+7d, covers the entire day.
+	DateField
+	// IDField is an ObjectID compared with hex strings.
+	IDField
+	// NameField is a reference to another entity compared by that entity's name, such as
+	// location = "Berlin". The names come from Schema.Names.
+	NameField
+)
+
+// Field is a field an expression may filter on.
+type Field struct {
+	Kind   FieldKind
+	Column string // Document field; defaults to the name the expression uses
+	Slug   string // NameField: the entity type whose names are compared
+}
+
+// Schema lists the fields of one collection that may be filtered on.
+type Schema struct {
+	Fields map[string]Field
+	// Names returns the names of the entities of a type by ID, for NameFields.
+	Names func(slug string) (map[primitive.ObjectID]string, error)
+}
+
+// Compile turns an expression into a MongoDB query. Relative dates are resolved against now.
+func Compile(expr Expr, schema Schema, now time.Time) (bson.M, error) {
+	switch e := expr.(type) {
+	case And:
+		parts, err := compileAll(e, schema, now)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$and": parts}, nil
+	case Or:
+		parts, err := compileAll(e, schema, now)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$or": parts}, nil
+	case Not:
+		part, err := Compile(e.Expr, schema, now)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$nor": bson.A{part}}, nil
+	case Condition:
+		return compileCondition(e, schema, now)
+	}
+	return nil, fmt.Errorf("unknown expression %T", expr)
+}
+
+func compileAll(exprs []Expr, schema Schema, now time.Time) (bson.A, error) {
+	parts := make(bson.A, len(exprs))
+	for i, expr := range exprs {
+		part, err := Compile(expr, schema, now)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = part
+	}
+	return parts, nil
+}
+
+func compileCondition(c Condition, schema Schema, now time.Time) (bson.M, error) {
+	field, ok := schema.Fields[c.Field]
+	if !ok {
+		return nil, &Error{c.Pos, fmt.Sprintf("unknown field %q", c.Field)}
+	}
+	column := field.Column
+	if column == "" {
+		column = c.Field
+	}
+	unsupported := &Error{c.Pos, fmt.Sprintf("%s does not support %s", c.Field, c.Op)}
+
+	// null tests whether a field is unset, for every kind of field.
+	if c.Op != "IN" && c.Values[0].Kind == Null {
+		empty := bson.A{nil, ""}
+		if field.Kind == IDField || field.Kind == NameField {
+			empty = bson.A{nil, primitive.NilObjectID}
+		}
+		switch c.Op {
+		case "=":
+			return bson.M{column: bson.M{"$in": empty}}, nil
+		case "!=":
+			return bson.M{column: bson.M{"$nin": empty}}, nil
+		}
+		return nil, unsupported
+	}
+
+	switch field.Kind {
+	case TextField:
+		return compileText(c, column, unsupported)
+	case NumberField:
+		return compileNumber(c, column, unsupported)
+	case DateField:
+		return compileDate(c, column, now, unsupported)
+	case IDField:
+		return compileID(c, column, unsupported)
+	case NameField:
+		return compileName(c, column, field.Slug, schema, unsupported)
+	}
+	return nil, unsupported
+}
+
+// expect checks that every value of a condition is of one of the kinds.
+func expect(c Condition, description string, kinds ...ValueKind) error {
+	for _, value := range c.Values {
+		ok := false
+		for _, kind := range kinds {
+			ok = ok || value.Kind == kind
+		}
+		if !ok {
+			return &Error{value.Pos, fmt.Sprintf("%s needs %s", c.Field, description)}
+		}
+	}
+	return nil
+}
+
+// exactText matches a whole text, ignoring case.
+func exactText(text string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(text) + "$", Options: "i"}
+}
+
+func compileText(c Condition, column string, unsupported error) (bson.M, error) {
+	if err := expect(c, "text in quotes", String); err != nil {
+		return nil, err
+	}
+	text := c.Values[0].Text
+	switch c.Op {
+	case "=":
+		return bson.M{column: exactText(text)}, nil
+	case "!=":
+		return bson.M{column: bson.M{"$not": exactText(text)}}, nil
+	case "~":
+		return bson.M{column: primitive.Regex{Pattern: regexp.QuoteMeta(text), Options: "i"}}, nil
+	case "!~":
+		return bson.M{column: bson.M{"$not": primitive.Regex{Pattern: regexp.QuoteMeta(text), Options: "i"}}}, nil
+	case "IN":
+		patterns := bson.A{}
+		for _, value := range c.Values {
+			patterns = append(patterns, exactText(value.Text))
+		}
+		return bson.M{column: bson.M{"$in": patterns}}, nil
+	}
+	return nil, unsupported
+}
+
+var comparisonOps = map[string]string{"=": "$eq", "!=": "$ne", ">": "$gt", ">=": "$gte", "<": "$lt", "<=": "$lte"}
+
+func compileNumber(c Condition, column string, unsupported error) (bson.M, error) {
+	if err := expect(c, "a number", Number); err != nil {
+		return nil, err
+	}
+	if c.Op == "IN" {
+		numbers := bson.A{}
+		for _, value := range c.Values {
+			numbers = append(numbers, value.Number)
+		}
+		return bson.M{column: bson.M{"$in": numbers}}, nil
+	}
+	op, ok := comparisonOps[c.Op]
+	if !ok {
+		return nil, unsupported
+	}
+	return bson.M{column: bson.M{op: c.Values[0].Number}}, nil
+}
+
+func compileDate(c Condition, column string, now time.Time, unsupported error) (bson.M, error) {
+	if err := expect(c, "a date such as 2026-03-01 or now+30d", Date, Relative); err != nil {
+		return nil, err
+	}
+	// Each value covers [start, end): one instant, or a whole day.
+	bounds := func(value Value) (primitive.DateTime, primitive.DateTime) {
+		start := value.Resolve(now)
+		if value.DayOf {
+			start = start.Truncate(24 * time.Hour)
+			return primitive.NewDateTimeFromTime(start), primitive.NewDateTimeFromTime(start.AddDate(0, 0, 1))
+		}
+		return primitive.NewDateTimeFromTime(start), primitive.NewDateTimeFromTime(start.Add(time.Millisecond))
+	}
+	within := func(value Value) bson.M {
+		start, end := bounds(value)
+		return bson.M{column: bson.M{"$gte": start, "$lt": end}}
+	}
+
+	start, end := bounds(c.Values[0])
+	switch c.Op {
+	case "=":
+		return within(c.Values[0]), nil
+	case "!=":
+		return bson.M{"$nor": bson.A{within(c.Values[0])}}, nil
+	case ">":
+		return bson.M{column: bson.M{"$gte": end}}, nil
+	case ">=":
+		return bson.M{column: bson.M{"$gte": start}}, nil
+	case "<":
+		return bson.M{column: bson.M{"$lt": start}}, nil
+	case "<=":
+		return bson.M{column: bson.M{"$lt": end}}, nil
+	case "IN":
+		days := bson.A{}
+		for _, value := range c.Values {
+			days = append(days, within(value))
+		}
+		return bson.M{"$or": days}, nil
+	}
+	return nil, unsupported
+}
+
+func compileID(c Condition, column string, unsupported error) (bson.M, error) {
+	if err := expect(c, "an ID in quotes", String); err != nil {
+		return nil, err
+	}
+	ids := bson.A{}
+	for _, value := range c.Values {
+		id, err := primitive.ObjectIDFromHex(value.Text)
+		if err != nil {
+			return nil, &Error{value.Pos, fmt.Sprintf("%q is not a valid ID", value.Text)}
+		}
+		ids = append(ids, id)
+	}
+	switch c.Op {
+	case "=":
+		return bson.M{column: ids[0]}, nil
+	case "!=":
+		return bson.M{column: bson.M{"$ne": ids[0]}}, nil
+	case "IN":
+		return bson.M{column: bson.M{"$in": ids}}, nil
+	}
+	return nil, unsupported
+}
+
+func compileName(c Condition, column, slug string, schema Schema, unsupported error) (bson.M, error) {
+	if err := expect(c, "a name in quotes", String); err != nil {
+		return nil, err
+	}
+	names, err := schema.Names(slug)
+	if err != nil {
+		return nil, err
+	}
+	// Find the entities whose name matches any of the values.
+	matches := func(name string) bool {
+		for _, value := range c.Values {
+			switch c.Op {
+			case "=", "!=", "IN":
+				if strings.EqualFold(name, value.Text) {
+					return true
+				}
+			case "~", "!~":
+				if strings.Contains(strings.ToLower(name), strings.ToLower(value.Text)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	ids := bson.A{}
+	for id, name := range names {
+		if matches(name) {
+			ids = append(ids, id)
+		}
+	}
+	switch c.Op {
+	case "=", "~", "IN":
+		return bson.M{column: bson.M{"$in": ids}}, nil
+	case "!=", "!~":
+		return bson.M{column: bson.M{"$nin": ids}}, nil
+	}
+	return nil, unsupported
+}
@@ -0,0 +1,148 @@
+package filter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCompile(t *testing.T) {
+	berlin, munich := primitive.NewObjectID(), primitive.NewObjectID()
+	employee := primitive.NewObjectID()
+	schema := Schema{
+		Fields: map[string]Field{
+			"name":     {Kind: TextField},
+			"count":    {Kind: NumberField},
+			"start":    {Kind: DateField, Column: "onboardingDate"},
+			"id":       {Kind: IDField, Column: "_id"},
+			"location": {Kind: NameField, Column: "locationId", Slug: "locations"},
+		},
+		Names: func(slug string) (map[primitive.ObjectID]string, error) {
+			if slug != "locations" {
+				return nil, errors.New("unexpected slug " + slug)
+			}
+			return map[primitive.ObjectID]string{berlin: "Berlin", munich: "München"}, nil
+		},
+	}
+	now := time.Date(2026, 1, 31, 15, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) primitive.DateTime {
+		return primitive.NewDateTimeFromTime(time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	}
+	exact := func(pattern string) primitive.Regex { return primitive.Regex{Pattern: pattern, Options: "i"} }
+
+	tests := []struct {
+		name  string
+		input string
+		want  bson.M
+	}{
+		// Precedence and parentheses.
+		{"precedence", `name = "a" OR count > 1 AND NOT count = 2`, bson.M{"$or": bson.A{
+			bson.M{"name": exact("^a$")},
+			bson.M{"$and": bson.A{
+				bson.M{"count": bson.M{"$gt": 1.0}},
+				bson.M{"$nor": bson.A{bson.M{"count": bson.M{"$eq": 2.0}}}},
+			}},
+		}}},
+		{"parentheses", `(name = "a" OR name = "b") AND count < 3`, bson.M{"$and": bson.A{
+			bson.M{"$or": bson.A{bson.M{"name": exact("^a$")}, bson.M{"name": exact("^b$")}}},
+			bson.M{"count": bson.M{"$lt": 3.0}},
+		}}},
+
+		// Text goes through QuoteMeta, so regex metacharacters match themselves.
+		{"metacharacters in =", `name = "a.b*"`, bson.M{"name": exact(`^a\.b\*$`)}},
+		{"metacharacters in ~", `name ~ "(x|y)+"`, bson.M{"name": exact(`\(x\|y\)\+`)}},
+		{"anchors in !~", `name !~ "^$"`, bson.M{"name": bson.M{"$not": exact(`\^\$`)}}},
+		{"escaped quote and backslash", `name != "O\'B\\"`, bson.M{"name": bson.M{"$not": exact(`^O'B\\$`)}}},
+		{"IN", `name IN ("a", "[b]")`, bson.M{"name": bson.M{"$in": bson.A{exact("^a$"), exact(`^\[b\]$`)}}}},
+		{"null text", `name = null`, bson.M{"name": bson.M{"$in": bson.A{nil, ""}}}},
+		{"not null text", `name != null`, bson.M{"name": bson.M{"$nin": bson.A{nil, ""}}}},
+
+		// Numbers.
+		{"number", `count >= 3`, bson.M{"count": bson.M{"$gte": 3.0}}},
+		{"number IN", `count IN (1, 2.5)`, bson.M{"count": bson.M{"$in": bson.A{1.0, 2.5}}}},
+
+		// Dates cover a whole day unless they name an instant.
+		{"day", `start = 2026-03-01`, bson.M{"onboardingDate": bson.M{"$gte": day(2026, 3, 1), "$lt": day(2026, 3, 2)}}},
+		{"after a day", `start > 2026-03-01`, bson.M{"onboardingDate": bson.M{"$gte": day(2026, 3, 2)}}},
+		{"up to a day", `start <= 2026-03-01`, bson.M{"onboardingDate": bson.M{"$lt": day(2026, 3, 2)}}},
+		{"not on a day", `start != 2026-03-01`, bson.M{"$nor": bson.A{bson.M{"onboardingDate": bson.M{"$gte": day(2026, 3, 1), "$lt": day(2026, 3, 2)}}}}},
+		{"relative day", `start < today+7d`, bson.M{"onboardingDate": bson.M{"$lt": day(2026, 2, 7)}}},
+		{"relative instant", `start >= now-2h`, bson.M{"onboardingDate": bson.M{"$gte": primitive.NewDateTimeFromTime(now.Add(-2 * time.Hour))}}},
+		{"null date", `start = null`, bson.M{"onboardingDate": bson.M{"$in": bson.A{nil, ""}}}},
+
+		// References.
+		{"ID", `id = "` + employee.Hex() + `"`, bson.M{"_id": employee}},
+		{"null ID", `id = null`, bson.M{"_id": bson.M{"$in": bson.A{nil, primitive.NilObjectID}}}},
+		{"name ignores case", `location = "berlin"`, bson.M{"locationId": bson.M{"$in": bson.A{berlin}}}},
+		{"name contains", `location !~ "MÜN"`, bson.M{"locationId": bson.M{"$nin": bson.A{munich}}}},
+		{"unknown name", `location = "Paris"`, bson.M{"locationId": bson.M{"$in": bson.A{}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			got, err := Compile(expr, schema, now)
+			if err != nil {
+				t.Fatalf("Compile(%q): %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Compile(%q) =\n%#v\nwant\n%#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	schema := Schema{
+		Fields: map[string]Field{
+			"name":  {Kind: TextField},
+			"count": {Kind: NumberField},
+			"start": {Kind: DateField},
+			"id":    {Kind: IDField},
+		},
+	}
+	tests := []struct {
+		name  string
+		input string
+		pos   int
+		msg   string
+	}{
+		{"unknown field", `salary > 1`, 0, `unknown field "salary"`},
+		{"unknown field in a group", `name = "a" OR (count = 1 AND secret = "x")`, 29, `unknown field "secret"`},
+		{"number as text", `name = 3`, 7, "name needs text in quotes"},
+		{"text as number", `count = "3"`, 8, "count needs a number"},
+		{"date as number", `count < 2026-03-01`, 8, "count needs a number"},
+		{"number as date", `start = 5`, 8, "start needs a date"},
+		{"quoted date", `start = "2026-03-01"`, 8, "start needs a date"},
+		{"text in a date list", `start IN (2026-03-01, "soon")`, 22, "start needs a date"},
+		{"relative date as number", `count > now`, 8, "count needs a number"},
+		{"contains on a number", `count ~ 3`, 0, "count does not support ~"},
+		{"contains on a date", `start ~ today`, 0, "start does not support ~"},
+		{"order on an ID", `id > "000000000000000000000000"`, 0, "id does not support >"},
+		{"invalid ID", `id = "employee-1"`, 5, `"employee-1" is not a valid ID`},
+		{"ordered null", `count > null`, 0, "count does not support >"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			_, err = Compile(expr, schema, time.Now())
+			var filterErr *Error
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("Compile(%q) = %v, want an *Error", tt.input, err)
+			}
+			if filterErr.Pos != tt.pos || !strings.Contains(filterErr.Msg, tt.msg) {
+				t.Fatalf("Compile(%q) error at %d %q, want at %d %q", tt.input, filterErr.Pos, filterErr.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,459 @@
+// Package filter implements the filter expressions accepted by the list endpoints, such as
+//
+//	jobRole = "Engineer" AND location IN ("Berlin", "Munich") AND onboardingDate < now+30d
+//
+// Expressions are parsed into a tree and compiled to a MongoDB query against a Schema that
+// lists the fields that may be filtered on. Values are always literals, so an expression
+// can never inject operators into the query.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// MaxLength is the longest expression accepted, in bytes.
+	MaxLength = 2000
+	// MaxConditions is the largest number of comparisons in one expression.
+	MaxConditions = 50
+	// maxDepth limits how deeply parentheses and NOT may nest.
+	maxDepth = 20
+)
+
+// Error describes a problem with an expression. Pos is the byte offset it was found at.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.Pos+1, e.Msg)
+}
+
+// Expr is a parsed expression: an And, Or, Not or Condition.
+type Expr interface {
+	isExpr()
+}
+
+// And matches documents matching all of its expressions.
+type And []Expr
+
+// Or matches documents matching any of its expressions.
+type Or []Expr
+
+// Not matches documents not matching its expression.
+type Not struct{ Expr Expr }
+
+// Condition compares a field with one value, or with a list of values for IN.
+type Condition struct {
+	Pos    int
+	Field  string
+	Op     string // "=", "!=", ">", ">=", "<", "<=", "~" (contains), "!~" or "IN"
+	Values []Value
+}
+
+func (And) isExpr()       {}
+func (Or) isExpr()        {}
+func (Not) isExpr()       {}
+func (Condition) isExpr() {}
+
+// ValueKind says what kind of literal a Value is.
+type ValueKind int
+
+const (
+	String ValueKind = iota
+	Number
+	Null
+	Date     // An absolute date such as 2026-03-01, or a date and time
+	Relative // now or today, optionally with an offset such as now+30d
+)
+
+// Value is a literal of an expression.
+type Value struct {
+	Pos    int
+	Kind   ValueKind
+	Text   string    // String
+	Number float64   // Number
+	Time   time.Time // Date
+	DayOf  bool      // Date and Relative: the value is a whole day, not an instant
+	Base   string    // Relative: "now" or "today"
+	Offset int       // Relative: amount of Unit to add, may be negative
+	Unit   byte      // Relative: 'h', 'd', 'w', 'm' (months) or 'y'
+}
+
+// Resolve returns the instant a Date or Relative value stands for.
+func (v Value) Resolve(now time.Time) time.Time {
+	if v.Kind == Date {
+		return v.Time
+	}
+	t := now.UTC()
+	if v.Base == "today" {
+		t = t.Truncate(24 * time.Hour)
+	}
+	switch v.Unit {
+	case 'h':
+		return t.Add(time.Duration(v.Offset) * time.Hour)
+	case 'd':
+		return t.AddDate(0, 0, v.Offset)
+	case 'w':
+		return t.AddDate(0, 0, 7*v.Offset)
+	case 'm':
+		return t.AddDate(0, v.Offset, 0)
+	case 'y':
+		return t.AddDate(v.Offset, 0, 0)
+	}
+	return t
+}
+
+// --- Lexer ---
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokDate
+	tokRelative
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"!=", "<>", ">=", "<=", "!~", "==", "=", ">", "<", "~"}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokComma, ",", i})
+			i++
+		case c == '"' || c == '\'':
+			text, end, err := lexString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tokString, text, i})
+			i = end
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(input) && input[i+1] >= '0' && input[i+1] <= '9':
+			end := i + 1
+			for end < len(input) && strings.IndexByte("0123456789.-:+TZ", input[end]) >= 0 {
+				end++
+			}
+			text := input[i:end]
+			kind := tokNumber
+			if _, ok := parseDate(text); ok {
+				kind = tokDate
+			} else if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, &Error{i, fmt.Sprintf("%q is neither a number nor a date", text)}
+			}
+			tokens = append(tokens, token{kind, text, i})
+			i = end
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			for end < len(input) && (input[end] == '_' || input[end] == '.' || unicode.IsLetter(rune(input[end])) || unicode.IsDigit(rune(input[end]))) {
+				end++
+			}
+			word := input[i:end]
+			if lower := strings.ToLower(word); lower == "now" || lower == "today" {
+				// A relative date may be followed by an offset, as in now+30d.
+				if end < len(input) && (input[end] == '+' || input[end] == '-') {
+					end++
+					for end < len(input) && (input[end] >= '0' && input[end] <= '9' || unicode.IsLetter(rune(input[end]))) {
+						end++
+					}
+				}
+				tokens = append(tokens, token{tokRelative, input[i:end], i})
+			} else {
+				tokens = append(tokens, token{tokIdent, word, i})
+			}
+			i = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(input[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &Error{i, fmt.Sprintf("unexpected character %q", c)}
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(input)}), nil
+}
+
+// lexString reads a quoted string starting at input[start]. A backslash escapes the next
+// character.
+func lexString(input string, start int) (string, int, error) {
+	quote := input[start]
+	var b strings.Builder
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if i+1 < len(input) {
+				i++
+				b.WriteByte(input[i])
+			}
+		case quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(input[i])
+		}
+	}
+	return "", 0, &Error{start, "unterminated string"}
+}
+
+// parseDate reads a date (2026-03-01) or a date and time in RFC 3339 format.
+func parseDate(text string) (Value, bool) {
+	if t, err := time.Parse("2006-01-02", text); err == nil {
+		return Value{Kind: Date, Time: t, DayOf: true}, true
+	}
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return Value{Kind: Date, Time: t.UTC()}, true
+	}
+	return Value{}, false
+}
+
+// --- Parser ---
+
+type parser struct {
+	tokens     []token
+	pos        int
+	depth      int
+	conditions int
+}
+
+// Parse reads an expression. Keywords (AND, OR, NOT, IN, null) are case-insensitive.
+func Parse(input string) (Expr, error) {
+	if len(input) > MaxLength {
+		return nil, &Error{MaxLength, fmt.Sprintf("the filter is longer than %d characters", MaxLength)}
+	}
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &Error{tok.pos, fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// keyword reports whether the next token is the keyword, and consumes it if so.
+func (p *parser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokIdent && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := Or{left}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, right)
+	}
+	if len(or) == 1 {
+		return left, nil
+	}
+	return or, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	and := And{left}
+	for p.keyword("AND") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, right)
+	}
+	if len(and) == 1 {
+		return left, nil
+	}
+	return and, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, &Error{p.peek().pos, "the filter is nested too deeply"}
+	}
+
+	if p.keyword("NOT") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{expr}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokRParen {
+			return nil, &Error{tok.pos, "missing )"}
+		}
+		return expr, nil
+	}
+	return p.parseCondition()
+}
+
+func (p *parser) parseCondition() (Expr, error) {
+	field := p.next()
+	if field.kind != tokIdent {
+		return nil, &Error{field.pos, "expected a field name"}
+	}
+	p.conditions++
+	if p.conditions > MaxConditions {
+		return nil, &Error{field.pos, fmt.Sprintf("the filter has more than %d conditions", MaxConditions)}
+	}
+	condition := Condition{Pos: field.pos, Field: field.text}
+
+	if p.keyword("IN") {
+		condition.Op = "IN"
+		if tok := p.next(); tok.kind != tokLParen {
+			return nil, &Error{tok.pos, "expected ( after IN"}
+		}
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			condition.Values = append(condition.Values, value)
+			tok := p.next()
+			if tok.kind == tokRParen {
+				break
+			}
+			if tok.kind != tokComma {
+				return nil, &Error{tok.pos, "expected , or )"}
+			}
+		}
+		return condition, nil
+	}
+
+	op := p.next()
+	if op.kind != tokOp {
+		return nil, &Error{op.pos, fmt.Sprintf("expected an operator after %s", field.text)}
+	}
+	switch op.text {
+	case "==":
+		condition.Op = "="
+	case "<>":
+		condition.Op = "!="
+	default:
+		condition.Op = op.text
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	condition.Values = []Value{value}
+	return condition, nil
+}
+
+func (p *parser) parseValue() (Value, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		return Value{Pos: tok.pos, Kind: String, Text: tok.text}, nil
+	case tokNumber:
+		n, _ := strconv.ParseFloat(tok.text, 64)
+		return Value{Pos: tok.pos, Kind: Number, Number: n}, nil
+	case tokDate:
+		value, _ := parseDate(tok.text)
+		value.Pos = tok.pos
+		return value, nil
+	case tokRelative:
+		return parseRelative(tok)
+	case tokIdent:
+		if strings.EqualFold(tok.text, "null") {
+			return Value{Pos: tok.pos, Kind: Null}, nil
+		}
+		return Value{}, &Error{tok.pos, fmt.Sprintf("%s is not a value; put text in quotes", tok.text)}
+	}
+	return Value{}, &Error{tok.pos, "expected a value"}
+}
+
+// parseRelative reads now or today with an optional offset such as +30d, -2w or +1m.
+func parseRelative(tok token) (Value, error) {
+	text := strings.ToLower(tok.text)
+	value := Value{Pos: tok.pos, Kind: Relative, Base: text, DayOf: strings.HasPrefix(text, "today")}
+	if i := strings.IndexAny(text, "+-"); i >= 0 {
+		value.Base = text[:i]
+		offset := text[i+1:]
+		if len(offset) < 2 || strings.IndexByte("hdwmy", offset[len(offset)-1]) < 0 {
+			return Value{}, &Error{tok.pos, fmt.Sprintf("%q needs an offset such as +30d; units are h, d, w, m and y", tok.text)}
+		}
+		n, err := strconv.Atoi(offset[:len(offset)-1])
+		if err != nil {
+			return Value{}, &Error{tok.pos, fmt.Sprintf("invalid offset in %q", tok.text)}
+		}
+		if text[i] == '-' {
+			n = -n
+		}
+		value.Offset, value.Unit = n, offset[len(offset)-1]
+		if value.Unit == 'h' {
+			value.DayOf = false
+		}
+	}
+	return value, nil
+}
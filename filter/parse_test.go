@@ -0,0 +1,198 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// render prints a parsed expression with explicit parentheses, so tests can compare trees.
+func render(expr Expr) string {
+	join := func(exprs []Expr, sep string) string {
+		parts := make([]string, len(exprs))
+		for i, e := range exprs {
+			parts[i] = render(e)
+		}
+		return "(" + strings.Join(parts, sep) + ")"
+	}
+	switch e := expr.(type) {
+	case And:
+		return join(e, " AND ")
+	case Or:
+		return join(e, " OR ")
+	case Not:
+		return "NOT " + render(e.Expr)
+	case Condition:
+		values := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			values[i] = renderValue(v)
+		}
+		if e.Op == "IN" {
+			return fmt.Sprintf("%s IN [%s]", e.Field, strings.Join(values, ", "))
+		}
+		return fmt.Sprintf("%s %s %s", e.Field, e.Op, values[0])
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func renderValue(v Value) string {
+	switch v.Kind {
+	case String:
+		return fmt.Sprintf("%q", v.Text)
+	case Number:
+		return fmt.Sprintf("%g", v.Number)
+	case Null:
+		return "null"
+	case Date:
+		if v.DayOf {
+			return "day:" + v.Time.Format("2006-01-02")
+		}
+		return "at:" + v.Time.Format(time.RFC3339)
+	case Relative:
+		s := v.Base
+		if v.Unit != 0 {
+			s += fmt.Sprintf("%+d%c", v.Offset, v.Unit)
+		}
+		if v.DayOf {
+			s += "/day"
+		}
+		return s
+	}
+	return "?"
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		// Precedence and parentheses.
+		{"AND binds tighter than OR", `a = "x" OR b = "y" AND c = "z"`, `(a = "x" OR (b = "y" AND c = "z"))`},
+		{"parentheses group OR", `(a = "x" OR b = "y") AND c = "z"`, `((a = "x" OR b = "y") AND c = "z")`},
+		{"NOT binds tightest", `NOT a = "x" AND b = "y"`, `(NOT a = "x" AND b = "y")`},
+		{"NOT of a group", `NOT (a = "x" OR b = "y")`, `NOT (a = "x" OR b = "y")`},
+		{"redundant parentheses", `((a = "x"))`, `a = "x"`},
+		{"keywords ignore case", `a = "x" and not b = "y" Or c in ("p")`, `((a = "x" AND NOT b = "y") OR c IN ["p"])`},
+		{"chained AND is flat", `a = 1 AND b = 2 AND c = 3`, `(a = 1 AND b = 2 AND c = 3)`},
+
+		// Operators.
+		{"== is =", `a == 1`, `a = 1`},
+		{"<> is !=", `a <> 1`, `a != 1`},
+		{"contains", `a ~ "x"`, `a ~ "x"`},
+		{"not contains", `a !~ "x"`, `a !~ "x"`},
+		{"comparison without spaces", `a>=-1.5`, `a >= -1.5`},
+		{"IN list", `a IN ("x", 'y', 3, null)`, `a IN ["x", "y", 3, null]`},
+
+		// Quoting and escapes.
+		{"single quotes", `a = 'x y'`, `a = "x y"`},
+		{"escaped quote", `a = 'O\'Brien'`, `a = "O'Brien"`},
+		{"escaped double quote", `a = "say \"hi\""`, `a = "say \"hi\""`},
+		{"escaped backslash", `a = "a\\b"`, `a = "a\\b"`},
+		{"other quote needs no escape", `a = 'he said "hi"'`, `a = "he said \"hi\""`},
+		{"keywords inside quotes", `a = "x AND b = y"`, `a = "x AND b = y"`},
+		{"empty string", `a = ""`, `a = ""`},
+		{"null ignores case", `a = NULL`, `a = null`},
+
+		// Dates.
+		{"day", `d >= 2026-03-01`, `d >= day:2026-03-01`},
+		{"instant in UTC", `d < 2026-03-01T10:00:00+02:00`, `d < at:2026-03-01T08:00:00Z`},
+		{"now", `d < now`, `d < now`},
+		{"now with offset", `d < now+30d`, `d < now+30d`},
+		{"today with negative offset", `d >= today-2w`, `d >= today-2w/day`},
+		{"hours are not a whole day", `d >= TODAY+3h`, `d >= today+3h`},
+		{"months", `d < now+1m`, `d < now+1m`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			if got := render(expr); got != tt.want {
+				t.Fatalf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	deep := strings.Repeat("(", maxDepth+1) + `a = 1` + strings.Repeat(")", maxDepth+1)
+	many := strings.TrimSuffix(strings.Repeat(`a = 1 OR `, MaxConditions+1), " OR ")
+
+	tests := []struct {
+		name  string
+		input string
+		pos   int
+		msg   string
+	}{
+		// Errors at the end of the input.
+		{"empty", ``, 0, "expected a field name"},
+		{"missing value", `a =`, 3, "expected a value"},
+		{"missing operator", `a`, 1, "expected an operator after a"},
+		{"dangling AND", `a = "x" AND`, 11, "expected a field name"},
+		{"dangling NOT", `NOT`, 3, "expected a field name"},
+		{"unclosed parenthesis", `(a = "x"`, 8, "missing )"},
+		{"unclosed IN list", `a IN ("x", "y"`, 14, "expected , or )"},
+		{"IN without list", `a IN`, 4, "expected ( after IN"},
+		{"unterminated string", `a = "x`, 4, "unterminated string"},
+		{"escaped closing quote", `a = "x\"`, 4, "unterminated string"},
+
+		// Errors inside the input.
+		{"unexpected token", `a = "x")`, 7, `unexpected ")"`},
+		{"two conditions without AND", `a = 1 b = 2`, 6, `unexpected "b"`},
+		{"bare word", `a = x`, 4, "x is not a value; put text in quotes"},
+		{"IN without parenthesis", `a IN "x"`, 5, "expected ( after IN"},
+		{"unknown character", `a # 1`, 2, "unexpected character '#'"},
+		{"bad number", `a = 1.2.3`, 4, `"1.2.3" is neither a number nor a date`},
+		{"invalid date", `d = 2026-13-01`, 4, "neither a number nor a date"},
+		{"offset without unit", `d < now+30`, 4, "needs an offset such as +30d"},
+		{"offset with unknown unit", `d < now+3q`, 4, "needs an offset such as +30d"},
+		{"offset without number", `d < now+xd`, 4, "invalid offset"},
+
+		// Limits.
+		{"too deep", deep, maxDepth, "nested too deeply"},
+		{"too many conditions", many, MaxConditions * 9, fmt.Sprintf("more than %d conditions", MaxConditions)},
+		{"too long", `a = "` + strings.Repeat("x", MaxLength) + `"`, MaxLength, "longer than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			var filterErr *Error
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("Parse(%q) = %v, %v; want an *Error", tt.input, render(expr), err)
+			}
+			if filterErr.Pos != tt.pos || !strings.Contains(filterErr.Msg, tt.msg) {
+				t.Fatalf("Parse(%q) error at %d %q, want at %d %q", tt.input, filterErr.Pos, filterErr.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2026, 1, 31, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"now", now},
+		{"today", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"now+2h", now.Add(2 * time.Hour)},
+		{"now-1d", now.AddDate(0, 0, -1)},
+		{"today+1w", time.Date(2026, 2, 7, 0, 0, 0, 0, time.UTC)},
+		{"today+1m", time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)}, // Normalized like time.AddDate
+		{"now-1y", now.AddDate(-1, 0, 0)},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		expr, err := Parse("d = " + tt.input)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		if got := expr.(Condition).Values[0].Resolve(now); !got.Equal(tt.want) {
+			t.Errorf("%s resolves to %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/your-username/onboarding/api"
 	"github.com/your-username/onboarding/config"
 	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/notifications"
+	"github.com/your-username/onboarding/scheduler"
+	"github.com/your-username/onboarding/services"
+	"github.com/your-username/onboarding/storage"
 )
 
 func main() {
@@ -15,10 +21,62 @@ func main() {
 	// 2. Initialize Database connection.
 	db.InitDB()
 
-	// 3. Setup the Gin router with all our defined routes.
+	// 3. Wire up file storage and the event bus, and start the background workers.
+	ctx := context.Background()
+	blobStore, err := storage.New()
+	if err != nil {
+		log.Fatalf("Could not set up file storage: %v", err)
+	}
+	services.UseBlobStore(blobStore)
+	var eventStore *events.MongoStore
+	if config.AppConfig.EventStore == "mongo" {
+		if eventStore, err = events.NewMongoStore(ctx); err != nil {
+			log.Fatalf("Could not set up the event store: %v", err)
+		}
+		events.UseStore(eventStore)
+	}
+	services.RegisterEventSubscribers()
+	notifications.RegisterSubscribers()
+	if err := events.Start(ctx); err != nil {
+		log.Fatalf("Could not start the event bus: %v", err)
+	}
+	services.StartWebhookDispatcher(ctx)
+	if err := services.FixEmployeeValues(ctx); err != nil {
+		log.Fatalf("Could not fix employee values: %v", err)
+	}
+	if err := services.MigrateDocumentRetention(ctx); err != nil {
+		log.Fatalf("Could not migrate document retention: %v", err)
+	}
+	if err := services.EnsureAssetIndexes(ctx); err != nil {
+		log.Fatalf("Could not set up the hardware inventory: %v", err)
+	}
+	if err := services.EnsureVersionIndexes(ctx); err != nil {
+		log.Fatalf("Could not set up the record history: %v", err)
+	}
+	if err := services.EnsureScheduledChangeIndexes(ctx); err != nil {
+		log.Fatalf("Could not set up scheduled changes: %v", err)
+	}
+	if err := services.InitSearch(ctx); err != nil {
+		log.Fatalf("Could not set up search: %v", err)
+	}
+	services.InitChangeFeed(ctx, eventStore)
+	mailSender, err := notifications.NewSender()
+	if err != nil {
+		log.Fatalf("Could not set up email delivery: %v", err)
+	}
+	if err := notifications.Start(ctx, mailSender); err != nil {
+		log.Fatalf("Could not start email notifications: %v", err)
+	}
+	services.RegisterJobs()
+	notifications.RegisterJobs()
+	if err := scheduler.Start(ctx); err != nil {
+		log.Fatalf("Could not start the job scheduler: %v", err)
+	}
+
+	// 4. Setup the Gin router with all our defined routes.
 	router := api.SetupRouter()
 
-	// 4. Start the server.
+	// 5. Start the server.
 	log.Println("Starting server on http://localhost:8080")
 	if err := router.Run(":8080"); err != nil {
 		log.Fatalf("Failed to run server: %v", err)
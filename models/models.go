@@ -6,9 +6,13 @@ import "go.mongodb.org/mongo-driver/bson/primitive"
 type Tenant struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Name            string             `bson:"name" json:"name"`     // e.g., "Acme Corporation"
-	Status          string             `bson:"status" json:"status"` // e.g., "active", "suspended", "trial"
+	Status          string             `bson:"status" json:"status"` // e.g., "active", "suspended", "trial", "expired"
 	CreatedAt       primitive.DateTime `bson:"createdAt" json:"createdAt"`
 	EnabledEntities []string           `bson:"enabledEntities" json:"enabledEntities"` // Stores slugs like "locations", "departments", "costs"
+
+	// TrashRetentionDays is how long deleted employees and entities can be restored before
+	// they are purged. Zero means the default of 30 days.
+	TrashRetentionDays int `bson:"trashRetentionDays,omitempty" json:"trashRetentionDays,omitempty"`
 }
 
 // User represents a user who can log in and perform actions within a specific tenant.
@@ -17,7 +21,7 @@ type User struct {
 	Username string             `bson:"username" json:"username"`
 	Password string             `bson:"password" json:"-"` // Omit password from JSON responses for security.
 	TenantID string             `bson:"tenantId" json:"tenantId"`
-	Role     string             `bson:"role" json:"role"` // e.g., "admin", "member"
+	Role     string             `bson:"role" json:"role"` // "admin", "member", "manager" or "security"
 }
 
 // --- Base and Specific Entity Structs ---
@@ -39,13 +43,16 @@ type Location struct {
 	PostalCode string           `bson:"postalCode" json:"postalCode"`
 }
 
-// 2. Department represents a company department.
+// 2. Department represents a company department. Departments nest through ParentID.
 type Department struct {
 	BaseEntity `bson:",inline"`
-	Head       string `bson:"head" json:"head"` // Name of the department head
+	Head       string             `bson:"head" json:"head"`                             // Name of the department head
+	ParentID   primitive.ObjectID `bson:"parentId,omitempty" json:"parentId,omitempty"` // Department this one belongs to; unset for top-level departments
 }
 
-// 3. Manager represents a person to whom an employee reports.
+// 3. Manager represents a person to whom an employee reports. Reporting lines between
+// employees use Employee.ReportsToID instead; this entity is for managers without an
+// employee record.
 type Manager struct {
 	BaseEntity `bson:",inline"` // Name here would be the Manager's full name
 	Email      string           `bson:"email" json:"email"`
@@ -64,25 +71,35 @@ type EmploymentType struct {
 
 // 6. Team represents a specific group within a department (e.g., "Frontend", "Platform").
 type Team struct {
-	BaseEntity `bson:",inline"`
+	BaseEntity   `bson:",inline"`
+	DepartmentID primitive.ObjectID `bson:"departmentId,omitempty" json:"departmentId,omitempty"`
 }
 
 // 7. CostCenter is an accounting entity for tracking expenses.
 type CostCenter struct {
-	BaseEntity `bson:",inline"`
-	Code       string `bson:"code" json:"code"` // e.g., "FIN-404", "ENG-101"
+	BaseEntity      `bson:",inline"`
+	Code            string  `bson:"code" json:"code"`                                           // e.g., "FIN-404", "ENG-101"
+	HeadcountBudget int     `bson:"headcountBudget,omitempty" json:"headcountBudget,omitempty"` // New hires budgeted per month; 0 means no budget
+	PerHireCost     float64 `bson:"perHireCost,omitempty" json:"perHireCost,omitempty"`         // Cost of every new hire besides hardware, e.g. licenses
 }
 
-// 8. HardwareAsset represents a piece of company equipment assigned to an employee.
+// 8. HardwareAsset is a model of company equipment, such as a laptop. The individual
+// devices of a model are tracked as AssetUnits.
 type HardwareAsset struct {
 	BaseEntity  `bson:",inline"` // Name here would be "MacBook Pro 16 Inch"
 	ModelNumber string           `bson:"modelNumber" json:"modelNumber"`
+	UnitCost    float64          `bson:"unitCost,omitempty" json:"unitCost,omitempty"` // Price of one unit, for cost reporting
 }
 
 // 9. OnboardingBuddy is a peer assigned to help a new hire.
 type OnboardingBuddy struct {
 	BaseEntity `bson:",inline"`   // Name is the buddy's full name
 	TeamID     primitive.ObjectID `bson:"teamId,omitempty" json:"teamId,omitempty"` // Optional reference to the buddy's team
+	Email      string             `bson:"email,omitempty" json:"email,omitempty"`   // Used to notify the buddy about new hires
+	// EmployeeID links the buddy to their employee record, whose team, department and
+	// location are used to suggest buddies for new hires.
+	EmployeeID primitive.ObjectID `bson:"employeeId,omitempty" json:"employeeId,omitempty"`
+	MaxBuddies int                `bson:"maxBuddies,omitempty" json:"maxBuddies,omitempty"` // New hires looked after at once; 0 uses the default
 }
 
 // 10. AccessLevel defines a permissions or security clearance level.
@@ -112,7 +129,390 @@ type Employee struct {
 	EmploymentTypeID  primitive.ObjectID `bson:"employmentTypeId" json:"employmentTypeId"`
 	TeamID            primitive.ObjectID `bson:"teamId" json:"teamId"`
 	CostCenterID      primitive.ObjectID `bson:"costCenterId" json:"costCenterId"`
-	HardwareAssetID   primitive.ObjectID `bson:"hardwareAssetId" json:"hardwareAssetId"`
+	HardwareAssetID   primitive.ObjectID `bson:"hardwareAssetId" json:"hardwareAssetId"` // Model the employee needs; the units handed out are AssetUnits
 	OnboardingBuddyID primitive.ObjectID `bson:"onboardingBuddyId" json:"onboardingBuddyId"`
 	AccessLevelID     primitive.ObjectID `bson:"accessLevelId" json:"accessLevelId"`
+
+	// ReportsToID is the employee this employee reports to. It forms the org chart and must
+	// not create a cycle.
+	ReportsToID primitive.ObjectID `bson:"reportsToId,omitempty" json:"reportsToId,omitempty"`
+
+	// Filled in by the new hire on the pre-boarding portal.
+	EmergencyContact *EmergencyContact `bson:"emergencyContact,omitempty" json:"emergencyContact,omitempty"`
+}
+
+// EmergencyContact is the person to call if something happens to an employee at work.
+type EmergencyContact struct {
+	Name         string `bson:"name" json:"name"`
+	Relationship string `bson:"relationship,omitempty" json:"relationship,omitempty"` // e.g., "Partner", "Parent"
+	PhoneNumber  string `bson:"phoneNumber" json:"phoneNumber"`
+}
+
+// --- Bulk Import ---
+
+// ImportRowError describes a problem with one row of an import file.
+// Row numbers are 1-based and count the header row, matching what a spreadsheet shows.
+type ImportRowError struct {
+	Row     int    `bson:"row" json:"row"`
+	Field   string `bson:"field,omitempty" json:"field,omitempty"`
+	Message string `bson:"message" json:"message"`
+}
+
+// ImportJob tracks an asynchronous bulk import of employees or entities.
+type ImportJob struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID      string             `bson:"tenantId" json:"tenantId"`
+	EntityType    string             `bson:"entityType" json:"entityType"` // "employees" or an entity slug such as "locations"
+	FileName      string             `bson:"fileName" json:"fileName"`
+	Status        string             `bson:"status" json:"status"` // "pending", "running", "completed", "failed"
+	TotalRows     int                `bson:"totalRows" json:"totalRows"`
+	ProcessedRows int                `bson:"processedRows" json:"processedRows"`
+	CreatedRows   int                `bson:"createdRows" json:"createdRows"`
+	Errors        []ImportRowError   `bson:"errors" json:"errors"`
+	CreatedBy     string             `bson:"createdBy" json:"createdBy"`
+	CreatedAt     primitive.DateTime `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     primitive.DateTime `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"` // Last progress of the job
+	CompletedAt   primitive.DateTime `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// --- Webhooks ---
+
+// WebhookSubscription is a tenant-configured endpoint that receives events via HTTP POST.
+type WebhookSubscription struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID   string             `bson:"tenantId" json:"tenantId"`
+	URL        string             `bson:"url" json:"url"`
+	EventTypes []string           `bson:"eventTypes" json:"eventTypes"` // e.g. "employee.created"; "*" subscribes to everything
+	Secret     string             `bson:"secret" json:"-"`              // Used to sign payloads; only returned when the subscription is created
+	Active     bool               `bson:"active" json:"active"`
+	CreatedAt  primitive.DateTime `bson:"createdAt" json:"createdAt"`
+}
+
+// WebhookDelivery is one event queued for one subscription. The webhook_deliveries
+// collection doubles as the outbox: deliveries are written when the event happens and
+// removed from the queue only once the receiver has acknowledged them.
+type WebhookDelivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID       string             `bson:"tenantId" json:"tenantId"`
+	SubscriptionID primitive.ObjectID `bson:"subscriptionId" json:"subscriptionId"`
+	EventID        primitive.ObjectID `bson:"eventId" json:"eventId"`
+	EventType      string             `bson:"eventType" json:"eventType"`
+	Payload        string             `bson:"payload" json:"payload"` // Exact JSON body that is signed and sent
+	Status         string             `bson:"status" json:"status"`   // "pending", "delivering", "delivered", "failed"
+	Attempts       int                `bson:"attempts" json:"attempts"`
+	NextAttemptAt  primitive.DateTime `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LockedUntil    primitive.DateTime `bson:"lockedUntil,omitempty" json:"-"`
+	LastStatusCode int                `bson:"lastStatusCode,omitempty" json:"lastStatusCode,omitempty"`
+	LastError      string             `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	CreatedAt      primitive.DateTime `bson:"createdAt" json:"createdAt"`
+	DeliveredAt    primitive.DateTime `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+}
+
+// --- Notifications ---
+
+// NotificationTemplate is a tenant's email template for one trigger. Subject and Body are
+// Go text/template sources; see the notifications package for the available variables.
+type NotificationTemplate struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID   string             `bson:"tenantId" json:"tenantId"`
+	Name       string             `bson:"name" json:"name"`
+	Trigger    string             `bson:"trigger" json:"trigger"`                           // "employee_created", "onboarding_reminder" or "portal_invite"
+	DaysBefore int                `bson:"daysBefore,omitempty" json:"daysBefore,omitempty"` // For reminders: days before the onboarding date
+	Recipients []string           `bson:"recipients" json:"recipients"`                     // "employee", "manager", "buddy" or email addresses
+	Subject    string             `bson:"subject" json:"subject"`
+	Body       string             `bson:"body" json:"body"`
+	Active     bool               `bson:"active" json:"active"`
+	UpdatedAt  primitive.DateTime `bson:"updatedAt" json:"updatedAt"`
+}
+
+// EmailMessage is a rendered email in the outgoing queue.
+type EmailMessage struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID      string             `bson:"tenantId" json:"tenantId"`
+	Trigger       string             `bson:"trigger" json:"trigger"`
+	TemplateName  string             `bson:"templateName" json:"templateName"`
+	EmployeeID    primitive.ObjectID `bson:"employeeId" json:"employeeId"`
+	To            []string           `bson:"to" json:"to"`
+	Subject       string             `bson:"subject" json:"subject"`
+	Body          string             `bson:"body" json:"body"`
+	PortalToken   string             `bson:"portalToken,omitempty" json:"-"` // Magic link token of a portal invitation until it is sent
+	Status        string             `bson:"status" json:"status"`           // "pending", "sending", "sent", "failed"
+	Attempts      int                `bson:"attempts" json:"attempts"`
+	NextAttemptAt primitive.DateTime `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LockedUntil   primitive.DateTime `bson:"lockedUntil,omitempty" json:"-"`
+	LastError     string             `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	DedupKey      string             `bson:"dedupKey,omitempty" json:"-"` // Prevents the same trigger from mailing twice
+	CreatedAt     primitive.DateTime `bson:"createdAt" json:"createdAt"`
+	SentAt        primitive.DateTime `bson:"sentAt,omitempty" json:"sentAt,omitempty"`
+}
+
+// --- Pre-boarding Portal ---
+
+// PortalLink is a magic link that lets a new hire sign in to the pre-boarding portal
+// without a user account. Only a hash of the link's token is stored.
+type PortalLink struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID   string             `bson:"tenantId" json:"tenantId"`
+	EmployeeID primitive.ObjectID `bson:"employeeId" json:"employeeId"`
+	TokenHash  string             `bson:"tokenHash" json:"-"` // SHA-256 of the token in the link
+	CreatedBy  string             `bson:"createdBy" json:"createdBy"`
+	CreatedAt  primitive.DateTime `bson:"createdAt" json:"createdAt"`
+	ExpiresAt  primitive.DateTime `bson:"expiresAt" json:"expiresAt"`
+	LastUsedAt primitive.DateTime `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+	RevokedAt  primitive.DateTime `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+}
+
+// --- Documents ---
+
+// DocumentTemplate is a document a tenant requires from every new hire, such as a signed
+// contract or a tax form. A requested EmployeeDocument is created from every active
+// template when an employee is created.
+type DocumentTemplate struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID     string             `bson:"tenantId" json:"tenantId"`
+	Name         string             `bson:"name" json:"name"` // e.g., "Employment contract"
+	Description  string             `bson:"description,omitempty" json:"description,omitempty"`
+	ContentTypes []string           `bson:"contentTypes,omitempty" json:"contentTypes,omitempty"` // Accepted types; empty accepts every supported type
+	MaxSize      int64              `bson:"maxSize,omitempty" json:"maxSize,omitempty"`           // In bytes; 0 uses the global limit
+	// RetentionDays deletes the file this many days after the employee is offboarded, even
+	// once the employee is purged from the trash. With 0 it is kept as long as the employee exists.
+	RetentionDays int  `bson:"retentionDays,omitempty" json:"retentionDays,omitempty"`
+	Active        bool `bson:"active" json:"active"`
+}
+
+// EmployeeDocument is a document collected from an employee. It starts as "requested"
+// (from a template) or "submitted" (uploaded without one) and is then reviewed.
+type EmployeeDocument struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID        string             `bson:"tenantId" json:"tenantId"`
+	EmployeeID      primitive.ObjectID `bson:"employeeId" json:"employeeId"`
+	TemplateID      primitive.ObjectID `bson:"templateId,omitempty" json:"templateId,omitempty"`
+	Name            string             `bson:"name" json:"name"`
+	Status          string             `bson:"status" json:"status"` // "requested", "submitted", "approved", "rejected"
+	RejectionReason string             `bson:"rejectionReason,omitempty" json:"rejectionReason,omitempty"`
+	FileName        string             `bson:"fileName,omitempty" json:"fileName,omitempty"`
+	ContentType     string             `bson:"contentType,omitempty" json:"contentType,omitempty"`
+	Size            int64              `bson:"size,omitempty" json:"size,omitempty"`
+	StorageKey      string             `bson:"storageKey,omitempty" json:"-"`                    // Key of the file in the blob store
+	UploadedBy      string             `bson:"uploadedBy,omitempty" json:"uploadedBy,omitempty"` // A user ID, or "portal" for the new hire
+	UploadedAt      primitive.DateTime `bson:"uploadedAt,omitempty" json:"uploadedAt,omitempty"`
+	ReviewedBy      string             `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ReviewedAt      primitive.DateTime `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	RetentionDays   int                `bson:"retentionDays,omitempty" json:"retentionDays,omitempty"` // From the template, when the file was uploaded
+	RetainUntil     primitive.DateTime `bson:"retainUntil,omitempty" json:"retainUntil,omitempty"`     // When the file is deleted; set when the employee is offboarded
+	CreatedAt       primitive.DateTime `bson:"createdAt" json:"createdAt"`
+}
+
+// --- Hardware Inventory ---
+
+// AssetUnit is one physical device of a hardware model, identified by its serial number.
+type AssetUnit struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID     string             `bson:"tenantId" json:"tenantId"`
+	ModelID      primitive.ObjectID `bson:"modelId" json:"modelId"` // The HardwareAsset this unit is an instance of
+	SerialNumber string             `bson:"serialNumber" json:"serialNumber"`
+	PurchaseDate primitive.DateTime `bson:"purchaseDate,omitempty" json:"purchaseDate,omitempty"`
+	Condition    string             `bson:"condition" json:"condition"`                       // "new", "good", "fair", "poor", "broken"
+	Status       string             `bson:"status" json:"status"`                             // "in_stock", "assigned", "in_repair", "retired"
+	AssignedTo   primitive.ObjectID `bson:"assignedTo,omitempty" json:"assignedTo,omitempty"` // Employee holding the unit while assigned
+	AssignedAt   primitive.DateTime `bson:"assignedAt,omitempty" json:"assignedAt,omitempty"`
+	Notes        string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedAt    primitive.DateTime `bson:"createdAt" json:"createdAt"`
+}
+
+// AssetAssignment records one hand-out of a unit to an employee. ReturnedAt is unset while
+// the employee still has the unit.
+type AssetAssignment struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID        string             `bson:"tenantId" json:"tenantId"`
+	UnitID          primitive.ObjectID `bson:"unitId" json:"unitId"`
+	ModelID         primitive.ObjectID `bson:"modelId" json:"modelId"`
+	SerialNumber    string             `bson:"serialNumber" json:"serialNumber"` // Copied so the history reads well after a unit is deleted
+	EmployeeID      primitive.ObjectID `bson:"employeeId" json:"employeeId"`
+	AssignedBy      string             `bson:"assignedBy" json:"assignedBy"`
+	AssignedAt      primitive.DateTime `bson:"assignedAt" json:"assignedAt"`
+	AssignCondition string             `bson:"assignCondition" json:"assignCondition"`
+	ReturnedBy      string             `bson:"returnedBy,omitempty" json:"returnedBy,omitempty"`
+	ReturnedAt      primitive.DateTime `bson:"returnedAt,omitempty" json:"returnedAt,omitempty"`
+	ReturnCondition string             `bson:"returnCondition,omitempty" json:"returnCondition,omitempty"`
+	Notes           string             `bson:"notes,omitempty" json:"notes,omitempty"`
+}
+
+// --- Buddies ---
+
+// BuddyAssignment records a buddy looking after a new hire. It is open until EndsAt, or
+// until EndedAt if the new hire gets another buddy or leaves earlier.
+type BuddyAssignment struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID   string             `bson:"tenantId" json:"tenantId"`
+	BuddyID    primitive.ObjectID `bson:"buddyId" json:"buddyId"`
+	EmployeeID primitive.ObjectID `bson:"employeeId" json:"employeeId"` // The new hire
+	AssignedBy string             `bson:"assignedBy,omitempty" json:"assignedBy,omitempty"`
+	AssignedAt primitive.DateTime `bson:"assignedAt" json:"assignedAt"`
+	EndsAt     primitive.DateTime `bson:"endsAt" json:"endsAt"`
+	EndedAt    primitive.DateTime `bson:"endedAt,omitempty" json:"endedAt,omitempty"`
+	Feedback   []BuddyFeedback    `bson:"feedback,omitempty" json:"feedback,omitempty"`
+}
+
+// BuddyFeedback is a rating of how a buddy assignment went.
+type BuddyFeedback struct {
+	From    string             `bson:"from" json:"from"`     // "new_hire" or "admin"
+	Rating  int                `bson:"rating" json:"rating"` // 1 to 5
+	Comment string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	GivenBy string             `bson:"givenBy" json:"givenBy"` // A user ID, or "portal" for the new hire
+	GivenAt primitive.DateTime `bson:"givenAt" json:"givenAt"`
+}
+
+// --- Access Requests ---
+
+// AccessRequest asks for an access level to be granted to an employee, or for a granted
+// one to be revoked. It passes its approval steps in order, and is then carried out by
+// whoever provisions the access, who marks it granted or revoked.
+type AccessRequest struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID      string             `bson:"tenantId" json:"tenantId"`
+	Kind          string             `bson:"kind" json:"kind"` // "grant" or "revoke"
+	EmployeeID    primitive.ObjectID `bson:"employeeId" json:"employeeId"`
+	EmployeeName  string             `bson:"employeeName" json:"employeeName"` // Kept for requests that outlive the employee
+	AccessLevelID primitive.ObjectID `bson:"accessLevelId" json:"accessLevelId"`
+	GrantID       primitive.ObjectID `bson:"grantId,omitempty" json:"grantId,omitempty"` // For revocations, the grant being revoked
+	Justification string             `bson:"justification" json:"justification"`
+	Status        string             `bson:"status" json:"status"`
+	Steps         []string           `bson:"steps" json:"steps"` // Approval steps, in order, e.g. "manager", "security"
+	Approvals     []Approval         `bson:"approvals" json:"approvals"`
+	RequestedBy   string             `bson:"requestedBy" json:"requestedBy"` // A user ID, or "system" for automatic revocations
+	RequestedAt   primitive.DateTime `bson:"requestedAt" json:"requestedAt"`
+	GrantedBy     string             `bson:"grantedBy,omitempty" json:"grantedBy,omitempty"`
+	GrantedAt     primitive.DateTime `bson:"grantedAt,omitempty" json:"grantedAt,omitempty"`
+	RevokedBy     string             `bson:"revokedBy,omitempty" json:"revokedBy,omitempty"`
+	RevokedAt     primitive.DateTime `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+}
+
+// Approval is the decision taken at one approval step of an access or change request.
+type Approval struct {
+	Step      string             `bson:"step" json:"step"`
+	Decision  string             `bson:"decision" json:"decision"` // "approved" or "rejected"
+	DecidedBy string             `bson:"decidedBy" json:"decidedBy"`
+	Comment   string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	DecidedAt primitive.DateTime `bson:"decidedAt" json:"decidedAt"`
+}
+
+// --- Change Approvals ---
+
+// ApprovalPolicy makes changes to some fields of an entity type wait for sign-off. An
+// update that changes one of the fields becomes a ChangeRequest instead of being applied.
+type ApprovalPolicy struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID   string             `bson:"tenantId" json:"tenantId"`
+	Name       string             `bson:"name" json:"name"`
+	EntityType string             `bson:"entityType" json:"entityType"` // "employees" or an entity slug, e.g. "costs"
+	Fields     []string           `bson:"fields" json:"fields"`         // Fields that need approval; empty means any field
+	Steps      []string           `bson:"steps" json:"steps"`           // Roles that approve, in order, e.g. "manager", "security"
+	CreatedAt  primitive.DateTime `bson:"createdAt" json:"createdAt"`
+}
+
+// ChangeRequest is an update held back by one or more approval policies. It stores the
+// diff against the entity, and the diff is applied once every step approved it.
+type ChangeRequest struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	TenantID    string                 `bson:"tenantId" json:"tenantId"`
+	EntityType  string                 `bson:"entityType" json:"entityType"`
+	EntityID    primitive.ObjectID     `bson:"entityId" json:"entityId"`
+	PolicyIDs   []primitive.ObjectID   `bson:"policyIds" json:"policyIds"`
+	Changes     map[string]interface{} `bson:"changes" json:"changes"`   // Field -> requested value, as submitted
+	Previous    map[string]interface{} `bson:"previous" json:"previous"` // Field -> value when the change was requested
+	Status      string                 `bson:"status" json:"status"`     // "pending", "rejected", "cancelled", "applied" or "failed"
+	Steps       []string               `bson:"steps" json:"steps"`
+	Approvals   []Approval             `bson:"approvals" json:"approvals"`
+	RequestedBy string                 `bson:"requestedBy" json:"requestedBy"` // A user ID, "portal" for the new hire, or "system"
+	RequestedAt primitive.DateTime     `bson:"requestedAt" json:"requestedAt"`
+	AppliedAt   primitive.DateTime     `bson:"appliedAt,omitempty" json:"appliedAt,omitempty"`
+	Error       string                 `bson:"error,omitempty" json:"error,omitempty"` // Why applying the change failed
+}
+
+// --- Record Versions ---
+
+// RecordVersion is the state of an employee or entity after one of its changes. Together
+// the versions of a record form its history, which answers what it looked like at any time.
+type RecordVersion struct {
+	ID         primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	TenantID   string                 `bson:"tenantId" json:"tenantId"`
+	EntityType string                 `bson:"entityType" json:"entityType"` // "employees" or an entity slug
+	RecordID   primitive.ObjectID     `bson:"recordId" json:"recordId"`
+	Version    int                    `bson:"version" json:"version"`     // Counts up from 1 per record
+	Operation  string                 `bson:"operation" json:"operation"` // "baseline", "created", "updated", "deleted" or "restored"
+	Data       map[string]interface{} `bson:"data" json:"data"`           // The whole record after the change
+	ChangedAt  primitive.DateTime     `bson:"changedAt" json:"changedAt"`
+}
+
+// --- Scheduled Changes ---
+
+// ScheduledChange is an update of an employee that takes effect at a later date, such as a
+// move to another team next month. It stays pending until a background job applies it on
+// its effective date.
+type ScheduledChange struct {
+	ID              primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	TenantID        string                 `bson:"tenantId" json:"tenantId"`
+	EmployeeID      primitive.ObjectID     `bson:"employeeId" json:"employeeId"`
+	Changes         map[string]interface{} `bson:"changes" json:"changes"`             // Field -> new value, as submitted; onboardingDate as a date
+	EffectiveDate   primitive.DateTime     `bson:"effectiveDate" json:"effectiveDate"` // Start of the day (UTC) the change takes effect
+	Comment         string                 `bson:"comment,omitempty" json:"comment,omitempty"`
+	Status          string                 `bson:"status" json:"status"`                                       // "pending", "applying", "held", "applied", "cancelled" or "failed"
+	ChangeRequestID primitive.ObjectID     `bson:"changeRequestId,omitempty" json:"changeRequestId,omitempty"` // The change request of a held change
+	CreatedBy       string                 `bson:"createdBy" json:"createdBy"`
+	CreatedAt       primitive.DateTime     `bson:"createdAt" json:"createdAt"`
+	AppliedAt       primitive.DateTime     `bson:"appliedAt,omitempty" json:"appliedAt,omitempty"`
+	CancelledBy     string                 `bson:"cancelledBy,omitempty" json:"cancelledBy,omitempty"`
+	CancelledAt     primitive.DateTime     `bson:"cancelledAt,omitempty" json:"cancelledAt,omitempty"`
+	Error           string                 `bson:"error,omitempty" json:"error,omitempty"` // Why applying the change failed
+}
+
+// --- Saved Views ---
+
+// SavedView is a named filter expression for a list endpoint. A view belongs to the user
+// who saved it; shared views are visible to everyone in the tenant.
+type SavedView struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID   string             `bson:"tenantId" json:"tenantId"`
+	Name       string             `bson:"name" json:"name"`             // e.g. "Engineers in Berlin starting next month"
+	EntityType string             `bson:"entityType" json:"entityType"` // "employees" or an entity slug
+	Filter     string             `bson:"filter" json:"filter"`         // e.g. `jobRole = "Engineer" AND onboardingDate < now+1m`
+	Shared     bool               `bson:"shared" json:"shared"`
+	OwnerID    string             `bson:"ownerId" json:"ownerId"`
+	CreatedAt  primitive.DateTime `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  primitive.DateTime `bson:"updatedAt" json:"updatedAt"`
+}
+
+// --- Scheduler ---
+
+// ScheduledJob is a recurring (cron) or one-off job run by the scheduler.
+type ScheduledJob struct {
+	ID          string                 `bson:"_id" json:"id"`                                // Job name for recurring jobs, a generated ID for one-off jobs
+	Handler     string                 `bson:"handler" json:"handler"`                       // Name of the registered handler that runs the job
+	Schedule    string                 `bson:"schedule,omitempty" json:"schedule,omitempty"` // Cron expression; empty for one-off jobs
+	Payload     map[string]interface{} `bson:"payload,omitempty" json:"payload,omitempty"`   // Handler input for one-off jobs
+	Paused      bool                   `bson:"paused" json:"paused"`
+	Done        bool                   `bson:"done" json:"done"` // One-off jobs that have run
+	NextRunAt   primitive.DateTime     `bson:"nextRunAt" json:"nextRunAt"`
+	LastRunAt   primitive.DateTime     `bson:"lastRunAt,omitempty" json:"lastRunAt,omitempty"`
+	LastStatus  string                 `bson:"lastStatus,omitempty" json:"lastStatus,omitempty"` // "succeeded" or "failed"
+	LastError   string                 `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	TriggeredBy string                 `bson:"triggeredBy,omitempty" json:"-"` // User who requested a manual run
+	LockedBy    string                 `bson:"lockedBy,omitempty" json:"lockedBy,omitempty"`
+	LockedUntil primitive.DateTime     `bson:"lockedUntil,omitempty" json:"lockedUntil,omitempty"`
+	CreatedAt   primitive.DateTime     `bson:"createdAt" json:"createdAt"`
+}
+
+// JobRun records one execution of a scheduled job.
+type JobRun struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	JobID       string             `bson:"jobId" json:"jobId"`
+	Handler     string             `bson:"handler" json:"handler"`
+	Trigger     string             `bson:"trigger" json:"trigger"` // "schedule" or "manual"
+	TriggeredBy string             `bson:"triggeredBy,omitempty" json:"triggeredBy,omitempty"`
+	Instance    string             `bson:"instance" json:"instance"` // Server replica that ran the job
+	Status      string             `bson:"status" json:"status"`     // "running", "succeeded", "failed"
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt   primitive.DateTime `bson:"startedAt" json:"startedAt"`
+	FinishedAt  primitive.DateTime `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
 }
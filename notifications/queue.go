@@ -0,0 +1,345 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"github.com/your-username/onboarding/scheduler"
+	"github.com/your-username/onboarding/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// maxSendAttempts is the number of attempts before an email is marked as failed.
+	maxSendAttempts = 6
+	// sendRetryBase is the delay before the first retry; it doubles after every failure.
+	sendRetryBase = time.Minute
+	// sendRetryMax caps the delay between two attempts.
+	sendRetryMax = 6 * time.Hour
+	// sendLockDuration is how long a worker may hold an email before another may retry it.
+	sendLockDuration = 2 * time.Minute
+	// queuePollInterval is how often the queue is checked for due emails.
+	queuePollInterval = 5 * time.Second
+	// portalTokenPlaceholder stands in for the magic link token in stored invitations.
+	// The token is only put into the email when it is sent.
+	portalTokenPlaceholder = "REDACTED"
+)
+
+// sender delivers the queued emails. It is set by Start.
+var sender Sender
+
+// RegisterSubscribers subscribes the notification triggers to the event bus.
+// It is called once at startup, before events.Start.
+func RegisterSubscribers() {
+	events.Subscribe("notifications", events.Async, onEmployeeCreated, events.TypeEmployeeCreated)
+}
+
+// RegisterJobs registers the reminder job with the scheduler.
+// It is called once at startup, before scheduler.Start.
+func RegisterJobs() {
+	scheduler.Recurring("onboarding-reminders", "0 * * * *", func(ctx context.Context, job *models.ScheduledJob) error {
+		return queueReminders(ctx, time.Now())
+	})
+}
+
+// Start creates the queue's indexes and runs the delivery worker until ctx is cancelled.
+// With a nil sender, emails are queued but not delivered.
+func Start(ctx context.Context, s Sender) error {
+	sender = s
+	_, err := db.GetCollection("email_messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "dedupKey", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"dedupKey": bson.M{"$type": "string"}}),
+	})
+	if err != nil {
+		return err
+	}
+	if sender == nil {
+		log.Println("Email delivery is disabled, set MAIL_SINK or SMTP_HOST to send the queued emails")
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(queuePollInterval)
+		defer ticker.Stop()
+		for {
+			for sendNext(ctx) {
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// --- Triggers ---
+
+// onEmployeeCreated queues the "employee_created" emails for a new employee.
+func onEmployeeCreated(ctx context.Context, env events.Envelope) error {
+	e, ok := env.Event.(events.EmployeeCreated)
+	if !ok {
+		return nil
+	}
+	return queueForEmployee(ctx, TriggerEmployeeCreated, 0, e.Employee, "", "", nil)
+}
+
+// QueuePortalInvite queues the "portal_invite" emails with a new hire's magic link. The
+// stored email shows a placeholder instead of the token; the token is kept apart from it
+// until the email is sent, and is never returned by GetMessages.
+func QueuePortalInvite(ctx context.Context, employee models.Employee, link *models.PortalLink, token string) error {
+	return queueForEmployee(ctx, TriggerPortalInvite, 0, employee, link.ID.Hex(), token, func(data *TemplateData) {
+		data.PortalURL = services.PortalURL(portalTokenPlaceholder)
+		data.PortalLinkExpiresAt = link.ExpiresAt.Time().UTC()
+	})
+}
+
+// withPortalToken puts the magic link token of an invitation into its text.
+func withPortalToken(text, token string) string {
+	if token == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, "token="+portalTokenPlaceholder, "token="+url.QueryEscape(token))
+}
+
+// queueReminders queues the "onboarding_reminder" emails for every employee whose
+// onboarding date is the configured number of days after now. The scheduler runs it every
+// hour; the dedup key makes sure each reminder is queued only once.
+func queueReminders(ctx context.Context, now time.Time) error {
+	// Collect the distinct reminder offsets in use.
+	offsets := map[int]bool{}
+	for _, tmpl := range DefaultTemplates {
+		if tmpl.Trigger == TriggerOnboardingReminder {
+			offsets[tmpl.DaysBefore] = true
+		}
+	}
+	values, err := db.GetCollection("notification_templates").Distinct(ctx, "daysBefore",
+		bson.M{"trigger": TriggerOnboardingReminder, "active": true})
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if days, ok := v.(int32); ok {
+			offsets[int(days)] = true
+		} else if days, ok := v.(int64); ok {
+			offsets[int(days)] = true
+		}
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	for days := range offsets {
+		start := today.AddDate(0, 0, days)
+		// Deleted employees wait in the trash and get no reminders.
+		filter := bson.M{
+			"onboardingDate": bson.M{
+				"$gte": primitive.NewDateTimeFromTime(start),
+				"$lt":  primitive.NewDateTimeFromTime(start.AddDate(0, 0, 1)),
+			},
+			"deletedAt": bson.M{"$exists": false},
+		}
+		cursor, err := db.GetCollection("employees").Find(ctx, filter)
+		if err != nil {
+			return err
+		}
+		var employees []models.Employee
+		if err := cursor.All(ctx, &employees); err != nil {
+			return err
+		}
+		for _, employee := range employees {
+			dedup := start.Format("2006-01-02")
+			if err := queueForEmployee(ctx, TriggerOnboardingReminder, days, employee, dedup, "", nil); err != nil {
+				log.Printf("Could not queue reminder for employee %s: %v", employee.ID.Hex(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// queueForEmployee renders the tenant's templates for a trigger and queues one email per
+// template. For reminders, only templates with the given daysBefore are used. The dedup
+// suffix distinguishes repeated triggers for the same employee (e.g. a moved start date).
+// portalToken is the magic link token of an invitation, or "".
+// prepare, if not nil, adds trigger-specific variables to the template data.
+func queueForEmployee(ctx context.Context, trigger string, daysBefore int, employee models.Employee, dedupSuffix, portalToken string, prepare func(*TemplateData)) error {
+	templates, err := templatesFor(ctx, employee.TenantID, trigger)
+	if err != nil {
+		return err
+	}
+
+	var data *TemplateData
+	for _, tmpl := range templates {
+		if trigger == TriggerOnboardingReminder && tmpl.DaysBefore != daysBefore {
+			continue
+		}
+		if data == nil {
+			if data, err = loadTemplateData(ctx, employee); err != nil {
+				return err
+			}
+			if prepare != nil {
+				prepare(data)
+			}
+		}
+
+		to := resolveRecipients(tmpl.Recipients, data)
+		if len(to) == 0 {
+			continue
+		}
+		subject, body, err := Render(&tmpl, data)
+		if err != nil {
+			log.Printf("Template %q of tenant %s failed to render: %v", tmpl.Name, employee.TenantID, err)
+			continue
+		}
+
+		templateKey := "default:" + tmpl.Name
+		if !tmpl.ID.IsZero() {
+			templateKey = tmpl.ID.Hex()
+		}
+		now := primitive.NewDateTimeFromTime(time.Now())
+		message := models.EmailMessage{
+			ID:            primitive.NewObjectID(),
+			TenantID:      employee.TenantID,
+			Trigger:       trigger,
+			TemplateName:  tmpl.Name,
+			EmployeeID:    employee.ID,
+			To:            to,
+			Subject:       subject,
+			Body:          body,
+			PortalToken:   portalToken,
+			Status:        "pending",
+			NextAttemptAt: now,
+			DedupKey:      fmt.Sprintf("%s:%s:%s:%s", trigger, templateKey, employee.ID.Hex(), dedupSuffix),
+			CreatedAt:     now,
+		}
+		_, err = db.GetCollection("email_messages").InsertOne(ctx, message)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- Delivery ---
+
+// sendNext claims and sends one due email. It reports whether it found one.
+func sendNext(ctx context.Context) bool {
+	messages := db.GetCollection("email_messages")
+	now := time.Now()
+
+	filter := bson.M{"$or": []bson.M{
+		{"status": "pending", "nextAttemptAt": bson.M{"$lte": primitive.NewDateTimeFromTime(now)}},
+		{"status": "sending", "lockedUntil": bson.M{"$lte": primitive.NewDateTimeFromTime(now)}},
+	}}
+	claim := bson.M{"$set": bson.M{
+		"status":      "sending",
+		"lockedUntil": primitive.NewDateTimeFromTime(now.Add(sendLockDuration)),
+	}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var message models.EmailMessage
+	if err := messages.FindOneAndUpdate(ctx, filter, claim, opts).Decode(&message); err != nil {
+		if err != mongo.ErrNoDocuments && ctx.Err() == nil {
+			log.Printf("Email queue could not claim a message: %v", err)
+		}
+		return false
+	}
+
+	sendErr := sender.Send(ctx, Email{
+		ID:      message.ID.Hex(),
+		From:    config.AppConfig.MailFrom,
+		To:      message.To,
+		Subject: message.Subject,
+		Body:    withPortalToken(message.Body, message.PortalToken),
+	})
+	attempts := message.Attempts + 1
+	update := bson.M{"attempts": attempts}
+	// The magic link token is dropped once the email is sent or given up on.
+	unset := bson.M{}
+	switch {
+	case sendErr == nil:
+		update["status"] = "sent"
+		update["lastError"] = ""
+		update["sentAt"] = primitive.NewDateTimeFromTime(time.Now())
+		unset["portalToken"] = ""
+	case attempts >= maxSendAttempts:
+		update["status"] = "failed"
+		update["lastError"] = sendErr.Error()
+		unset["portalToken"] = ""
+	default:
+		delay := sendRetryBase << (attempts - 1)
+		if delay > sendRetryMax {
+			delay = sendRetryMax
+		}
+		update["status"] = "pending"
+		update["lastError"] = sendErr.Error()
+		update["nextAttemptAt"] = primitive.NewDateTimeFromTime(time.Now().Add(delay))
+	}
+	change := bson.M{"$set": update}
+	if len(unset) > 0 {
+		change["$unset"] = unset
+	}
+	messages.UpdateByID(ctx, message.ID, change)
+	return true
+}
+
+// GetMessages lists the tenant's queued and sent emails, newest first, optionally filtered by status.
+func GetMessages(ctx context.Context, tenantID, status string, page services.Pagination) ([]models.EmailMessage, error) {
+	filter := bson.M{"tenantId": tenantID}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if page.Limit > 0 {
+		opts.SetLimit(page.Limit)
+	}
+	if page.Offset > 0 {
+		opts.SetSkip(page.Offset)
+	}
+	cursor, err := db.GetCollection("email_messages").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	messages := []models.EmailMessage{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// RetryMessage queues a failed email again with a fresh retry budget. Failed portal
+// invitations no longer hold their token, so a new portal link has to be created instead.
+func RetryMessage(ctx context.Context, id, tenantID string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid id format")
+	}
+	filter := bson.M{"_id": objID, "tenantId": tenantID, "status": "failed", "trigger": bson.M{"$ne": TriggerPortalInvite}}
+	update := bson.M{"$set": bson.M{
+		"status":        "pending",
+		"attempts":      0,
+		"nextAttemptAt": primitive.NewDateTimeFromTime(time.Now()),
+	}}
+	result, err := db.GetCollection("email_messages").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("failed message not found or does not belong to this tenant; failed portal invitations are not retried, create a new portal link instead")
+	}
+	return nil
+}
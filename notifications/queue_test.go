@@ -0,0 +1,62 @@
+package notifications
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/models"
+	"github.com/your-username/onboarding/services"
+)
+
+func TestPortalInviteTokenIsOnlyAddedWhenSending(t *testing.T) {
+	previous := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = previous })
+	config.AppConfig.PortalURL = "https://portal.example.com/start"
+
+	var invite *models.NotificationTemplate
+	for i := range DefaultTemplates {
+		if DefaultTemplates[i].Trigger == TriggerPortalInvite {
+			invite = &DefaultTemplates[i]
+		}
+	}
+	if invite == nil {
+		t.Fatal("no default portal invitation template")
+	}
+
+	const token = "s3cr3t-T0ken_abc"
+	data := &TemplateData{
+		Employee:            models.Employee{FirstName: "Ada"},
+		PortalURL:           services.PortalURL(portalTokenPlaceholder),
+		PortalLinkExpiresAt: time.Now(),
+	}
+	_, body, err := Render(invite, data)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	message := models.EmailMessage{Body: body, PortalToken: token}
+
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(encoded), token) {
+		t.Fatalf("the stored message exposes the token: %s", encoded)
+	}
+	if !strings.Contains(message.Body, services.PortalURL(portalTokenPlaceholder)) {
+		t.Fatalf("expected the placeholder link in the stored body, got:\n%s", message.Body)
+	}
+
+	sent := withPortalToken(message.Body, message.PortalToken)
+	if !strings.Contains(sent, services.PortalURL(token)) {
+		t.Fatalf("expected the magic link in the sent body, got:\n%s", sent)
+	}
+	if strings.Contains(sent, portalTokenPlaceholder) {
+		t.Fatalf("the sent body still holds the placeholder:\n%s", sent)
+	}
+	if withPortalToken("no link here", "") != "no link here" {
+		t.Fatal("expected other emails to be sent unchanged")
+	}
+}
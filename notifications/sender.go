@@ -0,0 +1,147 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-username/onboarding/config"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Email is a message ready to be sent.
+type Email struct {
+	ID      string // Used for the Message-ID header
+	From    string
+	To      []string
+	Subject string
+	Body    string // Plain text
+}
+
+// Sender delivers emails.
+type Sender interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// NewSender returns the sender configured by MAIL_SINK. Without MAIL_SINK, SMTP is used if
+// SMTP_HOST is set; otherwise delivery is disabled and NewSender returns nil, so emails stay
+// queued until a sink is configured. The capture sink is only used when asked for.
+func NewSender() (Sender, error) {
+	sink := config.AppConfig.MailSink
+	if sink == "" {
+		if config.AppConfig.SMTPHost == "" {
+			return nil, nil
+		}
+		sink = "smtp"
+	}
+
+	switch sink {
+	case "smtp":
+		if config.AppConfig.SMTPHost == "" {
+			return nil, fmt.Errorf("MAIL_SINK=smtp requires SMTP_HOST")
+		}
+		return &SMTPSender{
+			Addr:     net.JoinHostPort(config.AppConfig.SMTPHost, config.AppConfig.SMTPPort),
+			Host:     config.AppConfig.SMTPHost,
+			Username: config.AppConfig.SMTPUsername,
+			Password: config.AppConfig.SMTPPassword,
+		}, nil
+	case "capture":
+		return Capture, nil
+	}
+	return nil, fmt.Errorf("unknown MAIL_SINK %q, use \"smtp\" or \"capture\"", sink)
+}
+
+// --- SMTP ---
+
+// SMTPSender delivers emails to an SMTP server. STARTTLS is used when the server offers it.
+type SMTPSender struct {
+	Addr     string // host:port
+	Host     string
+	Username string // Authentication is skipped when empty
+	Password string
+}
+
+func (s *SMTPSender) Send(ctx context.Context, email Email) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	// net/smtp has no context support; run it in the background and give up when ctx ends.
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(s.Addr, auth, email.From, email.To, formatEmail(email)) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// formatEmail renders the email as an RFC 5322 message.
+func formatEmail(email Email) []byte {
+	var buf bytes.Buffer
+	header := func(key, value string) { fmt.Fprintf(&buf, "%s: %s\r\n", key, value) }
+	header("From", email.From)
+	header("To", strings.Join(email.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	if email.ID != "" {
+		header("Message-ID", fmt.Sprintf("<%s@onboarding>", email.ID))
+	}
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+// --- Capture ---
+
+// maxCapturedEmails bounds the memory used by the capture sink.
+const maxCapturedEmails = 1000
+
+// CaptureSender keeps sent emails in memory instead of delivering them. It is meant
+// for development and tests, which can inspect Capture.Messages().
+type CaptureSender struct {
+	mu       sync.Mutex
+	messages []Email
+}
+
+// Capture is the sink used when MAIL_SINK=capture.
+var Capture = &CaptureSender{}
+
+func (s *CaptureSender) Send(ctx context.Context, email Email) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if email.ID == "" {
+		email.ID = primitive.NewObjectID().Hex()
+	}
+	s.messages = append(s.messages, email)
+	if len(s.messages) > maxCapturedEmails {
+		s.messages = s.messages[len(s.messages)-maxCapturedEmails:]
+	}
+	return nil
+}
+
+// Messages returns the captured emails, oldest first.
+func (s *CaptureSender) Messages() []Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Email(nil), s.messages...)
+}
+
+// Reset forgets all captured emails.
+func (s *CaptureSender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
@@ -0,0 +1,85 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+
+	"github.com/your-username/onboarding/config"
+)
+
+func withMailConfig(t *testing.T, sink, smtpHost string) {
+	t.Helper()
+	previous := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = previous })
+	config.AppConfig.MailSink = sink
+	config.AppConfig.SMTPHost = smtpHost
+	config.AppConfig.SMTPPort = "587"
+}
+
+func TestNewSenderIsDisabledWithoutConfiguration(t *testing.T) {
+	withMailConfig(t, "", "")
+	sender, err := NewSender()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sender != nil {
+		t.Fatalf("expected delivery to be disabled, got %T", sender)
+	}
+}
+
+func TestNewSenderUsesSMTPHost(t *testing.T) {
+	withMailConfig(t, "", "mail.example.com")
+	sender, err := NewSender()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	smtpSender, ok := sender.(*SMTPSender)
+	if !ok {
+		t.Fatalf("expected an SMTP sender, got %T", sender)
+	}
+	if smtpSender.Addr != "mail.example.com:587" {
+		t.Fatalf("unexpected address %q", smtpSender.Addr)
+	}
+}
+
+func TestNewSenderRejectsInvalidConfiguration(t *testing.T) {
+	for _, tc := range []struct{ sink, host string }{{"smtp", ""}, {"log", ""}} {
+		withMailConfig(t, tc.sink, tc.host)
+		if _, err := NewSender(); err == nil {
+			t.Errorf("MAIL_SINK=%q SMTP_HOST=%q: expected an error", tc.sink, tc.host)
+		}
+	}
+}
+
+func TestCaptureSink(t *testing.T) {
+	withMailConfig(t, "capture", "")
+	sender, err := NewSender()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sender != Capture {
+		t.Fatalf("expected the capture sink, got %T", sender)
+	}
+	Capture.Reset()
+	t.Cleanup(Capture.Reset)
+
+	email := Email{From: "onboarding@example.com", To: []string{"ada@example.com"}, Subject: "Welcome", Body: "Hello Ada"}
+	if err := sender.Send(context.Background(), email); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	messages := Capture.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected one captured email, got %d", len(messages))
+	}
+	if messages[0].Subject != "Welcome" || messages[0].To[0] != "ada@example.com" {
+		t.Fatalf("unexpected email %+v", messages[0])
+	}
+	if messages[0].ID == "" {
+		t.Fatal("expected the captured email to get an ID")
+	}
+
+	Capture.Reset()
+	if len(Capture.Messages()) != 0 {
+		t.Fatal("expected Reset to forget the captured emails")
+	}
+}
@@ -0,0 +1,375 @@
+// Package notifications sends emails about new hires to their manager, buddy and anyone
+// else a tenant configures. Emails are rendered from per-tenant templates, written to a
+// queue in MongoDB and delivered in the background with retries.
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"github.com/your-username/onboarding/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Triggers that send notifications.
+const (
+	TriggerEmployeeCreated    = "employee_created"    // Right after an employee is created
+	TriggerOnboardingReminder = "onboarding_reminder" // DaysBefore days before the onboarding date
+	TriggerPortalInvite       = "portal_invite"       // When an admin sends a new hire their pre-boarding portal link
+)
+
+// Triggers lists the valid template triggers.
+var Triggers = []string{TriggerEmployeeCreated, TriggerOnboardingReminder, TriggerPortalInvite}
+
+// Recipients that are resolved per employee. Any other recipient must be an email address.
+const (
+	RecipientEmployee = "employee"
+	RecipientManager  = "manager"
+	RecipientBuddy    = "buddy"
+)
+
+// DefaultTemplates are used for every trigger for which a tenant has no template of its own.
+// A tenant switches a default off by creating an inactive template for the same trigger.
+var DefaultTemplates = []models.NotificationTemplate{
+	{
+		Name:       "New hire announcement",
+		Trigger:    TriggerEmployeeCreated,
+		Recipients: []string{RecipientManager, RecipientBuddy},
+		Subject:    "{{.Employee.FirstName}} {{.Employee.LastName}} joins on {{date .OnboardingDate}}",
+		Body: `Hello,
+
+{{.Employee.FirstName}} {{.Employee.LastName}} will join {{.Tenant.Name}} on {{date .OnboardingDate}}{{with .Department}} in {{.Name}}{{end}}{{with .Location}} at {{.Name}}{{end}}.
+{{with .Manager}}
+Manager: {{.Name}}{{end}}{{with .Buddy}}
+Onboarding buddy: {{.Name}}{{end}}
+
+Please make sure everything is ready for their first day.
+`,
+		Active: true,
+	},
+	{
+		Name:       "Onboarding reminder",
+		Trigger:    TriggerOnboardingReminder,
+		DaysBefore: 3,
+		Recipients: []string{RecipientManager, RecipientBuddy},
+		Subject:    "Reminder: {{.Employee.FirstName}} {{.Employee.LastName}} starts in {{.DaysUntilStart}} days",
+		Body: `Hello,
+
+{{.Employee.FirstName}} {{.Employee.LastName}} starts on {{date .OnboardingDate}}{{with .Location}} at {{.Name}}{{end}}.
+Please check that their equipment and accounts are ready.
+`,
+		Active: true,
+	},
+	{
+		Name:       "Pre-boarding invitation",
+		Trigger:    TriggerPortalInvite,
+		Recipients: []string{RecipientEmployee},
+		Subject:    "Welcome to {{.Tenant.Name}}, {{.Employee.FirstName}}!",
+		Body: `Hello {{.Employee.FirstName}},
+
+we are looking forward to seeing you on {{date .OnboardingDate}}{{with .Location}} at {{.Name}}{{end}}.
+
+Before your first day, please open your pre-boarding page to check your onboarding details,
+add your contact information and upload your documents:
+
+{{.PortalURL}}
+
+The link is personal and valid until {{date .PortalLinkExpiresAt}}.
+`,
+		Active: true,
+	},
+}
+
+// TemplateData holds the variables available in templates. Entity fields are nil when the
+// employee has no such reference, so use {{with .Manager}}...{{end}} to access them.
+type TemplateData struct {
+	Employee       models.Employee
+	Tenant         models.Tenant
+	Manager        *models.Manager // The employee named by reportsToId, or else the managerId entity
+	Buddy          *models.OnboardingBuddy
+	Location       *models.Location
+	Department     *models.Department
+	JobRole        *models.JobRole
+	Team           *models.Team
+	OnboardingDate time.Time
+	DaysUntilStart int
+
+	// Only set for portal invitations.
+	PortalURL           string
+	PortalLinkExpiresAt time.Time
+}
+
+// templateFuncs are the helper functions available in templates.
+var templateFuncs = template.FuncMap{
+	// date formats a time, by default as "Mon, 2 Jan 2006".
+	"date": func(t time.Time, layout ...string) string {
+		if len(layout) > 0 {
+			return t.Format(layout[0])
+		}
+		return t.Format("Mon, 2 Jan 2006")
+	},
+}
+
+// loadTemplateData gathers the employee's tenant and referenced entities.
+func loadTemplateData(ctx context.Context, employee models.Employee) (*TemplateData, error) {
+	data := &TemplateData{
+		Employee:       employee,
+		OnboardingDate: employee.OnboardingDate.Time().UTC(),
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	data.DaysUntilStart = int(data.OnboardingDate.Truncate(24*time.Hour).Sub(today).Hours() / 24)
+
+	tenantID, err := primitive.ObjectIDFromHex(employee.TenantID)
+	if err != nil {
+		return nil, errors.New("invalid tenant id")
+	}
+	if err := db.GetCollection("tenants").FindOne(ctx, bson.M{"_id": tenantID}).Decode(&data.Tenant); err != nil {
+		return nil, fmt.Errorf("loading tenant: %w", err)
+	}
+
+	data.Manager = services.ManagerOf(ctx, &employee)
+	data.Buddy = lookup[models.OnboardingBuddy](ctx, "onboarding_buddies", employee.OnboardingBuddyID, employee.TenantID)
+	data.Location = lookup[models.Location](ctx, "locations", employee.LocationID, employee.TenantID)
+	data.Department = lookup[models.Department](ctx, "departments", employee.DepartmentID, employee.TenantID)
+	data.JobRole = lookup[models.JobRole](ctx, "job_roles", employee.JobRoleID, employee.TenantID)
+	data.Team = lookup[models.Team](ctx, "teams", employee.TeamID, employee.TenantID)
+	return data, nil
+}
+
+// lookup fetches a referenced entity, or returns nil if the reference is unset or dangling.
+func lookup[T services.Entity](ctx context.Context, collectionName string, id primitive.ObjectID, tenantID string) *T {
+	if id.IsZero() {
+		return nil
+	}
+	entity, err := services.GetEntityByID[T](ctx, collectionName, id.Hex(), tenantID)
+	if err != nil {
+		return nil
+	}
+	return entity
+}
+
+// Render executes a template's subject and body with the given data.
+func Render(tmpl *models.NotificationTemplate, data *TemplateData) (subject, body string, err error) {
+	if subject, err = execute("subject", tmpl.Subject, data); err != nil {
+		return "", "", err
+	}
+	if body, err = execute("body", tmpl.Body, data); err != nil {
+		return "", "", err
+	}
+	// Headers cannot span lines.
+	subject = strings.Join(strings.Fields(subject), " ")
+	return subject, body, nil
+}
+
+func execute(name, source string, data *TemplateData) (string, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// resolveRecipients turns the template's recipients into email addresses. Recipients that
+// the employee does not have (e.g. no buddy) are skipped.
+func resolveRecipients(recipients []string, data *TemplateData) []string {
+	seen := map[string]bool{}
+	var addresses []string
+	add := func(address string) {
+		if address != "" && !seen[strings.ToLower(address)] {
+			seen[strings.ToLower(address)] = true
+			addresses = append(addresses, address)
+		}
+	}
+	for _, recipient := range recipients {
+		switch recipient {
+		case RecipientEmployee:
+			add(data.Employee.Email)
+		case RecipientManager:
+			if data.Manager != nil {
+				add(data.Manager.Email)
+			}
+		case RecipientBuddy:
+			if data.Buddy != nil {
+				add(data.Buddy.Email)
+			}
+		default:
+			add(recipient)
+		}
+	}
+	return addresses
+}
+
+// --- Template Management ---
+
+// TemplateInput holds the fields a tenant admin can set on a template.
+type TemplateInput struct {
+	Name       string   `json:"name" binding:"required"`
+	Trigger    string   `json:"trigger" binding:"required"`
+	DaysBefore int      `json:"daysBefore"`
+	Recipients []string `json:"recipients" binding:"required"`
+	Subject    string   `json:"subject" binding:"required"`
+	Body       string   `json:"body" binding:"required"`
+	Active     *bool    `json:"active"` // Defaults to true
+}
+
+// validate checks the trigger, recipients and template syntax.
+func (in *TemplateInput) validate() error {
+	switch in.Trigger {
+	case TriggerEmployeeCreated, TriggerPortalInvite:
+		in.DaysBefore = 0
+	case TriggerOnboardingReminder:
+		if in.DaysBefore < 1 || in.DaysBefore > 60 {
+			return errors.New("daysBefore must be between 1 and 60 for onboarding reminders")
+		}
+	default:
+		return fmt.Errorf("unknown trigger %q, use one of %s", in.Trigger, strings.Join(Triggers, ", "))
+	}
+
+	if len(in.Recipients) == 0 {
+		return errors.New("at least one recipient is required")
+	}
+	for _, recipient := range in.Recipients {
+		if recipient == RecipientEmployee || recipient == RecipientManager || recipient == RecipientBuddy {
+			continue
+		}
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("recipient %q must be employee, manager, buddy or an email address", recipient)
+		}
+	}
+
+	for name, source := range map[string]string{"subject": in.Subject, "body": in.Body} {
+		if _, err := template.New(name).Funcs(templateFuncs).Parse(source); err != nil {
+			return fmt.Errorf("invalid %s template: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (in *TemplateInput) toModel(tenantID string) models.NotificationTemplate {
+	return models.NotificationTemplate{
+		TenantID:   tenantID,
+		Name:       in.Name,
+		Trigger:    in.Trigger,
+		DaysBefore: in.DaysBefore,
+		Recipients: in.Recipients,
+		Subject:    in.Subject,
+		Body:       in.Body,
+		Active:     in.Active == nil || *in.Active,
+		UpdatedAt:  primitive.NewDateTimeFromTime(time.Now()),
+	}
+}
+
+// CreateTemplate stores a new template for the tenant.
+func CreateTemplate(ctx context.Context, tenantID string, in *TemplateInput) (*models.NotificationTemplate, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	tmpl := in.toModel(tenantID)
+	tmpl.ID = primitive.NewObjectID()
+	if _, err := db.GetCollection("notification_templates").InsertOne(ctx, tmpl); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// GetTemplates lists the tenant's own templates.
+func GetTemplates(ctx context.Context, tenantID string) ([]models.NotificationTemplate, error) {
+	return services.GetEntitiesByTenant[models.NotificationTemplate](ctx, "notification_templates", tenantID, services.Pagination{})
+}
+
+// UpdateTemplate replaces a template of the tenant.
+func UpdateTemplate(ctx context.Context, id, tenantID string, in *TemplateInput) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	tmpl := in.toModel(tenantID)
+	update, err := toBSON(tmpl)
+	if err != nil {
+		return err
+	}
+	delete(update, "_id")
+	return services.UpdateEntity[models.NotificationTemplate](ctx, "notification_templates", id, tenantID, "", update)
+}
+
+// DeleteTemplate removes a template. If it was the tenant's last template for its
+// trigger, the default template applies again.
+func DeleteTemplate(ctx context.Context, id, tenantID string) error {
+	return services.DeleteEntity[models.NotificationTemplate](ctx, "notification_templates", id, tenantID, "")
+}
+
+// PreviewTemplate renders a template for one of the tenant's employees without sending anything.
+func PreviewTemplate(ctx context.Context, tenantID, employeeID string, in *TemplateInput) (subject, body string, to []string, err error) {
+	if err := in.validate(); err != nil {
+		return "", "", nil, err
+	}
+	employee, err := services.GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return "", "", nil, errors.New("employee not found or does not belong to this tenant")
+	}
+	data, err := loadTemplateData(ctx, *employee)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if in.Trigger == TriggerPortalInvite {
+		data.PortalURL = services.PortalURL("preview")
+		data.PortalLinkExpiresAt = time.Now().Add(services.PortalLinkLifetime).UTC()
+	}
+	tmpl := in.toModel(tenantID)
+	subject, body, err = Render(&tmpl, data)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return subject, body, resolveRecipients(tmpl.Recipients, data), nil
+}
+
+// templatesFor returns the active templates a tenant uses for a trigger: its own,
+// or the defaults if it has none for that trigger.
+func templatesFor(ctx context.Context, tenantID, trigger string) ([]models.NotificationTemplate, error) {
+	cursor, err := db.GetCollection("notification_templates").Find(ctx, bson.M{"tenantId": tenantID, "trigger": trigger})
+	if err != nil {
+		return nil, err
+	}
+	var own []models.NotificationTemplate
+	if err := cursor.All(ctx, &own); err != nil {
+		return nil, err
+	}
+	if len(own) == 0 {
+		for _, tmpl := range DefaultTemplates {
+			if tmpl.Trigger == trigger {
+				own = append(own, tmpl)
+			}
+		}
+	}
+
+	var active []models.NotificationTemplate
+	for _, tmpl := range own {
+		if tmpl.Active {
+			active = append(active, tmpl)
+		}
+	}
+	return active, nil
+}
+
+// toBSON converts a struct into a bson.M.
+func toBSON(v interface{}) (bson.M, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	err = bson.Unmarshal(data, &doc)
+	return doc, err
+}
@@ -0,0 +1,380 @@
+// Package scheduler runs recurring and one-off background jobs. Jobs are stored in
+// MongoDB, so every server replica sees the same schedule, and each run is claimed with
+// a lease so that only one replica runs a job at a time.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// pollInterval is how often each replica looks for due jobs.
+	pollInterval = 10 * time.Second
+	// leaseDuration is how long a claimed job stays locked. The lease is renewed while the
+	// job runs, so it only expires when the replica running it dies.
+	leaseDuration = 5 * time.Minute
+	// runRetention is how long run history is kept.
+	runRetention = 30 * 24 * time.Hour
+)
+
+// Handler runs a job. job.Payload holds the input of one-off jobs.
+type Handler func(ctx context.Context, job *models.ScheduledJob) error
+
+var (
+	mu       sync.RWMutex
+	handlers = map[string]Handler{}
+	defaults = map[string]string{} // Recurring job name -> default cron schedule
+
+	// instanceID identifies this replica in locks and run history.
+	instanceID = newInstanceID()
+)
+
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return fmt.Sprintf("%s-%s", host, hex.EncodeToString(buf))
+}
+
+// Register makes a handler available to one-off jobs under the given name.
+func Register(name string, handler Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers[name] = handler
+}
+
+// Recurring registers a handler and a recurring job of the same name with a default cron
+// schedule (standard 5-field syntax or descriptors such as "@daily"). The job is created
+// on Start if it does not exist; an existing job keeps its stored schedule and pause state.
+func Recurring(name, schedule string, handler Handler) {
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		panic(fmt.Sprintf("scheduler: invalid schedule %q for %s: %v", schedule, name, err))
+	}
+	Register(name, handler)
+	mu.Lock()
+	defer mu.Unlock()
+	defaults[name] = schedule
+}
+
+// Start creates the recurring jobs and indexes, then runs due jobs until ctx is cancelled.
+func Start(ctx context.Context) error {
+	jobs := db.GetCollection("scheduled_jobs")
+	runs := db.GetCollection("job_runs")
+
+	_, err := runs.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "jobId", Value: 1}, {Key: "startedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "startedAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(runRetention.Seconds()))},
+	})
+	if err != nil {
+		return err
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	now := time.Now()
+	// Stored jobs whose handler is not registered here may belong to another release
+	// during a rolling deploy, so they are left alone; this replica just never claims them.
+	cursor, err := jobs.Find(ctx, bson.M{"done": false, "handler": bson.M{"$nin": handlerNames()}})
+	if err != nil {
+		return err
+	}
+	var unknown []models.ScheduledJob
+	if err := cursor.All(ctx, &unknown); err != nil {
+		return err
+	}
+	for _, job := range unknown {
+		log.Printf("Scheduler has no handler %q for job %s on this replica; skipping it", job.Handler, job.ID)
+	}
+	for name, schedule := range defaults {
+		next, _ := nextRun(schedule, now)
+		_, err := jobs.UpdateByID(ctx, name, bson.M{"$setOnInsert": bson.M{
+			"handler":   name,
+			"schedule":  schedule,
+			"paused":    false,
+			"done":      false,
+			"nextRunAt": primitive.NewDateTimeFromTime(next),
+			"createdAt": primitive.NewDateTimeFromTime(now),
+		}}, options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("creating job %s: %w", name, err)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			for runNext(ctx) {
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// handlerNames lists the registered handlers. The caller holds mu.
+func handlerNames() []string {
+	names := make([]string, 0, len(handlers))
+	for name := range handlers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ScheduleOnce creates a one-off job that runs the named handler at runAt.
+func ScheduleOnce(ctx context.Context, handler string, runAt time.Time, payload map[string]interface{}) (*models.ScheduledJob, error) {
+	mu.RLock()
+	_, ok := handlers[handler]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown job handler %q", handler)
+	}
+
+	job := &models.ScheduledJob{
+		ID:        primitive.NewObjectID().Hex(),
+		Handler:   handler,
+		Payload:   payload,
+		NextRunAt: primitive.NewDateTimeFromTime(runAt),
+		CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+	}
+	if _, err := db.GetCollection("scheduled_jobs").InsertOne(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// nextRun returns the next time a cron schedule fires after t.
+func nextRun(schedule string, t time.Time) (time.Time, error) {
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsed.Next(t), nil
+}
+
+// runNext claims one due job, runs it and records the run. It reports whether it found one.
+func runNext(ctx context.Context) bool {
+	jobs := db.GetCollection("scheduled_jobs")
+	now := time.Now()
+
+	// 1. Claim a due job whose lock is free or expired and whose handler is registered here.
+	// Paused jobs only run when triggered manually.
+	mu.RLock()
+	names := handlerNames()
+	mu.RUnlock()
+	filter := bson.M{
+		"done":      false,
+		"handler":   bson.M{"$in": names},
+		"nextRunAt": bson.M{"$lte": primitive.NewDateTimeFromTime(now)},
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"paused": false},
+				{"triggeredBy": bson.M{"$exists": true}},
+			}},
+			{"$or": []bson.M{
+				{"lockedUntil": bson.M{"$exists": false}},
+				{"lockedUntil": bson.M{"$lte": primitive.NewDateTimeFromTime(now)}},
+			}},
+		},
+	}
+	claim := bson.M{"$set": bson.M{
+		"lockedBy":    instanceID,
+		"lockedUntil": primitive.NewDateTimeFromTime(now.Add(leaseDuration)),
+	}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextRunAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job models.ScheduledJob
+	if err := jobs.FindOneAndUpdate(ctx, filter, claim, opts).Decode(&job); err != nil {
+		if err != mongo.ErrNoDocuments && ctx.Err() == nil {
+			log.Printf("Scheduler could not claim a job: %v", err)
+		}
+		return false
+	}
+
+	// 2. Run it, renewing the lease while it runs.
+	run := models.JobRun{
+		ID:          primitive.NewObjectID(),
+		JobID:       job.ID,
+		Handler:     job.Handler,
+		Trigger:     "schedule",
+		TriggeredBy: job.TriggeredBy,
+		Instance:    instanceID,
+		Status:      "running",
+		StartedAt:   primitive.NewDateTimeFromTime(now),
+	}
+	if job.TriggeredBy != "" {
+		run.Trigger = "manual"
+	}
+	runs := db.GetCollection("job_runs")
+	if _, err := runs.InsertOne(ctx, run); err != nil {
+		// Without a run record the job would run unseen; release it and try again later.
+		log.Printf("Scheduler could not record the run of job %s, releasing it: %v", job.ID, err)
+		jobs.UpdateOne(ctx, bson.M{"_id": job.ID, "lockedBy": instanceID},
+			bson.M{"$unset": bson.M{"lockedBy": "", "lockedUntil": ""}})
+		return false
+	}
+
+	runErr := execute(ctx, &job)
+
+	// 3. Record the outcome and release the lock.
+	finished := time.Now()
+	run.Status, run.FinishedAt = "succeeded", primitive.NewDateTimeFromTime(finished)
+	if runErr != nil {
+		run.Status, run.Error = "failed", runErr.Error()
+		log.Printf("Job %s failed: %v", job.ID, runErr)
+	}
+	if _, err := runs.ReplaceOne(ctx, bson.M{"_id": run.ID}, run); err != nil {
+		log.Printf("Scheduler could not record the outcome of job %s: %v", job.ID, err)
+	}
+
+	set := bson.M{
+		"lastRunAt":  primitive.NewDateTimeFromTime(finished),
+		"lastStatus": run.Status,
+		"lastError":  run.Error,
+	}
+	if job.Schedule == "" {
+		set["done"] = true
+	} else if next, err := nextRun(job.Schedule, finished); err == nil {
+		set["nextRunAt"] = primitive.NewDateTimeFromTime(next)
+	} else {
+		set["paused"] = true // A stored schedule that no longer parses must not spin
+		set["lastError"] = "invalid schedule: " + err.Error()
+	}
+	_, err := jobs.UpdateOne(ctx, bson.M{"_id": job.ID, "lockedBy": instanceID}, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"lockedBy": "", "lockedUntil": "", "triggeredBy": ""},
+	})
+	if err != nil {
+		// The job stays locked until its lease expires and then runs again.
+		log.Printf("Scheduler could not release job %s: %v", job.ID, err)
+	}
+	return true
+}
+
+// execute calls the job's handler, renewing the lease until it returns.
+func execute(ctx context.Context, job *models.ScheduledJob) (err error) {
+	mu.RLock()
+	handler, ok := handlers[job.Handler]
+	mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler registered for %q", job.Handler)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(leaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				db.GetCollection("scheduled_jobs").UpdateOne(runCtx,
+					bson.M{"_id": job.ID, "lockedBy": instanceID},
+					bson.M{"$set": bson.M{"lockedUntil": primitive.NewDateTimeFromTime(time.Now().Add(leaseDuration))}})
+			}
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(runCtx, job)
+}
+
+// --- Administration ---
+
+// GetJobs lists all jobs, recurring ones first.
+func GetJobs(ctx context.Context) ([]models.ScheduledJob, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "done", Value: 1}, {Key: "nextRunAt", Value: 1}})
+	cursor, err := db.GetCollection("scheduled_jobs").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	jobs := []models.ScheduledJob{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetJobRuns lists the runs of a job, newest first.
+func GetJobRuns(ctx context.Context, jobID string, limit, offset int64) ([]models.JobRun, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "startedAt", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	if offset > 0 {
+		opts.SetSkip(offset)
+	}
+	cursor, err := db.GetCollection("job_runs").Find(ctx, bson.M{"jobId": jobID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	runs := []models.JobRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// TriggerJob makes a job run as soon as possible, even if it is paused or a one-off job
+// that already ran. A recurring job keeps its schedule and pause state afterwards.
+func TriggerJob(ctx context.Context, jobID, userID string) error {
+	result, err := db.GetCollection("scheduled_jobs").UpdateByID(ctx, jobID, bson.M{"$set": bson.M{
+		"nextRunAt":   primitive.NewDateTimeFromTime(time.Now()),
+		"done":        false,
+		"triggeredBy": userID,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("job not found")
+	}
+	return nil
+}
+
+// SetJobPaused pauses or resumes a job. A resumed recurring job continues from its next
+// scheduled time rather than catching up on the runs it missed.
+func SetJobPaused(ctx context.Context, jobID string, paused bool) error {
+	var job models.ScheduledJob
+	err := db.GetCollection("scheduled_jobs").FindOne(ctx, bson.M{"_id": jobID}).Decode(&job)
+	if err != nil {
+		return errors.New("job not found")
+	}
+
+	set := bson.M{"paused": paused}
+	if !paused && job.Schedule != "" {
+		if next, err := nextRun(job.Schedule, time.Now()); err == nil {
+			set["nextRunAt"] = primitive.NewDateTimeFromTime(next)
+		}
+	}
+	_, err = db.GetCollection("scheduled_jobs").UpdateByID(ctx, jobID, bson.M{"$set": set})
+	return err
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kinds of access request.
+const (
+	AccessGrant  = "grant"
+	AccessRevoke = "revoke"
+)
+
+// Access request statuses. A request is pending while it waits for approvals, approved
+// once every step approved it, and granted or revoked once the access was actually
+// provisioned or removed. A granted request becomes revoked when its revocation is
+// carried out.
+const (
+	AccessPending   = ApprovalPending
+	AccessApproved  = ApprovalApproved
+	AccessRejected  = ApprovalRejected
+	AccessCancelled = "cancelled"
+	AccessGranted   = "granted"
+	AccessRevoked   = "revoked"
+)
+
+// accessApprovalSteps are the approval steps of each kind of request, in order. Each
+// step is decided by a user with that role, or an admin. Revocations skip the manager:
+// removing access is never riskier than keeping it.
+var accessApprovalSteps = map[string][]string{
+	AccessGrant:  {"manager", "security"},
+	AccessRevoke: {"security"},
+}
+
+// ErrAccessRequestNotFound is returned for access requests that do not exist in the tenant.
+var ErrAccessRequestNotFound = errors.New("access request not found or does not belong to this tenant")
+
+// ErrAccessLevelReadOnly is returned when an employee's access level is set directly
+// instead of through an access request.
+var ErrAccessLevelReadOnly = errors.New("accessLevelId can only be changed by carrying out an access request")
+
+// errRevocationOpen is returned when a grant already has an open revocation.
+var errRevocationOpen = errors.New("a revocation of this access is already open")
+
+// offboardingJustification is the justification of revocations opened when an employee leaves.
+const offboardingJustification = "Employee offboarded"
+
+// --- Requests ---
+
+// AccessRequestInput asks for an access level for an employee.
+type AccessRequestInput struct {
+	AccessLevelID string `json:"accessLevelId" binding:"required"`
+	Justification string `json:"justification" binding:"required"`
+}
+
+// CreateAccessRequest opens a request to grant an access level to an employee. There can
+// be only one open or granted request per employee and level.
+func CreateAccessRequest(ctx context.Context, employeeID, tenantID, userID string, in *AccessRequestInput) (*models.AccessRequest, error) {
+	employee, err := GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return nil, ErrEmployeeNotFound
+	}
+	level, err := GetEntityByID[models.AccessLevel](ctx, "access_levels", in.AccessLevelID, tenantID)
+	if err != nil {
+		return nil, errors.New("accessLevelId does not refer to an access level of this tenant")
+	}
+	in.Justification = strings.TrimSpace(in.Justification)
+	if in.Justification == "" {
+		return nil, errors.New("justification is required")
+	}
+
+	name := strings.TrimSpace(employee.FirstName + " " + employee.LastName)
+	existing, err := db.GetCollection("access_requests").CountDocuments(ctx, bson.M{
+		"tenantId":      tenantID,
+		"kind":          AccessGrant,
+		"employeeId":    employee.ID,
+		"accessLevelId": level.ID,
+		"status":        bson.M{"$in": []string{AccessPending, AccessApproved, AccessGranted}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("%s already has or has requested the %s access level", name, level.Name)
+	}
+
+	request := newAccessRequest(tenantID, AccessGrant, employee.ID, name, level.ID, in.Justification, userID)
+	if _, err := db.GetCollection("access_requests").InsertOne(ctx, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func newAccessRequest(tenantID, kind string, employeeID primitive.ObjectID, employeeName string, levelID primitive.ObjectID, justification, requestedBy string) *models.AccessRequest {
+	return &models.AccessRequest{
+		ID:            primitive.NewObjectID(),
+		TenantID:      tenantID,
+		Kind:          kind,
+		EmployeeID:    employeeID,
+		EmployeeName:  employeeName,
+		AccessLevelID: levelID,
+		Justification: justification,
+		Status:        AccessPending,
+		Steps:         accessApprovalSteps[kind],
+		Approvals:     []models.Approval{},
+		RequestedBy:   requestedBy,
+		RequestedAt:   primitive.NewDateTimeFromTime(time.Now()),
+	}
+}
+
+// RequestAccessRevocation opens a request to revoke a granted access level.
+func RequestAccessRevocation(ctx context.Context, grantID, tenantID, userID, justification string) (*models.AccessRequest, error) {
+	grant, err := GetAccessRequest(ctx, grantID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Kind != AccessGrant || grant.Status != AccessGranted {
+		return nil, errors.New("only granted access can be revoked")
+	}
+	justification = strings.TrimSpace(justification)
+	if justification == "" {
+		return nil, errors.New("justification is required")
+	}
+	return openAccessRevocation(ctx, grant, justification, userID)
+}
+
+// openAccessRevocation opens a revocation of a grant, unless one is already open.
+func openAccessRevocation(ctx context.Context, grant *models.AccessRequest, justification, requestedBy string) (*models.AccessRequest, error) {
+	var open models.AccessRequest
+	err := db.GetCollection("access_requests").FindOne(ctx, bson.M{
+		"tenantId": grant.TenantID,
+		"grantId":  grant.ID,
+		"status":   bson.M{"$in": []string{AccessPending, AccessApproved}},
+	}).Decode(&open)
+	if err == nil {
+		return nil, errRevocationOpen
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	request := newAccessRequest(grant.TenantID, AccessRevoke, grant.EmployeeID, grant.EmployeeName, grant.AccessLevelID, justification, requestedBy)
+	request.GrantID = grant.ID
+	if _, err := db.GetCollection("access_requests").InsertOne(ctx, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// AccessRequestFilter narrows the list of access requests. Empty fields match everything.
+type AccessRequestFilter struct {
+	EmployeeID string
+	Status     string
+	Kind       string
+	Step       string // Only pending requests waiting for this approval step
+}
+
+// GetAccessRequests lists the tenant's access requests, newest first.
+func GetAccessRequests(ctx context.Context, tenantID string, filter AccessRequestFilter, page Pagination) ([]models.AccessRequest, error) {
+	query := bson.M{"tenantId": tenantID}
+	if filter.EmployeeID != "" {
+		id, err := primitive.ObjectIDFromHex(filter.EmployeeID)
+		if err != nil {
+			return nil, errors.New("invalid id format")
+		}
+		query["employeeId"] = id
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Kind != "" {
+		query["kind"] = filter.Kind
+	}
+	if filter.Step != "" {
+		// The step waiting for a decision is the one after the decisions taken so far.
+		query["status"] = AccessPending
+		query["$expr"] = bson.M{"$eq": bson.A{
+			bson.M{"$arrayElemAt": bson.A{"$steps", bson.M{"$size": "$approvals"}}},
+			filter.Step,
+		}}
+	}
+
+	opts := page.findOptions().SetSort(bson.D{{Key: "requestedAt", Value: -1}})
+	cursor, err := db.GetCollection("access_requests").Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	requests := []models.AccessRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetAccessRequest fetches one of the tenant's access requests.
+func GetAccessRequest(ctx context.Context, id, tenantID string) (*models.AccessRequest, error) {
+	request, err := GetEntityByID[models.AccessRequest](ctx, "access_requests", id, tenantID)
+	if err != nil {
+		return nil, ErrAccessRequestNotFound
+	}
+	return request, nil
+}
+
+// --- Approvals ---
+
+// DecideAccessRequest approves or rejects the step a pending request is waiting for.
+// A rejection ends the request; the approval of the last step makes it approved.
+func DecideAccessRequest(ctx context.Context, id, tenantID, userID string, approve bool, comment string) (*models.AccessRequest, error) {
+	request, err := GetAccessRequest(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != AccessPending {
+		return nil, fmt.Errorf("the request is %s and no longer waits for approval", request.Status)
+	}
+
+	approval, status, err := decideStep(ctx, tenantID, userID, request.RequestedBy, request.Steps, request.Approvals, approve, comment)
+	if err != nil {
+		return nil, err
+	}
+	// Matching on the number of decisions makes sure two approvers cannot decide the
+	// same step at once.
+	return updateAccessRequest(ctx, bson.M{"_id": request.ID, "status": AccessPending, "approvals": bson.M{"$size": len(request.Approvals)}},
+		bson.M{"$push": bson.M{"approvals": approval}, "$set": bson.M{"status": status}})
+}
+
+// CancelAccessRequest withdraws a request that has not been carried out yet. Only the
+// requester and admins may cancel.
+func CancelAccessRequest(ctx context.Context, id, tenantID, userID string) (*models.AccessRequest, error) {
+	request, err := GetAccessRequest(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestedBy != userID {
+		if role, err := userRole(ctx, userID, tenantID); err != nil || role != "admin" {
+			return nil, ErrRoleForbidden
+		}
+	}
+	return updateAccessRequest(ctx, bson.M{"_id": request.ID, "status": bson.M{"$in": []string{AccessPending, AccessApproved}}},
+		bson.M{"$set": bson.M{"status": AccessCancelled}})
+}
+
+// CompleteAccessRequest records that an approved request was carried out. A grant becomes
+// granted and sets the employee's access level. A revocation becomes revoked, together
+// with the grant it revokes, and clears the employee's access level if it is that one.
+func CompleteAccessRequest(ctx context.Context, id, tenantID, userID string) (*models.AccessRequest, error) {
+	request, err := GetAccessRequest(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := userRole(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	// Carrying out approved requests belongs to the security step.
+	if !mayDecide("security", role) {
+		return nil, ErrRoleForbidden
+	}
+	if request.Status != AccessApproved {
+		return nil, fmt.Errorf("only approved requests can be carried out, this one is %s", request.Status)
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	if request.Kind == AccessGrant {
+		completed, err := updateAccessRequest(ctx, bson.M{"_id": request.ID, "status": AccessApproved},
+			bson.M{"$set": bson.M{"status": AccessGranted, "grantedBy": userID, "grantedAt": now}})
+		if err != nil {
+			return nil, err
+		}
+		// The access request was approved already, so the approval policies are not checked again.
+		if err := updateEmployee(request.EmployeeID.Hex(), tenantID, bson.M{"accessLevelId": request.AccessLevelID}); err != nil && !errors.Is(err, ErrEmployeeNotFound) {
+			return nil, err
+		}
+		return completed, nil
+	}
+
+	revoked := bson.M{"$set": bson.M{"status": AccessRevoked, "revokedBy": userID, "revokedAt": now}}
+	completed, err := updateAccessRequest(ctx, bson.M{"_id": request.ID, "status": AccessApproved}, revoked)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.GetCollection("access_requests").UpdateOne(ctx, bson.M{"_id": request.GrantID, "status": AccessGranted}, revoked); err != nil {
+		return nil, err
+	}
+	// The employee is gone when the revocation comes from offboarding.
+	var employee models.Employee
+	err = db.GetCollection("employees").FindOne(ctx, notDeleted(bson.M{"_id": request.EmployeeID, "tenantId": tenantID})).Decode(&employee)
+	if err == nil && employee.AccessLevelID == request.AccessLevelID {
+		if err := updateEmployee(employee.ID.Hex(), tenantID, bson.M{"accessLevelId": primitive.NilObjectID}); err != nil {
+			return nil, err
+		}
+	}
+	return completed, nil
+}
+
+// updateAccessRequest applies update to the request matched by filter and returns the
+// result. No match means the request changed in the meantime.
+func updateAccessRequest(ctx context.Context, filter, update bson.M) (*models.AccessRequest, error) {
+	var request models.AccessRequest
+	err := db.GetCollection("access_requests").FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&request)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.New("the request was changed in the meantime, reload it and try again")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// --- Offboarding ---
+
+// revokeAccessOfDeletedEmployee cancels the open access requests of an offboarded
+// employee and opens a revocation for every access they were granted. It is subscribed
+// to employee.deleted.
+func revokeAccessOfDeletedEmployee(ctx context.Context, env events.Envelope) error {
+	e, ok := env.Event.(events.EmployeeDeleted)
+	if !ok {
+		return nil
+	}
+	requests := db.GetCollection("access_requests")
+	_, err := requests.UpdateMany(ctx,
+		bson.M{"tenantId": e.Employee.TenantID, "employeeId": e.Employee.ID, "kind": AccessGrant, "status": bson.M{"$in": []string{AccessPending, AccessApproved}}},
+		bson.M{"$set": bson.M{"status": AccessCancelled}})
+	if err != nil {
+		return err
+	}
+
+	cursor, err := requests.Find(ctx, bson.M{"tenantId": e.Employee.TenantID, "employeeId": e.Employee.ID, "kind": AccessGrant, "status": AccessGranted})
+	if err != nil {
+		return err
+	}
+	var grants []models.AccessRequest
+	if err := cursor.All(ctx, &grants); err != nil {
+		return err
+	}
+	for i := range grants {
+		// An open revocation, requested by hand before the employee left, is kept.
+		if _, err := openAccessRevocation(ctx, &grants[i], offboardingJustification, "system"); err != nil && !errors.Is(err, errRevocationOpen) {
+			return err
+		}
+	}
+	return nil
+}
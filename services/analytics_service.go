@@ -0,0 +1,515 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultUpcomingDays is how far ahead the upcoming onboardings report looks by default.
+const DefaultUpcomingDays = 30
+
+// MaxUpcomingDays is the furthest the upcoming onboardings report looks ahead.
+const MaxUpcomingDays = 365
+
+// unassignedGroupName names the report group of employees without the grouped reference.
+const unassignedGroupName = "Unassigned"
+
+// AnalyticsGroups are the employee references analytics reports can be grouped by.
+var AnalyticsGroups = map[string]Reference{
+	"department":     {Field: "departmentId", Slug: "departments"},
+	"location":       {Field: "locationId", Slug: "locations"},
+	"employmentType": {Field: "employmentTypeId", Slug: "employement-types"},
+	"team":           {Field: "teamId", Slug: "teams"},
+	"jobRole":        {Field: "jobRoleId", Slug: "job-roles"},
+	"costCenter":     {Field: "costCenterId", Slug: "costs"},
+}
+
+// ReportRow is one row of an analytics report. Cells lists its values in the order of the
+// report's columns, for CSV and XLSX output.
+type ReportRow interface {
+	Cells() []string
+}
+
+// DateRange limits a report to the days from From to To, both included. A zero bound is open.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// filter returns the MongoDB condition for a date field within the range, or nil for an
+// open range.
+func (r DateRange) filter() bson.M {
+	condition := bson.M{}
+	if !r.From.IsZero() {
+		condition["$gte"] = primitive.NewDateTimeFromTime(r.From.Truncate(24 * time.Hour))
+	}
+	if !r.To.IsZero() {
+		condition["$lt"] = primitive.NewDateTimeFromTime(r.To.Truncate(24*time.Hour).AddDate(0, 0, 1))
+	}
+	if len(condition) == 0 {
+		return nil
+	}
+	return condition
+}
+
+// analyticsGroup looks up a groupBy value. An empty one groups everything together.
+func analyticsGroup(groupBy string) (Reference, error) {
+	if groupBy == "" {
+		return Reference{}, nil
+	}
+	group, ok := AnalyticsGroups[groupBy]
+	if !ok {
+		return Reference{}, fmt.Errorf("unknown groupBy %q, use department, location, employmentType, team, jobRole or costCenter", groupBy)
+	}
+	return group, nil
+}
+
+// groupName returns the display name of a report group.
+func groupName(index *nameIndex, id primitive.ObjectID) string {
+	if index == nil {
+		return ""
+	}
+	if id.IsZero() {
+		return unassignedGroupName
+	}
+	return index.names[id]
+}
+
+// loadGroupNames loads the names of the entities a report is grouped by, if any.
+func loadGroupNames(ctx context.Context, tenantID string, group Reference) (*nameIndex, error) {
+	if group.Field == "" {
+		return nil, nil
+	}
+	return loadNameIndex(ctx, group.Slug, tenantID)
+}
+
+// hexOrEmpty formats an ObjectID, leaving the zero ID empty.
+func hexOrEmpty(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.Hex()
+}
+
+// --- Hires per Month ---
+
+// HiresReportColumns are the CSV columns of the hires report.
+var HiresReportColumns = []string{"month", "groupId", "group", "hires"}
+
+// HiresReportRow counts the employees onboarding in one month, per group.
+type HiresReportRow struct {
+	Month   string `json:"month"` // e.g. "2026-03"
+	GroupID string `json:"groupId,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Hires   int    `json:"hires"`
+}
+
+func (r HiresReportRow) Cells() []string {
+	return []string{r.Month, r.GroupID, r.Group, strconv.Itoa(r.Hires)}
+}
+
+// GetHiresReport counts the employees whose onboarding date falls in each month of the
+// range, optionally per department, location or another group of AnalyticsGroups. Months
+// without hires are left out.
+func GetHiresReport(ctx context.Context, tenantID string, dates DateRange, groupBy string) ([]HiresReportRow, error) {
+	group, err := analyticsGroup(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	match := notDeleted(bson.M{"tenantId": tenantID})
+	if condition := dates.filter(); condition != nil {
+		match["onboardingDate"] = condition
+	}
+	var groupKey interface{}
+	if group.Field != "" {
+		groupKey = "$" + group.Field
+	}
+
+	cursor, err := db.GetCollection("employees").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id": bson.M{
+				"month": bson.M{"$dateToString": bson.M{"format": "%Y-%m", "date": "$onboardingDate"}},
+				"group": groupKey,
+			},
+			"hires": bson.M{"$sum": 1},
+		}},
+		{"$sort": bson.D{{Key: "_id.month", Value: 1}, {Key: "hires", Value: -1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		ID struct {
+			Month string             `bson:"month"`
+			Group primitive.ObjectID `bson:"group"`
+		} `bson:"_id"`
+		Hires int `bson:"hires"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	names, err := loadGroupNames(ctx, tenantID, group)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]HiresReportRow, len(results))
+	for i, result := range results {
+		rows[i] = HiresReportRow{
+			Month:   result.ID.Month,
+			GroupID: hexOrEmpty(result.ID.Group),
+			Group:   groupName(names, result.ID.Group),
+			Hires:   result.Hires,
+		}
+	}
+	return rows, nil
+}
+
+// --- Upcoming Onboardings ---
+
+// UpcomingReportColumns are the CSV columns of the upcoming onboardings report.
+var UpcomingReportColumns = []string{"employeeId", "firstName", "lastName", "email", "onboardingDate", "daysUntil", "department", "location"}
+
+// UpcomingReportRow is an employee who starts soon.
+type UpcomingReportRow struct {
+	EmployeeID     string    `json:"employeeId"`
+	FirstName      string    `json:"firstName"`
+	LastName       string    `json:"lastName"`
+	Email          string    `json:"email"`
+	OnboardingDate time.Time `json:"onboardingDate"`
+	DaysUntil      int       `json:"daysUntil"`
+	Department     string    `json:"department,omitempty"`
+	Location       string    `json:"location,omitempty"`
+}
+
+func (r UpcomingReportRow) Cells() []string {
+	return []string{r.EmployeeID, r.FirstName, r.LastName, r.Email, r.OnboardingDate.Format("2006-01-02"),
+		strconv.Itoa(r.DaysUntil), r.Department, r.Location}
+}
+
+// GetUpcomingReport lists the employees onboarding from today through the given number of
+// days ahead, soonest first.
+func GetUpcomingReport(ctx context.Context, tenantID string, days int) ([]UpcomingReportRow, error) {
+	if days <= 0 {
+		days = DefaultUpcomingDays
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	cursor, err := db.GetCollection("employees").Aggregate(ctx, []bson.M{
+		{"$match": notDeleted(bson.M{
+			"tenantId":       tenantID,
+			"onboardingDate": DateRange{From: today, To: today.AddDate(0, 0, days)}.filter(),
+		})},
+		{"$sort": bson.D{{Key: "onboardingDate", Value: 1}, {Key: "lastName", Value: 1}}},
+		{"$project": bson.M{"firstName": 1, "lastName": 1, "email": 1, "onboardingDate": 1, "departmentId": 1, "locationId": 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var employees []struct {
+		ID             primitive.ObjectID `bson:"_id"`
+		FirstName      string             `bson:"firstName"`
+		LastName       string             `bson:"lastName"`
+		Email          string             `bson:"email"`
+		OnboardingDate primitive.DateTime `bson:"onboardingDate"`
+		DepartmentID   primitive.ObjectID `bson:"departmentId"`
+		LocationID     primitive.ObjectID `bson:"locationId"`
+	}
+	if err := cursor.All(ctx, &employees); err != nil {
+		return nil, err
+	}
+
+	departments, err := loadNameIndex(ctx, "departments", tenantID)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := loadNameIndex(ctx, "locations", tenantID)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]UpcomingReportRow, len(employees))
+	for i, employee := range employees {
+		onboarding := employee.OnboardingDate.Time().UTC()
+		rows[i] = UpcomingReportRow{
+			EmployeeID:     employee.ID.Hex(),
+			FirstName:      employee.FirstName,
+			LastName:       employee.LastName,
+			Email:          employee.Email,
+			OnboardingDate: onboarding,
+			DaysUntil:      int(onboarding.Truncate(24*time.Hour).Sub(today).Hours() / 24),
+			Department:     departments.names[employee.DepartmentID],
+			Location:       locations.names[employee.LocationID],
+		}
+	}
+	return rows, nil
+}
+
+// --- Time to Complete Onboarding ---
+
+// CompletionReportColumns are the CSV columns of the time-to-complete report.
+var CompletionReportColumns = []string{"groupId", "group", "completed", "averageDays", "minDays", "maxDays"}
+
+// CompletionReportRow summarises how long onboarding took for the employees of one group.
+type CompletionReportRow struct {
+	GroupID     string  `json:"groupId,omitempty"`
+	Group       string  `json:"group,omitempty"`
+	Completed   int     `json:"completed"` // Employees who completed onboarding in the range
+	AverageDays float64 `json:"averageDays"`
+	MinDays     float64 `json:"minDays"`
+	MaxDays     float64 `json:"maxDays"`
+}
+
+func (r CompletionReportRow) Cells() []string {
+	return []string{r.GroupID, r.Group, strconv.Itoa(r.Completed), formatDays(r.AverageDays),
+		formatDays(r.MinDays), formatDays(r.MaxDays)}
+}
+
+func formatDays(days float64) string {
+	return strconv.FormatFloat(days, 'f', 1, 64)
+}
+
+// GetCompletionReport measures how long onboarding takes. An employee has completed
+// onboarding once all of their documents are approved; the time taken runs from the
+// creation of the employee record to the last approval. Only employees who completed
+// onboarding within the range are counted, optionally per group of AnalyticsGroups.
+func GetCompletionReport(ctx context.Context, tenantID string, dates DateRange, groupBy string) ([]CompletionReportRow, error) {
+	group, err := analyticsGroup(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	var groupKey interface{}
+	if group.Field != "" {
+		groupKey = "$employee." + group.Field
+	}
+	completed := bson.M{"$expr": bson.M{"$eq": bson.A{"$approved", "$documents"}}}
+	if condition := dates.filter(); condition != nil {
+		completed["completedAt"] = condition
+	}
+	const dayMillis = 24 * 60 * 60 * 1000
+
+	cursor, err := db.GetCollection("employee_documents").Aggregate(ctx, []bson.M{
+		// 1. Find the employees whose documents are all approved, and when the last one was.
+		{"$match": bson.M{"tenantId": tenantID}},
+		{"$group": bson.M{
+			"_id":         "$employeeId",
+			"documents":   bson.M{"$sum": 1},
+			"approved":    bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", DocumentApproved}}, 1, 0}}},
+			"completedAt": bson.M{"$max": "$reviewedAt"},
+		}},
+		{"$match": completed},
+
+		// 2. Join the employees, which drops deleted ones, and measure the time taken.
+		{"$lookup": bson.M{"from": "employees", "localField": "_id", "foreignField": "_id", "as": "employee"}},
+		{"$unwind": "$employee"},
+		{"$match": bson.M{"employee.deletedAt": bson.M{"$exists": false}}},
+		{"$project": bson.M{
+			"group": groupKey,
+			"days":  bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$completedAt", bson.M{"$toDate": "$_id"}}}, dayMillis}},
+		}},
+
+		// 3. Summarise per group.
+		{"$group": bson.M{
+			"_id":         "$group",
+			"completed":   bson.M{"$sum": 1},
+			"averageDays": bson.M{"$avg": "$days"},
+			"minDays":     bson.M{"$min": "$days"},
+			"maxDays":     bson.M{"$max": "$days"},
+		}},
+		{"$project": bson.M{
+			"completed":   1,
+			"averageDays": bson.M{"$round": bson.A{"$averageDays", 1}},
+			"minDays":     bson.M{"$round": bson.A{"$minDays", 1}},
+			"maxDays":     bson.M{"$round": bson.A{"$maxDays", 1}},
+		}},
+		{"$sort": bson.D{{Key: "completed", Value: -1}, {Key: "_id", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Group       primitive.ObjectID `bson:"_id"`
+		Completed   int                `bson:"completed"`
+		AverageDays float64            `bson:"averageDays"`
+		MinDays     float64            `bson:"minDays"`
+		MaxDays     float64            `bson:"maxDays"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	names, err := loadGroupNames(ctx, tenantID, group)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]CompletionReportRow, len(results))
+	for i, result := range results {
+		rows[i] = CompletionReportRow{
+			GroupID:     hexOrEmpty(result.Group),
+			Group:       groupName(names, result.Group),
+			Completed:   result.Completed,
+			AverageDays: result.AverageDays,
+			MinDays:     result.MinDays,
+			MaxDays:     result.MaxDays,
+		}
+	}
+	return rows, nil
+}
+
+// --- Span of Control ---
+
+// SpanOfControlColumns are the CSV columns of the span of control report.
+var SpanOfControlColumns = []string{"employeeId", "firstName", "lastName", "email", "directReports"}
+
+// SpanOfControlRow counts the direct reports of one employee.
+type SpanOfControlRow struct {
+	EmployeeID    string `json:"employeeId"`
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	Email         string `json:"email"`
+	DirectReports int    `json:"directReports"`
+}
+
+func (r SpanOfControlRow) Cells() []string {
+	return []string{r.EmployeeID, r.FirstName, r.LastName, r.Email, strconv.Itoa(r.DirectReports)}
+}
+
+// GetSpanOfControlReport lists every employee with direct reports in the org chart, the
+// largest teams first.
+func GetSpanOfControlReport(ctx context.Context, tenantID string) ([]SpanOfControlRow, error) {
+	cursor, err := db.GetCollection("employees").Aggregate(ctx, []bson.M{
+		{"$match": notDeleted(bson.M{"tenantId": tenantID, "reportsToId": bson.M{"$exists": true, "$ne": primitive.NilObjectID}})},
+		{"$group": bson.M{"_id": "$reportsToId", "directReports": bson.M{"$sum": 1}}},
+		{"$lookup": bson.M{"from": "employees", "localField": "_id", "foreignField": "_id", "as": "manager"}},
+		{"$unwind": "$manager"},
+		{"$match": bson.M{"manager.tenantId": tenantID, "manager.deletedAt": bson.M{"$exists": false}}},
+		{"$project": bson.M{
+			"directReports": 1,
+			"firstName":     "$manager.firstName",
+			"lastName":      "$manager.lastName",
+			"email":         "$manager.email",
+		}},
+		{"$sort": bson.D{{Key: "directReports", Value: -1}, {Key: "lastName", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		ID            primitive.ObjectID `bson:"_id"`
+		FirstName     string             `bson:"firstName"`
+		LastName      string             `bson:"lastName"`
+		Email         string             `bson:"email"`
+		DirectReports int                `bson:"directReports"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	rows := make([]SpanOfControlRow, len(results))
+	for i, result := range results {
+		rows[i] = SpanOfControlRow{
+			EmployeeID:    result.ID.Hex(),
+			FirstName:     result.FirstName,
+			LastName:      result.LastName,
+			Email:         result.Email,
+			DirectReports: result.DirectReports,
+		}
+	}
+	return rows, nil
+}
+
+// --- Entity Usage ---
+
+// EntityUsageColumns are the CSV columns of the entity usage report.
+var EntityUsageColumns = []string{"entityType", "entityId", "name", "employees"}
+
+// EntityUsageRow counts the employees that reference one entity.
+type EntityUsageRow struct {
+	EntityType string `json:"entityType"`
+	EntityID   string `json:"entityId"`
+	Name       string `json:"name"`
+	Employees  int    `json:"employees"`
+}
+
+func (r EntityUsageRow) Cells() []string {
+	return []string{r.EntityType, r.EntityID, r.Name, strconv.Itoa(r.Employees)}
+}
+
+// GetEntityUsageReport counts how many employees reference each entity, including entities
+// nobody uses. entityType limits the report to one type; the date range limits it to
+// employees onboarding in that period.
+func GetEntityUsageReport(ctx context.Context, tenantID, entityType string, dates DateRange) ([]EntityUsageRow, error) {
+	references := EmployeeReferences
+	if entityType != "" {
+		references = nil
+		for _, ref := range EmployeeReferences {
+			if ref.Slug == entityType {
+				references = []Reference{ref}
+			}
+		}
+		if references == nil {
+			return nil, fmt.Errorf("unknown entity type %q", entityType)
+		}
+	}
+
+	// 1. Count the employees per referenced entity, one facet per reference.
+	match := notDeleted(bson.M{"tenantId": tenantID})
+	if condition := dates.filter(); condition != nil {
+		match["onboardingDate"] = condition
+	}
+	facets := bson.M{}
+	for _, ref := range references {
+		facets[ref.Slug] = bson.A{bson.M{"$group": bson.M{"_id": "$" + ref.Field, "employees": bson.M{"$sum": 1}}}}
+	}
+	cursor, err := db.GetCollection("employees").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{"$facet": facets},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []map[string][]struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Employees int                `bson:"employees"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	// 2. List every entity with its count.
+	var rows []EntityUsageRow
+	for _, ref := range references {
+		names, err := loadNameIndex(ctx, ref.Slug, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		counts := map[primitive.ObjectID]int{}
+		if len(results) > 0 {
+			for _, count := range results[0][ref.Slug] {
+				counts[count.ID] = count.Employees
+			}
+		}
+		usage := make([]EntityUsageRow, 0, len(names.names))
+		for id, name := range names.names {
+			usage = append(usage, EntityUsageRow{EntityType: ref.Slug, EntityID: id.Hex(), Name: name, Employees: counts[id]})
+		}
+		// The most used entities first.
+		sort.Slice(usage, func(i, j int) bool {
+			if usage[i].Employees != usage[j].Employees {
+				return usage[i].Employees > usage[j].Employees
+			}
+			return usage[i].Name < usage[j].Name
+		})
+		rows = append(rows, usage...)
+	}
+	if rows == nil {
+		rows = []EntityUsageRow{}
+	}
+	return rows, nil
+}
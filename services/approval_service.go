@@ -0,0 +1,516 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Access requests and change requests both pass a list of approval steps in order. Each
+// step names the user role that decides it, and admins may decide any step. A rejection
+// ends the request; the approval of the last step makes it approved.
+
+// Statuses shared by every request that needs approval.
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// Further change request statuses. An approved change is applied at once, so it only
+// stays approved while that happens and then ends up applied, or failed if the update
+// was refused.
+const (
+	ChangeCancelled = "cancelled"
+	ChangeApplied   = "applied"
+	ChangeFailed    = "failed"
+)
+
+// ErrRoleForbidden is returned when the user's role may not take the action.
+var ErrRoleForbidden = errors.New("your role may not take this action")
+
+// ErrChangeRequestNotFound is returned for change requests that do not exist in the tenant.
+var ErrChangeRequestNotFound = errors.New("change request not found or does not belong to this tenant")
+
+// ChangeHeldError is returned by the update functions when an approval policy holds an
+// update back. The update is stored as the change request and applied once approved.
+type ChangeHeldError struct {
+	Request *models.ChangeRequest
+}
+
+func (e *ChangeHeldError) Error() string {
+	return "the change needs approval before it is applied"
+}
+
+// ErrApprovalPolicyNotFound is returned for policies that do not exist in the tenant.
+var ErrApprovalPolicyNotFound = errors.New("approval policy not found or does not belong to this tenant")
+
+// --- Approval Steps ---
+
+// userRole returns the role of a user of the tenant.
+func userRole(ctx context.Context, userID, tenantID string) (string, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", ErrRoleForbidden
+	}
+	var user models.User
+	if err := db.GetCollection("users").FindOne(ctx, bson.M{"_id": id, "tenantId": tenantID}).Decode(&user); err != nil {
+		return "", ErrRoleForbidden
+	}
+	return user.Role, nil
+}
+
+// mayDecide reports whether a user with the role may decide the approval step.
+func mayDecide(step, role string) bool {
+	return role == step || role == "admin"
+}
+
+// decideStep checks that the user may decide the step a request is waiting for, and
+// returns the decision with the status it leaves the request in. Nobody may decide their
+// own request, and each step must be decided by a different user.
+func decideStep(ctx context.Context, tenantID, userID, requestedBy string, steps []string, approvals []models.Approval, approve bool, comment string) (models.Approval, string, error) {
+	step := steps[len(approvals)]
+	role, err := userRole(ctx, userID, tenantID)
+	if err != nil {
+		return models.Approval{}, "", err
+	}
+	if !mayDecide(step, role) {
+		return models.Approval{}, "", ErrRoleForbidden
+	}
+	if requestedBy == userID {
+		return models.Approval{}, "", errors.New("you cannot decide your own request")
+	}
+	for _, approval := range approvals {
+		if approval.DecidedBy == userID {
+			return models.Approval{}, "", errors.New("you already decided an earlier step of this request")
+		}
+	}
+
+	approval := models.Approval{
+		Step:      step,
+		Decision:  ApprovalApproved,
+		DecidedBy: userID,
+		Comment:   strings.TrimSpace(comment),
+		DecidedAt: primitive.NewDateTimeFromTime(time.Now()),
+	}
+	status := ApprovalPending
+	if !approve {
+		approval.Decision = ApprovalRejected
+		status = ApprovalRejected
+	} else if len(approvals)+1 == len(steps) {
+		status = ApprovalApproved
+	}
+	return approval, status, nil
+}
+
+// --- Policies ---
+
+// ApprovalPolicyInput describes an approval policy.
+type ApprovalPolicyInput struct {
+	Name       string   `json:"name" binding:"required"`
+	EntityType string   `json:"entityType" binding:"required"`
+	Fields     []string `json:"fields"`
+	Steps      []string `json:"steps" binding:"required"`
+}
+
+func (in *ApprovalPolicyInput) validate() error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.EntityType != "employees" {
+		if _, ok := LookupEntity(in.EntityType); !ok {
+			return fmt.Errorf("unknown entity type %q", in.EntityType)
+		}
+	}
+	fields := []string{}
+	for _, field := range in.Fields {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	in.Fields = fields
+	if len(in.Steps) == 0 {
+		return errors.New("a policy needs at least one approval step")
+	}
+	for _, step := range in.Steps {
+		if !isUserRole(step) {
+			return fmt.Errorf("unknown role %q, use one of %s", step, strings.Join(UserRoles, ", "))
+		}
+	}
+	return nil
+}
+
+// CreateApprovalPolicy adds an approval policy to the tenant.
+func CreateApprovalPolicy(ctx context.Context, tenantID string, in *ApprovalPolicyInput) (*models.ApprovalPolicy, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	policy := models.ApprovalPolicy{
+		ID:         primitive.NewObjectID(),
+		TenantID:   tenantID,
+		Name:       in.Name,
+		EntityType: in.EntityType,
+		Fields:     in.Fields,
+		Steps:      in.Steps,
+		CreatedAt:  primitive.NewDateTimeFromTime(time.Now()),
+	}
+	if _, err := db.GetCollection("approval_policies").InsertOne(ctx, policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetApprovalPolicies lists the tenant's approval policies, optionally for one entity type.
+func GetApprovalPolicies(ctx context.Context, tenantID, entityType string) ([]models.ApprovalPolicy, error) {
+	query := bson.M{"tenantId": tenantID}
+	if entityType != "" {
+		query["entityType"] = entityType
+	}
+	cursor, err := db.GetCollection("approval_policies").Find(ctx, query, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	policies := []models.ApprovalPolicy{}
+	if err := cursor.All(ctx, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// UpdateApprovalPolicy replaces a policy. Pending change requests keep the steps they
+// were created with.
+func UpdateApprovalPolicy(ctx context.Context, id, tenantID string, in *ApprovalPolicyInput) (*models.ApprovalPolicy, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	var policy models.ApprovalPolicy
+	err = db.GetCollection("approval_policies").FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "tenantId": tenantID},
+		bson.M{"$set": bson.M{"name": in.Name, "entityType": in.EntityType, "fields": in.Fields, "steps": in.Steps}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&policy)
+	if err != nil {
+		return nil, ErrApprovalPolicyNotFound
+	}
+	return &policy, nil
+}
+
+// DeleteApprovalPolicy removes a policy. Pending change requests stay pending.
+func DeleteApprovalPolicy(ctx context.Context, id, tenantID string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid id format")
+	}
+	result, err := db.GetCollection("approval_policies").DeleteOne(ctx, bson.M{"_id": objID, "tenantId": tenantID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrApprovalPolicyNotFound
+	}
+	return nil
+}
+
+// --- Change Requests ---
+
+// approvalCollection returns the collection that stores an entity type.
+func approvalCollection(entityType string) (string, bool) {
+	if entityType == "employees" {
+		return "employees", true
+	}
+	def, ok := LookupEntity(entityType)
+	return def.Collection, ok
+}
+
+// sameValue reports whether a submitted value equals the stored one. References and dates
+// are compared as the stored type, since they may be submitted as strings, and a missing
+// field equals "" or the empty reference.
+func sameValue(stored, submitted interface{}) bool {
+	switch stored := stored.(type) {
+	case primitive.ObjectID:
+		id, err := ObjectIDValue(submitted)
+		return err == nil && id == stored
+	case primitive.DateTime:
+		date, err := dateValue(submitted)
+		return err == nil && date == stored
+	case nil:
+		return submitted == nil || submitted == "" || submitted == primitive.NilObjectID
+	}
+	return fmt.Sprint(stored) == fmt.Sprint(submitted)
+}
+
+// ProposeChange checks an update against the tenant's approval policies. If it changes a
+// field that a policy covers, the whole update is stored as a pending change request and
+// returned; otherwise it returns nil and the caller applies the update itself. Entities
+// that cannot be found also return nil, so the caller reports the error as usual.
+func ProposeChange(ctx context.Context, tenantID, entityType, entityID, userID string, data map[string]interface{}) (*models.ChangeRequest, error) {
+	policies, err := GetApprovalPolicies(ctx, tenantID, entityType)
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	collectionName, ok := approvalCollection(entityType)
+	if !ok {
+		return nil, nil
+	}
+	id, err := primitive.ObjectIDFromHex(entityID)
+	if err != nil {
+		return nil, nil
+	}
+
+	// 1. Work out what the update really changes.
+	var current bson.M
+	err = db.GetCollection(collectionName).FindOne(ctx, notDeleted(bson.M{"_id": id, "tenantId": tenantID})).Decode(&current)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	changes, previous := map[string]interface{}{}, map[string]interface{}{}
+	for field, value := range data {
+		if field == "_id" || field == "id" || field == "tenantId" || sameValue(current[field], value) {
+			continue
+		}
+		changes[field] = value
+		previous[field] = current[field]
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	// 2. Collect the steps of every policy the change touches.
+	request := &models.ChangeRequest{
+		ID:          primitive.NewObjectID(),
+		TenantID:    tenantID,
+		EntityType:  entityType,
+		EntityID:    id,
+		PolicyIDs:   []primitive.ObjectID{},
+		Changes:     changes,
+		Previous:    previous,
+		Status:      ApprovalPending,
+		Steps:       []string{},
+		Approvals:   []models.Approval{},
+		RequestedBy: userID,
+		RequestedAt: primitive.NewDateTimeFromTime(time.Now()),
+	}
+	for _, policy := range policies {
+		if !policyCovers(policy, changes) {
+			continue
+		}
+		request.PolicyIDs = append(request.PolicyIDs, policy.ID)
+		for _, step := range policy.Steps {
+			if !containsString(request.Steps, step) {
+				request.Steps = append(request.Steps, step)
+			}
+		}
+	}
+	if len(request.PolicyIDs) == 0 {
+		return nil, nil
+	}
+
+	if _, err := db.GetCollection("change_requests").InsertOne(ctx, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// requireApproval proposes an update and returns a *ChangeHeldError if it was held back.
+func requireApproval(ctx context.Context, tenantID, entityType, entityID, userID string, data map[string]interface{}) error {
+	request, err := ProposeChange(ctx, tenantID, entityType, entityID, userID, data)
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		return &ChangeHeldError{Request: request}
+	}
+	return nil
+}
+
+// isChangeHeld reports whether an update was held back for approval.
+func isChangeHeld(err error) bool {
+	var held *ChangeHeldError
+	return errors.As(err, &held)
+}
+
+// policyCovers reports whether a policy applies to a change.
+func policyCovers(policy models.ApprovalPolicy, changes map[string]interface{}) bool {
+	if len(policy.Fields) == 0 {
+		return true
+	}
+	for _, field := range policy.Fields {
+		if _, ok := changes[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeRequestFilter narrows the list of change requests. Empty fields match everything.
+type ChangeRequestFilter struct {
+	EntityType string
+	EntityID   string
+	Status     string
+	Step       string // Only pending requests waiting for this approval step
+}
+
+// GetChangeRequests lists the tenant's change requests, newest first.
+func GetChangeRequests(ctx context.Context, tenantID string, filter ChangeRequestFilter, page Pagination) ([]models.ChangeRequest, error) {
+	query := bson.M{"tenantId": tenantID}
+	if filter.EntityType != "" {
+		query["entityType"] = filter.EntityType
+	}
+	if filter.EntityID != "" {
+		id, err := primitive.ObjectIDFromHex(filter.EntityID)
+		if err != nil {
+			return nil, errors.New("invalid id format")
+		}
+		query["entityId"] = id
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Step != "" {
+		// The step waiting for a decision is the one after the decisions taken so far.
+		query["status"] = ApprovalPending
+		query["$expr"] = bson.M{"$eq": bson.A{
+			bson.M{"$arrayElemAt": bson.A{"$steps", bson.M{"$size": "$approvals"}}},
+			filter.Step,
+		}}
+	}
+
+	opts := page.findOptions().SetSort(bson.D{{Key: "requestedAt", Value: -1}})
+	cursor, err := db.GetCollection("change_requests").Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	requests := []models.ChangeRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetChangeRequest fetches one of the tenant's change requests.
+func GetChangeRequest(ctx context.Context, id, tenantID string) (*models.ChangeRequest, error) {
+	request, err := GetEntityByID[models.ChangeRequest](ctx, "change_requests", id, tenantID)
+	if err != nil {
+		return nil, ErrChangeRequestNotFound
+	}
+	return request, nil
+}
+
+// DecideChangeRequest approves or rejects the step a pending change is waiting for. When
+// the last step approves it, the change is applied and the request ends up applied or
+// failed.
+func DecideChangeRequest(ctx context.Context, id, tenantID, userID string, approve bool, comment string) (*models.ChangeRequest, error) {
+	request, err := GetChangeRequest(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != ApprovalPending {
+		return nil, fmt.Errorf("the change is %s and no longer waits for approval", request.Status)
+	}
+	approval, status, err := decideStep(ctx, tenantID, userID, request.RequestedBy, request.Steps, request.Approvals, approve, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Record the decision. Matching on the number of decisions makes sure two
+	// approvers cannot decide the same step at once.
+	request, err = updateChangeRequest(ctx,
+		bson.M{"_id": request.ID, "status": ApprovalPending, "approvals": bson.M{"$size": len(request.Approvals)}},
+		bson.M{"$push": bson.M{"approvals": approval}, "$set": bson.M{"status": status}})
+	if err != nil || request.Status != ApprovalApproved {
+		return request, err
+	}
+
+	// 2. Apply the change, and record the outcome.
+	outcome := bson.M{"status": ChangeApplied, "appliedAt": primitive.NewDateTimeFromTime(time.Now())}
+	if err := applyChange(ctx, request); err != nil {
+		outcome = bson.M{"status": ChangeFailed, "error": err.Error()}
+	}
+	return updateChangeRequest(ctx, bson.M{"_id": request.ID, "status": ApprovalApproved}, bson.M{"$set": outcome})
+}
+
+// applyChange writes the stored diff to the entity. The change is approved, so it skips
+// the approval policies but otherwise passes the same checks as the update endpoints. It
+// refuses if one of the fields changed since the request.
+func applyChange(ctx context.Context, request *models.ChangeRequest) error {
+	collectionName, ok := approvalCollection(request.EntityType)
+	if !ok {
+		return fmt.Errorf("unknown entity type %q", request.EntityType)
+	}
+	var current bson.M
+	if err := db.GetCollection(collectionName).FindOne(ctx, notDeleted(bson.M{"_id": request.EntityID, "tenantId": request.TenantID})).Decode(&current); err != nil {
+		return errors.New("the entity no longer exists")
+	}
+	for field, value := range request.Previous {
+		if fmt.Sprint(current[field]) != fmt.Sprint(value) {
+			return fmt.Errorf("%s was changed after the change was requested", field)
+		}
+	}
+
+	changes := bson.M(request.Changes)
+	if request.EntityType == "employees" {
+		if err := guardAccessLevel(request.EntityID.Hex(), request.TenantID, changes); err != nil {
+			return err
+		}
+		return updateEmployee(request.EntityID.Hex(), request.TenantID, changes)
+	}
+	if request.EntityType == "departments" {
+		if value, ok := changes["parentId"]; ok {
+			parentID, err := ObjectIDValue(value)
+			if err != nil {
+				return errors.New("invalid format for parentId")
+			}
+			if err := CheckDepartmentParent(ctx, request.TenantID, request.EntityID.Hex(), parentID); err != nil {
+				return err
+			}
+		}
+	}
+	return updateEntity(ctx, collectionName, request.EntityID.Hex(), request.TenantID, changes)
+}
+
+// CancelChangeRequest withdraws a pending change. Only the requester and admins may cancel.
+func CancelChangeRequest(ctx context.Context, id, tenantID, userID string) (*models.ChangeRequest, error) {
+	request, err := GetChangeRequest(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestedBy != userID {
+		if role, err := userRole(ctx, userID, tenantID); err != nil || role != "admin" {
+			return nil, ErrRoleForbidden
+		}
+	}
+	return updateChangeRequest(ctx, bson.M{"_id": request.ID, "status": ApprovalPending},
+		bson.M{"$set": bson.M{"status": ChangeCancelled}})
+}
+
+// updateChangeRequest applies update to the request matched by filter and returns the
+// result. No match means the request changed in the meantime.
+func updateChangeRequest(ctx context.Context, filter, update bson.M) (*models.ChangeRequest, error) {
+	var request models.ChangeRequest
+	err := db.GetCollection("change_requests").FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&request)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.New("the request was changed in the meantime, reload it and try again")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := settleHeldScheduledChange(ctx, &request); err != nil {
+		log.Printf("Failed to settle the scheduled change of change request %s: %v", request.ID.Hex(), err)
+	}
+	return &request, nil
+}
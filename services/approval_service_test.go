@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSameValue(t *testing.T) {
+	ref, other := primitive.NewObjectID(), primitive.NewObjectID()
+	day := primitive.NewDateTimeFromTime(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name      string
+		stored    interface{}
+		submitted interface{}
+		want      bool
+	}{
+		{"reference as hex", ref, ref.Hex(), true},
+		{"reference as ObjectID", ref, ref, true},
+		{"other reference", ref, other.Hex(), false},
+		{"empty reference", primitive.NilObjectID, "", true},
+		{"empty reference as nil", primitive.NilObjectID, nil, true},
+		{"date as string", day, "2026-03-01", true},
+		{"date as RFC3339", day, "2026-03-01T00:00:00Z", true},
+		{"date as date", day, day, true},
+		{"other date", day, "2026-03-02", false},
+		{"invalid date", day, "soon", false},
+		{"missing field", nil, "", true},
+		{"missing reference", nil, primitive.NilObjectID, true},
+		{"missing field set", nil, "Berlin", false},
+		{"same string", "Ada", "Ada", true},
+		{"other string", "Ada", "Grace", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameValue(tt.stored, tt.submitted); got != tt.want {
+				t.Fatalf("sameValue(%#v, %#v) = %v, want %v", tt.stored, tt.submitted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResubmittedEmployeeIsUnchanged(t *testing.T) {
+	ref := primitive.NewObjectID()
+	stored := bson.M{
+		"teamId":         ref,
+		"onboardingDate": primitive.NewDateTimeFromTime(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)),
+		"firstName":      "Ada",
+	}
+	// As a client sends the record back after reading it.
+	submitted := bson.M{"teamId": ref.Hex(), "onboardingDate": "2026-03-01T00:00:00Z", "firstName": "Ada"}
+	if err := normalizeEmployeeUpdate(submitted); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	for field, value := range submitted {
+		if !sameValue(stored[field], value) {
+			t.Errorf("%s counts as changed: stored %#v, submitted %#v", field, stored[field], value)
+		}
+	}
+}
@@ -0,0 +1,507 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Asset unit statuses. Units move between in_stock, in_repair and retired with
+// SetAssetUnitStatus, and in and out of assigned with AssignAssetUnit and ReturnAssetUnit.
+const (
+	AssetInStock  = "in_stock"
+	AssetAssigned = "assigned"
+	AssetInRepair = "in_repair"
+	AssetRetired  = "retired"
+)
+
+// AssetConditions are the accepted values for the condition of a unit, best first.
+var AssetConditions = []string{"new", "good", "fair", "poor", "broken"}
+
+// DefaultLowStockDays is how far ahead the low-stock report looks for onboardings.
+const DefaultLowStockDays = 30
+
+// EnsureAssetIndexes creates the indexes of the inventory collections. Serial numbers are
+// unique per tenant.
+func EnsureAssetIndexes(ctx context.Context) error {
+	_, err := db.GetCollection("asset_units").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "serialNumber", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "assignedTo", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.GetCollection("asset_assignments").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "unitId", Value: 1}, {Key: "assignedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "employeeId", Value: 1}, {Key: "assignedAt", Value: -1}}},
+	})
+	return err
+}
+
+// --- Asset Units ---
+
+// AssetUnitInput holds the fields that describe a unit. The status is changed through
+// assignments, returns and SetAssetUnitStatus instead.
+type AssetUnitInput struct {
+	ModelID      string     `json:"modelId" binding:"required"`
+	SerialNumber string     `json:"serialNumber" binding:"required"`
+	PurchaseDate *time.Time `json:"purchaseDate"`
+	Condition    string     `json:"condition"` // Defaults to "new"
+	Notes        string     `json:"notes"`
+}
+
+// validate normalizes the input and checks that the model belongs to the tenant.
+func (in *AssetUnitInput) validate(ctx context.Context, tenantID string) (primitive.ObjectID, error) {
+	in.SerialNumber = strings.TrimSpace(in.SerialNumber)
+	if in.SerialNumber == "" {
+		return primitive.NilObjectID, errors.New("serialNumber is required")
+	}
+	if in.Condition == "" {
+		in.Condition = "new"
+	}
+	if !containsString(AssetConditions, in.Condition) {
+		return primitive.NilObjectID, fmt.Errorf("unknown condition %q, use one of %s", in.Condition, strings.Join(AssetConditions, ", "))
+	}
+	model, err := GetEntityByID[models.HardwareAsset](ctx, "hardware_assets", in.ModelID, tenantID)
+	if err != nil {
+		return primitive.NilObjectID, errors.New("hardware model not found or does not belong to this tenant")
+	}
+	return model.ID, nil
+}
+
+// AssetUnitFilter narrows the list of units. Empty fields match everything.
+type AssetUnitFilter struct {
+	ModelID    string
+	Status     string
+	EmployeeID string
+}
+
+// CreateAssetUnit adds a unit to the tenant's stock.
+func CreateAssetUnit(ctx context.Context, tenantID string, in *AssetUnitInput) (*models.AssetUnit, error) {
+	modelID, err := in.validate(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	unit := models.AssetUnit{
+		ID:           primitive.NewObjectID(),
+		TenantID:     tenantID,
+		ModelID:      modelID,
+		SerialNumber: in.SerialNumber,
+		Condition:    in.Condition,
+		Status:       AssetInStock,
+		Notes:        in.Notes,
+		CreatedAt:    primitive.NewDateTimeFromTime(time.Now()),
+	}
+	if in.PurchaseDate != nil {
+		unit.PurchaseDate = primitive.NewDateTimeFromTime(*in.PurchaseDate)
+	}
+	if _, err := db.GetCollection("asset_units").InsertOne(ctx, unit); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("a unit with serial number %q already exists", in.SerialNumber)
+		}
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// GetAssetUnits lists the tenant's units ordered by serial number.
+func GetAssetUnits(ctx context.Context, tenantID string, filter AssetUnitFilter, page Pagination) ([]models.AssetUnit, error) {
+	query := bson.M{"tenantId": tenantID}
+	for field, value := range map[string]string{"modelId": filter.ModelID, "assignedTo": filter.EmployeeID} {
+		if value == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil, errors.New("invalid id format")
+		}
+		query[field] = id
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := page.findOptions().SetSort(bson.D{{Key: "serialNumber", Value: 1}})
+	cursor, err := db.GetCollection("asset_units").Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	units := []models.AssetUnit{}
+	if err := cursor.All(ctx, &units); err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// GetAssetUnit fetches one of the tenant's units.
+func GetAssetUnit(ctx context.Context, id, tenantID string) (*models.AssetUnit, error) {
+	unit, err := GetEntityByID[models.AssetUnit](ctx, "asset_units", id, tenantID)
+	if err != nil {
+		return nil, errors.New("asset unit not found or does not belong to this tenant")
+	}
+	return unit, nil
+}
+
+// UpdateAssetUnit replaces the description of a unit.
+func UpdateAssetUnit(ctx context.Context, id, tenantID string, in *AssetUnitInput) error {
+	modelID, err := in.validate(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	set := bson.M{
+		"modelId":      modelID,
+		"serialNumber": in.SerialNumber,
+		"condition":    in.Condition,
+		"notes":        in.Notes,
+	}
+	update := bson.M{"$set": set}
+	if in.PurchaseDate != nil {
+		set["purchaseDate"] = primitive.NewDateTimeFromTime(*in.PurchaseDate)
+	} else {
+		update["$unset"] = bson.M{"purchaseDate": ""}
+	}
+
+	unitID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid id format")
+	}
+	result, err := db.GetCollection("asset_units").UpdateOne(ctx, bson.M{"_id": unitID, "tenantId": tenantID}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("a unit with serial number %q already exists", in.SerialNumber)
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("asset unit not found or does not belong to this tenant")
+	}
+	return nil
+}
+
+// DeleteAssetUnit removes a unit that was entered by mistake. Units that left the company
+// should be retired instead, which keeps them in the reports. Assigned units cannot be deleted.
+func DeleteAssetUnit(ctx context.Context, id, tenantID string) error {
+	unit, err := GetAssetUnit(ctx, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if unit.Status == AssetAssigned {
+		return errors.New("the unit is assigned to an employee, return it first")
+	}
+	result, err := db.GetCollection("asset_units").DeleteOne(ctx, bson.M{"_id": unit.ID, "status": bson.M{"$ne": AssetAssigned}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("the unit was assigned in the meantime, return it first")
+	}
+	return nil
+}
+
+// SetAssetUnitStatus moves an unassigned unit to in_stock, in_repair or retired.
+func SetAssetUnitStatus(ctx context.Context, id, tenantID, status string) error {
+	if status != AssetInStock && status != AssetInRepair && status != AssetRetired {
+		return fmt.Errorf("status must be %s, %s or %s; use assign and return for assignments", AssetInStock, AssetInRepair, AssetRetired)
+	}
+	unit, err := GetAssetUnit(ctx, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if unit.Status == AssetAssigned {
+		return errors.New("the unit is assigned to an employee, return it first")
+	}
+	result, err := db.GetCollection("asset_units").UpdateOne(ctx,
+		bson.M{"_id": unit.ID, "status": bson.M{"$ne": AssetAssigned}},
+		bson.M{"$set": bson.M{"status": status}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("the unit was assigned in the meantime, return it first")
+	}
+	return nil
+}
+
+// --- Assignments ---
+
+// AssignAssetUnit hands an in-stock unit to an employee and records the assignment.
+// An employee may hold any number of units.
+func AssignAssetUnit(ctx context.Context, unitID, tenantID, employeeID, userID, notes string) (*models.AssetAssignment, error) {
+	employee, err := GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return nil, errors.New("employee not found or does not belong to this tenant")
+	}
+	unit, err := GetAssetUnit(ctx, unitID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if unit.Status != AssetInStock {
+		return nil, fmt.Errorf("only units in stock can be assigned, this one is %s", unit.Status)
+	}
+
+	// 1. Claim the unit. The status condition makes sure two assignments cannot both win.
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := db.GetCollection("asset_units").UpdateOne(ctx,
+		bson.M{"_id": unit.ID, "status": AssetInStock},
+		bson.M{"$set": bson.M{"status": AssetAssigned, "assignedTo": employee.ID, "assignedAt": now}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.New("the unit was assigned in the meantime")
+	}
+
+	// 2. Record the assignment, releasing the unit again if that fails.
+	assignment := models.AssetAssignment{
+		ID:              primitive.NewObjectID(),
+		TenantID:        tenantID,
+		UnitID:          unit.ID,
+		ModelID:         unit.ModelID,
+		SerialNumber:    unit.SerialNumber,
+		EmployeeID:      employee.ID,
+		AssignedBy:      userID,
+		AssignedAt:      now,
+		AssignCondition: unit.Condition,
+		Notes:           notes,
+	}
+	if _, err := db.GetCollection("asset_assignments").InsertOne(ctx, assignment); err != nil {
+		db.GetCollection("asset_units").UpdateOne(ctx, bson.M{"_id": unit.ID},
+			bson.M{"$set": bson.M{"status": AssetInStock}, "$unset": bson.M{"assignedTo": "", "assignedAt": ""}})
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// AssetReturn describes a unit coming back from an employee.
+type AssetReturn struct {
+	Condition string `json:"condition"` // Condition on return; defaults to the unit's current condition
+	Status    string `json:"status"`    // "in_stock" (default), "in_repair" or "retired"
+	Notes     string `json:"notes"`
+}
+
+// ReturnAssetUnit takes an assigned unit back and closes its open assignment.
+func ReturnAssetUnit(ctx context.Context, unitID, tenantID, userID string, in *AssetReturn) (*models.AssetAssignment, error) {
+	unit, err := GetAssetUnit(ctx, unitID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if unit.Status != AssetAssigned {
+		return nil, errors.New("the unit is not assigned")
+	}
+	if in.Condition == "" {
+		in.Condition = unit.Condition
+	}
+	if !containsString(AssetConditions, in.Condition) {
+		return nil, fmt.Errorf("unknown condition %q, use one of %s", in.Condition, strings.Join(AssetConditions, ", "))
+	}
+	if in.Status == "" {
+		in.Status = AssetInStock
+	}
+	if in.Status != AssetInStock && in.Status != AssetInRepair && in.Status != AssetRetired {
+		return nil, fmt.Errorf("status must be %s, %s or %s", AssetInStock, AssetInRepair, AssetRetired)
+	}
+
+	// 1. Release the unit, unless someone else returned it first.
+	result, err := db.GetCollection("asset_units").UpdateOne(ctx,
+		bson.M{"_id": unit.ID, "status": AssetAssigned, "assignedTo": unit.AssignedTo},
+		bson.M{
+			"$set":   bson.M{"status": in.Status, "condition": in.Condition},
+			"$unset": bson.M{"assignedTo": "", "assignedAt": ""},
+		})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.New("the unit was returned in the meantime")
+	}
+
+	// 2. Close the open assignment.
+	set := bson.M{
+		"returnedBy":      userID,
+		"returnedAt":      primitive.NewDateTimeFromTime(time.Now()),
+		"returnCondition": in.Condition,
+	}
+	if in.Notes != "" {
+		set["notes"] = in.Notes
+	}
+	var assignment models.AssetAssignment
+	err = db.GetCollection("asset_assignments").FindOneAndUpdate(ctx,
+		bson.M{"unitId": unit.ID, "employeeId": unit.AssignedTo, "returnedAt": bson.M{"$exists": false}},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&assignment)
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// GetAssetUnitAssignments lists the assignment history of a unit, newest first.
+func GetAssetUnitAssignments(ctx context.Context, unitID, tenantID string) ([]models.AssetAssignment, error) {
+	unit, err := GetAssetUnit(ctx, unitID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return findAssetAssignments(ctx, bson.M{"tenantId": tenantID, "unitId": unit.ID})
+}
+
+// GetEmployeeAssetAssignments lists the units an employee has held, current ones included,
+// newest first.
+func GetEmployeeAssetAssignments(ctx context.Context, employeeID, tenantID string) ([]models.AssetAssignment, error) {
+	objID, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	return findAssetAssignments(ctx, bson.M{"tenantId": tenantID, "employeeId": objID})
+}
+
+func findAssetAssignments(ctx context.Context, filter bson.M) ([]models.AssetAssignment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "assignedAt", Value: -1}})
+	cursor, err := db.GetCollection("asset_assignments").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	assignments := []models.AssetAssignment{}
+	if err := cursor.All(ctx, &assignments); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// --- Reports ---
+
+// LowStockItem is one hardware model in the low-stock report.
+type LowStockItem struct {
+	ModelID     primitive.ObjectID `json:"modelId"`
+	Name        string             `json:"name"`
+	ModelNumber string             `json:"modelNumber"`
+	InStock     int                `json:"inStock"`
+	Assigned    int                `json:"assigned"`
+	InRepair    int                `json:"inRepair"`
+	// Demand counts the employees starting within the report window who need the model
+	// (their hardwareAssetId) and do not hold a unit of it yet.
+	Demand    int  `json:"demand"`
+	Shortfall int  `json:"shortfall"` // Units to buy or repair to cover the demand
+	Low       bool `json:"low"`
+}
+
+// GetLowStockReport compares the units in stock with the needs of the employees starting
+// in the next days days. Models with a shortfall come first, the largest first.
+func GetLowStockReport(ctx context.Context, tenantID string, days int) ([]LowStockItem, error) {
+	if days <= 0 {
+		days = DefaultLowStockDays
+	}
+	hardwareModels, err := GetEntitiesByTenant[models.HardwareAsset](ctx, "hardware_assets", tenantID, Pagination{})
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[primitive.ObjectID]*LowStockItem, len(hardwareModels))
+	for _, model := range hardwareModels {
+		items[model.ID] = &LowStockItem{ModelID: model.ID, Name: model.Name, ModelNumber: model.ModelNumber}
+	}
+
+	// 1. Count the units of every model by status.
+	cursor, err := db.GetCollection("asset_units").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"tenantId": tenantID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"modelId": "$modelId", "status": "$status"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		ID struct {
+			ModelID primitive.ObjectID `bson:"modelId"`
+			Status  string             `bson:"status"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		item, ok := items[count.ID.ModelID]
+		if !ok {
+			continue
+		}
+		switch count.ID.Status {
+		case AssetInStock:
+			item.InStock = count.Count
+		case AssetAssigned:
+			item.Assigned = count.Count
+		case AssetInRepair:
+			item.InRepair = count.Count
+		}
+	}
+
+	// 2. Count the upcoming employees who still need their model.
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	cursor, err = db.GetCollection("employees").Find(ctx, notDeleted(bson.M{
+		"tenantId":        tenantID,
+		"hardwareAssetId": bson.M{"$ne": primitive.NilObjectID},
+		"onboardingDate": bson.M{
+			"$gte": primitive.NewDateTimeFromTime(today),
+			"$lt":  primitive.NewDateTimeFromTime(today.AddDate(0, 0, days+1)),
+		},
+	}), options.Find().SetProjection(bson.M{"hardwareAssetId": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var employees []models.Employee
+	if err := cursor.All(ctx, &employees); err != nil {
+		return nil, err
+	}
+	if len(employees) > 0 {
+		ids := make([]primitive.ObjectID, len(employees))
+		for i, employee := range employees {
+			ids[i] = employee.ID
+		}
+		cursor, err = db.GetCollection("asset_units").Find(ctx,
+			bson.M{"tenantId": tenantID, "status": AssetAssigned, "assignedTo": bson.M{"$in": ids}},
+			options.Find().SetProjection(bson.M{"modelId": 1, "assignedTo": 1}))
+		if err != nil {
+			return nil, err
+		}
+		var held []models.AssetUnit
+		if err := cursor.All(ctx, &held); err != nil {
+			return nil, err
+		}
+		equipped := map[[2]primitive.ObjectID]bool{}
+		for _, unit := range held {
+			equipped[[2]primitive.ObjectID{unit.AssignedTo, unit.ModelID}] = true
+		}
+		for _, employee := range employees {
+			if item, ok := items[employee.HardwareAssetID]; ok && !equipped[[2]primitive.ObjectID{employee.ID, employee.HardwareAssetID}] {
+				item.Demand++
+			}
+		}
+	}
+
+	// 3. Work out the shortfall and sort.
+	report := make([]LowStockItem, 0, len(items))
+	for _, item := range items {
+		if item.Demand > item.InStock {
+			item.Shortfall = item.Demand - item.InStock
+			item.Low = true
+		}
+		report = append(report, *item)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Shortfall != report[j].Shortfall {
+			return report[i].Shortfall > report[j].Shortfall
+		}
+		return report[i].Name < report[j].Name
+	})
+	return report, nil
+}
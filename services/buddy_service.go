@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultBuddyCapacity is the number of new hires a buddy looks after at once unless the
+// buddy's maxBuddies says otherwise.
+const DefaultBuddyCapacity = 2
+
+// Scores used to rank buddy suggestions. Sharing a team matters most; a buddy's score then
+// drops with every new hire they already look after.
+const (
+	buddyScoreTeam       = 40
+	buddyScoreDepartment = 20
+	buddyScoreLocation   = 10
+	buddyScorePerLoad    = 15
+)
+
+// buddyCapacity returns how many new hires the buddy may look after at once.
+func buddyCapacity(buddy *models.OnboardingBuddy) int {
+	if buddy.MaxBuddies > 0 {
+		return buddy.MaxBuddies
+	}
+	return DefaultBuddyCapacity
+}
+
+// openBuddyAssignments matches the assignments that still count towards a buddy's load.
+func openBuddyAssignments(tenantID string, now time.Time) bson.M {
+	return bson.M{
+		"tenantId": tenantID,
+		"endedAt":  bson.M{"$exists": false},
+		"endsAt":   bson.M{"$gt": primitive.NewDateTimeFromTime(now)},
+	}
+}
+
+// buddyLoads counts the open assignments of every buddy of the tenant.
+func buddyLoads(ctx context.Context, tenantID string) (map[primitive.ObjectID]int, error) {
+	cursor, err := db.GetCollection("buddy_assignments").Aggregate(ctx, []bson.M{
+		{"$match": openBuddyAssignments(tenantID, time.Now())},
+		{"$group": bson.M{"_id": "$buddyId", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		BuddyID primitive.ObjectID `bson:"_id"`
+		Count   int                `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	loads := make(map[primitive.ObjectID]int, len(counts))
+	for _, count := range counts {
+		loads[count.BuddyID] = count.Count
+	}
+	return loads, nil
+}
+
+// --- Load and Suggestions ---
+
+// BuddyLoad is a buddy with the number of new hires they currently look after.
+type BuddyLoad struct {
+	Buddy    models.OnboardingBuddy `json:"buddy"`
+	Load     int                    `json:"load"`
+	Capacity int                    `json:"capacity"`
+}
+
+// GetBuddyLoads lists every buddy of the tenant with their current load.
+func GetBuddyLoads(ctx context.Context, tenantID string) ([]BuddyLoad, error) {
+	buddies, err := GetEntitiesByTenant[models.OnboardingBuddy](ctx, "onboarding_buddies", tenantID, Pagination{})
+	if err != nil {
+		return nil, err
+	}
+	loads, err := buddyLoads(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]BuddyLoad, len(buddies))
+	for i := range buddies {
+		result[i] = BuddyLoad{Buddy: buddies[i], Load: loads[buddies[i].ID], Capacity: buddyCapacity(&buddies[i])}
+	}
+	return result, nil
+}
+
+// BuddySuggestion is a buddy with spare capacity, ranked for one new hire.
+type BuddySuggestion struct {
+	BuddyLoad
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"` // e.g. "same team", "looks after 1 of 2"
+}
+
+// SuggestBuddies ranks the buddies with spare capacity for a new hire, best match first.
+// Buddies score for sharing the new hire's team, department and location, and lose points
+// for every new hire they already look after. The team of a buddy linked to an employee
+// comes from the employee record, otherwise from the buddy's teamId.
+func SuggestBuddies(ctx context.Context, employeeID, tenantID string, limit int) ([]BuddySuggestion, error) {
+	employee, err := GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return nil, errors.New("employee not found or does not belong to this tenant")
+	}
+	candidates, err := GetBuddyLoads(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Load the employee records of the linked buddies in one go.
+	var linked []primitive.ObjectID
+	for _, candidate := range candidates {
+		if !candidate.Buddy.EmployeeID.IsZero() {
+			linked = append(linked, candidate.Buddy.EmployeeID)
+		}
+	}
+	profiles := map[primitive.ObjectID]models.Employee{}
+	if len(linked) > 0 {
+		cursor, err := db.GetCollection("employees").Find(ctx, notDeleted(bson.M{"tenantId": tenantID, "_id": bson.M{"$in": linked}}))
+		if err != nil {
+			return nil, err
+		}
+		var employees []models.Employee
+		if err := cursor.All(ctx, &employees); err != nil {
+			return nil, err
+		}
+		for _, e := range employees {
+			profiles[e.ID] = e
+		}
+	}
+
+	// 2. Score every buddy with spare capacity.
+	suggestions := []BuddySuggestion{}
+	for _, candidate := range candidates {
+		buddy := candidate.Buddy
+		if buddy.EmployeeID == employee.ID || candidate.Load >= candidate.Capacity {
+			continue
+		}
+		suggestion := BuddySuggestion{BuddyLoad: candidate, Reasons: []string{}}
+		teamID := buddy.TeamID
+		profile, hasProfile := profiles[buddy.EmployeeID]
+		if hasProfile && !profile.TeamID.IsZero() {
+			teamID = profile.TeamID
+		}
+		if !teamID.IsZero() && teamID == employee.TeamID {
+			suggestion.Score += buddyScoreTeam
+			suggestion.Reasons = append(suggestion.Reasons, "same team")
+		}
+		if hasProfile && !profile.DepartmentID.IsZero() && profile.DepartmentID == employee.DepartmentID {
+			suggestion.Score += buddyScoreDepartment
+			suggestion.Reasons = append(suggestion.Reasons, "same department")
+		}
+		if hasProfile && !profile.LocationID.IsZero() && profile.LocationID == employee.LocationID {
+			suggestion.Score += buddyScoreLocation
+			suggestion.Reasons = append(suggestion.Reasons, "same location")
+		}
+		suggestion.Score -= buddyScorePerLoad * candidate.Load
+		suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("looks after %d of %d", candidate.Load, candidate.Capacity))
+		suggestions = append(suggestions, suggestion)
+	}
+
+	// 3. Best score first; ties go to the buddy with the most spare capacity.
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Capacity-suggestions[i].Load > suggestions[j].Capacity-suggestions[j].Load
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// --- Assignments ---
+
+// AssignBuddy makes a buddy look after a new hire and records the assignment. A buddy at
+// capacity is only assigned with force. A change of buddy that an approval policy covers is
+// returned as a *ChangeHeldError.
+func AssignBuddy(ctx context.Context, employeeID, tenantID, buddyID, userID string, force bool) (*models.BuddyAssignment, error) {
+	employee, err := GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return nil, errors.New("employee not found or does not belong to this tenant")
+	}
+	buddy, err := GetEntityByID[models.OnboardingBuddy](ctx, "onboarding_buddies", buddyID, tenantID)
+	if err != nil {
+		return nil, errors.New("buddy not found or does not belong to this tenant")
+	}
+	if buddy.EmployeeID == employee.ID {
+		return nil, errors.New("an employee cannot be their own buddy")
+	}
+
+	if !force && employee.OnboardingBuddyID != buddy.ID {
+		loads, err := buddyLoads(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if loads[buddy.ID] >= buddyCapacity(buddy) {
+			return nil, fmt.Errorf("%s already looks after %d new hires, the maximum", buddy.Name, loads[buddy.ID])
+		}
+	}
+
+	// A change of buddy that needs approval is held back before the assignment opens; the
+	// employee.updated event opens it once the change is applied.
+	update := bson.M{"onboardingBuddyId": buddy.ID}
+	if err := requireApproval(ctx, tenantID, "employees", employeeID, userID, update); err != nil {
+		return nil, err
+	}
+	assignment, err := startBuddyAssignment(ctx, employee, buddy.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// The employee.updated event finds the assignment already open and leaves it alone.
+	if employee.OnboardingBuddyID != buddy.ID {
+		if err := updateEmployee(employeeID, tenantID, update); err != nil {
+			return nil, err
+		}
+	}
+	return assignment, nil
+}
+
+// startBuddyAssignment opens an assignment of the buddy to the employee and ends any other
+// open assignment of the employee. It returns the existing assignment if one is open already.
+func startBuddyAssignment(ctx context.Context, employee *models.Employee, buddyID primitive.ObjectID, assignedBy string) (*models.BuddyAssignment, error) {
+	collection := db.GetCollection("buddy_assignments")
+	now := time.Now()
+
+	var existing models.BuddyAssignment
+	filter := bson.M{"tenantId": employee.TenantID, "employeeId": employee.ID, "buddyId": buddyID, "endedAt": bson.M{"$exists": false}}
+	if err := collection.FindOne(ctx, filter).Decode(&existing); err == nil {
+		return &existing, nil
+	}
+	if err := endBuddyAssignments(ctx, bson.M{"tenantId": employee.TenantID, "employeeId": employee.ID}); err != nil {
+		return nil, err
+	}
+
+	// The buddy period runs from the onboarding date, or from now for late assignments.
+	start := employee.OnboardingDate.Time()
+	if start.Before(now) {
+		start = now
+	}
+	assignment := models.BuddyAssignment{
+		ID:         primitive.NewObjectID(),
+		TenantID:   employee.TenantID,
+		BuddyID:    buddyID,
+		EmployeeID: employee.ID,
+		AssignedBy: assignedBy,
+		AssignedAt: primitive.NewDateTimeFromTime(now),
+		EndsAt:     primitive.NewDateTimeFromTime(start.AddDate(0, 0, config.AppConfig.BuddyPeriodDays)),
+	}
+	if _, err := collection.InsertOne(ctx, assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// endBuddyAssignments ends the matching assignments that have not ended yet.
+func endBuddyAssignments(ctx context.Context, filter bson.M) error {
+	filter["endedAt"] = bson.M{"$exists": false}
+	_, err := db.GetCollection("buddy_assignments").UpdateMany(ctx, filter,
+		bson.M{"$set": bson.M{"endedAt": primitive.NewDateTimeFromTime(time.Now())}})
+	return err
+}
+
+// syncBuddyAssignments keeps the assignment history in line with the employees'
+// onboardingBuddyId when it is set through the employee endpoints or an import, and ends
+// the assignments of deleted employees and buddies. It is subscribed to employee.created,
+// employee.updated, employee.deleted and entity.deleted.
+func syncBuddyAssignments(ctx context.Context, env events.Envelope) error {
+	switch e := env.Event.(type) {
+	case events.EmployeeCreated:
+		if e.Employee.OnboardingBuddyID.IsZero() {
+			return nil
+		}
+		_, err := startBuddyAssignment(ctx, &e.Employee, e.Employee.OnboardingBuddyID, "")
+		return err
+	case events.EmployeeUpdated:
+		if _, ok := e.Changes["onboardingBuddyId"]; !ok {
+			return nil
+		}
+		employee, err := GetEmployeeByID(e.EmployeeID, e.TenantID)
+		if err != nil {
+			return nil // Deleted in the meantime
+		}
+		if employee.OnboardingBuddyID.IsZero() {
+			return endBuddyAssignments(ctx, bson.M{"tenantId": e.TenantID, "employeeId": employee.ID})
+		}
+		_, err = startBuddyAssignment(ctx, employee, employee.OnboardingBuddyID, "")
+		return err
+	case events.EmployeeDeleted:
+		return endBuddyAssignments(ctx, bson.M{"tenantId": e.Employee.TenantID, "employeeId": e.Employee.ID})
+	case events.EntityDeleted:
+		buddyID, err := primitive.ObjectIDFromHex(e.EntityID)
+		if e.EntityType != "onboarding-buddy" || err != nil {
+			return nil
+		}
+		return endBuddyAssignments(ctx, bson.M{"tenantId": e.TenantID, "buddyId": buddyID})
+	}
+	return nil
+}
+
+// BuddyAssignmentFilter narrows the assignment history. Empty fields match everything.
+type BuddyAssignmentFilter struct {
+	BuddyID    string
+	EmployeeID string
+	OpenOnly   bool
+}
+
+// GetBuddyAssignments lists the tenant's buddy assignments, newest first.
+func GetBuddyAssignments(ctx context.Context, tenantID string, filter BuddyAssignmentFilter, page Pagination) ([]models.BuddyAssignment, error) {
+	query := bson.M{"tenantId": tenantID}
+	if filter.OpenOnly {
+		query = openBuddyAssignments(tenantID, time.Now())
+	}
+	for field, value := range map[string]string{"buddyId": filter.BuddyID, "employeeId": filter.EmployeeID} {
+		if value == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil, errors.New("invalid id format")
+		}
+		query[field] = id
+	}
+
+	opts := page.findOptions().SetSort(bson.D{{Key: "assignedAt", Value: -1}})
+	cursor, err := db.GetCollection("buddy_assignments").Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	assignments := []models.BuddyAssignment{}
+	if err := cursor.All(ctx, &assignments); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// --- Feedback ---
+
+// BuddyFeedbackInput is a rating of a buddy assignment.
+type BuddyFeedbackInput struct {
+	Rating  int    `json:"rating" binding:"required"`
+	Comment string `json:"comment"`
+}
+
+func (in *BuddyFeedbackInput) validate() error {
+	if in.Rating < 1 || in.Rating > 5 {
+		return errors.New("rating must be between 1 and 5")
+	}
+	in.Comment = strings.TrimSpace(in.Comment)
+	return nil
+}
+
+// AddBuddyFeedback records an admin's feedback on an assignment.
+func AddBuddyFeedback(ctx context.Context, assignmentID, tenantID, userID string, in *BuddyFeedbackInput) (*models.BuddyAssignment, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(assignmentID)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	return addBuddyFeedback(ctx, bson.M{"_id": objID, "tenantId": tenantID}, models.BuddyFeedback{
+		From: "admin", Rating: in.Rating, Comment: in.Comment, GivenBy: userID,
+	})
+}
+
+// AddPortalBuddyFeedback records a new hire's feedback on their current or latest buddy.
+func AddPortalBuddyFeedback(ctx context.Context, employeeID, tenantID string, in *BuddyFeedbackInput) (*models.BuddyAssignment, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	assignments, err := GetBuddyAssignments(ctx, tenantID, BuddyAssignmentFilter{EmployeeID: employeeID}, Pagination{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return nil, errors.New("no buddy has been assigned to you yet")
+	}
+	return addBuddyFeedback(ctx, bson.M{"_id": assignments[0].ID}, models.BuddyFeedback{
+		From: "new_hire", Rating: in.Rating, Comment: in.Comment, GivenBy: "portal",
+	})
+}
+
+func addBuddyFeedback(ctx context.Context, filter bson.M, feedback models.BuddyFeedback) (*models.BuddyAssignment, error) {
+	feedback.GivenAt = primitive.NewDateTimeFromTime(time.Now())
+	var assignment models.BuddyAssignment
+	err := db.GetCollection("buddy_assignments").FindOneAndUpdate(ctx, filter,
+		bson.M{"$push": bson.M{"feedback": feedback}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&assignment)
+	if err != nil {
+		return nil, errors.New("buddy assignment not found or does not belong to this tenant")
+	}
+	return &assignment, nil
+}
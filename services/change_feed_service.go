@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// changeFeedPollInterval is how often the polling change feed checks for new events.
+const changeFeedPollInterval = 2 * time.Second
+
+var (
+	// ErrChangeFeedUnavailable is returned when neither change streams nor a persisted
+	// event log are available to follow changes.
+	ErrChangeFeedUnavailable = errors.New("real-time updates need a MongoDB replica set or EVENT_STORE=mongo")
+	// ErrInvalidResumeToken is returned when a client tries to resume from a position
+	// that is malformed or no longer available. The client should reload and start over.
+	ErrInvalidResumeToken = errors.New("the resume position is invalid or has expired")
+)
+
+// ChangeNotification tells a client that an employee or entity was created, updated, deleted
+// or restored from the trash.
+type ChangeNotification struct {
+	ID         string      `json:"-"`          // Position of the change, sent as the SSE event ID
+	Operation  string      `json:"operation"`  // "created", "updated", "deleted" or "restored"
+	EntityType string      `json:"entityType"` // "employees" or an entity slug
+	EntityID   string      `json:"entityId"`
+	Document   interface{} `json:"document,omitempty"` // The document after the change, when known
+	OccurredAt time.Time   `json:"occurredAt"`
+}
+
+// ChangeFeed streams the changes of one tenant.
+type ChangeFeed interface {
+	// Watch calls send for every change after resumeAfter (or from now, if it is empty)
+	// until ctx is cancelled or send returns an error.
+	Watch(ctx context.Context, tenantID, resumeAfter string, send func(ChangeNotification) error) error
+}
+
+// activeChangeFeed is the feed chosen by InitChangeFeed. It is nil when none is available.
+var activeChangeFeed ChangeFeed
+
+// InitChangeFeed picks the change feed for this deployment: MongoDB change streams on a
+// replica set or sharded cluster, otherwise polling of the persisted event log.
+// store may be nil if events are not persisted.
+func InitChangeFeed(ctx context.Context, store *events.MongoStore) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := db.MongoClient.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	switch {
+	case err == nil && (hello.SetName != "" || hello.Msg == "isdbgrid"):
+		enablePreImages(ctx)
+		activeChangeFeed = changeStreamFeed{}
+		log.Println("Real-time updates use MongoDB change streams")
+	case store != nil:
+		activeChangeFeed = pollingChangeFeed{store: store}
+		log.Println("Real-time updates poll the event store (no replica set found)")
+	default:
+		log.Printf("Real-time updates are disabled: %v", ErrChangeFeedUnavailable)
+	}
+}
+
+// WatchChanges streams the changes of a tenant using the active change feed.
+func WatchChanges(ctx context.Context, tenantID, resumeAfter string, send func(ChangeNotification) error) error {
+	if activeChangeFeed == nil {
+		return ErrChangeFeedUnavailable
+	}
+	return activeChangeFeed.Watch(ctx, tenantID, resumeAfter, send)
+}
+
+// watchedCollections maps every collection with real-time updates to its entity type.
+func watchedCollections() map[string]string {
+	collections := map[string]string{"employees": "employees"}
+	for _, def := range EntityDefinitions {
+		collections[def.Collection] = def.Slug
+	}
+	return collections
+}
+
+// --- Change Streams ---
+
+// changeStreamFeed follows MongoDB change streams. The SSE event ID is the change stream's
+// resume token, so a reconnecting client continues exactly where it stopped.
+type changeStreamFeed struct{}
+
+// enablePreImages asks MongoDB (6.0+) to keep documents as they were before a change.
+// Deletes only carry the deleted document's ID; without the pre-image the tenant of a
+// deleted document is unknown and the delete cannot be streamed.
+func enablePreImages(ctx context.Context) {
+	database := db.MongoClient.Database(config.AppConfig.DatabaseName)
+	for collection := range watchedCollections() {
+		command := bson.D{
+			{Key: "collMod", Value: collection},
+			{Key: "changeStreamPreAndPostImages", Value: bson.M{"enabled": true}},
+		}
+		if err := database.RunCommand(ctx, command).Err(); err != nil {
+			log.Printf("Could not enable pre-images on %s, deletes will not be streamed: %v", collection, err)
+		}
+	}
+}
+
+func (changeStreamFeed) Watch(ctx context.Context, tenantID, resumeAfter string, send func(ChangeNotification) error) error {
+	collections := watchedCollections()
+	names := make([]string, 0, len(collections))
+	for name := range collections {
+		names = append(names, name)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": []string{"insert", "update", "replace", "delete"}},
+		"ns.coll":       bson.M{"$in": names},
+		"$or": []bson.M{
+			{"fullDocument.tenantId": tenantID},
+			{"fullDocumentBeforeChange.tenantId": tenantID},
+		},
+	}}}}
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+	if resumeAfter != "" {
+		opts.SetResumeAfter(bson.M{"_data": resumeAfter})
+	}
+
+	stream, err := db.MongoClient.Database(config.AppConfig.DatabaseName).Watch(ctx, pipeline, opts)
+	if err != nil {
+		if resumeAfter != "" && isResumeError(err) {
+			return ErrInvalidResumeToken
+		}
+		return err
+	}
+	defer stream.Close(ctx)
+
+	operations := map[string]string{"insert": "created", "update": "updated", "replace": "updated", "delete": "deleted"}
+	for stream.Next(ctx) {
+		var change struct {
+			ID struct {
+				Data string `bson:"_data"`
+			} `bson:"_id"`
+			OperationType string `bson:"operationType"`
+			Ns            struct {
+				Coll string `bson:"coll"`
+			} `bson:"ns"`
+			DocumentKey struct {
+				ID primitive.ObjectID `bson:"_id"`
+			} `bson:"documentKey"`
+			UpdateDescription struct {
+				UpdatedFields bson.M   `bson:"updatedFields"`
+				RemovedFields []string `bson:"removedFields"`
+			} `bson:"updateDescription"`
+			FullDocument bson.M              `bson:"fullDocument"`
+			ClusterTime  primitive.Timestamp `bson:"clusterTime"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+
+		notification := ChangeNotification{
+			ID:         change.ID.Data,
+			Operation:  operations[change.OperationType],
+			EntityType: collections[change.Ns.Coll],
+			EntityID:   change.DocumentKey.ID.Hex(),
+			OccurredAt: time.Unix(int64(change.ClusterTime.T), 0).UTC(),
+		}
+		// Deleting and restoring are updates of deletedAt.
+		if _, ok := change.UpdateDescription.UpdatedFields["deletedAt"]; ok {
+			notification.Operation = "deleted"
+		} else if containsString(change.UpdateDescription.RemovedFields, "deletedAt") {
+			notification.Operation = "restored"
+		}
+		if change.FullDocument != nil {
+			notification.Document = change.FullDocument
+		}
+		if err := send(notification); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return stream.Err()
+}
+
+// isResumeError reports whether a change stream could not be opened because of its resume token.
+func isResumeError(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		// ChangeStreamHistoryLost, ChangeStreamFatalError, BadValue / FailedToParse for malformed tokens
+		return cmdErr.Code == 286 || cmdErr.Code == 280 || cmdErr.Code == 2 || cmdErr.Code == 9
+	}
+	return false
+}
+
+// --- Polling Fallback ---
+
+// pollingChangeFeed follows the domain event log for deployments without a replica set.
+// The SSE event ID is the event ID. Changes arrive a few seconds late, and updates come
+// without the document.
+type pollingChangeFeed struct {
+	store *events.MongoStore
+}
+
+func (f pollingChangeFeed) Watch(ctx context.Context, tenantID, resumeAfter string, send func(ChangeNotification) error) error {
+	position := primitive.NewObjectIDFromTimestamp(time.Now())
+	if resumeAfter != "" {
+		var err error
+		if position, err = primitive.ObjectIDFromHex(resumeAfter); err != nil {
+			return ErrInvalidResumeToken
+		}
+		// Events older than the retention may already be gone; resuming there would
+		// silently skip them.
+		if position.Timestamp().Before(time.Now().Add(-events.EventRetention)) {
+			return ErrInvalidResumeToken
+		}
+	}
+
+	types := []string{
+		events.TypeEmployeeCreated, events.TypeEmployeeUpdated, events.TypeEmployeeDeleted, events.TypeEmployeeRestored,
+		events.TypeEntityCreated, events.TypeEntityUpdated, events.TypeEntityDeleted, events.TypeEntityRestored,
+	}
+	ticker := time.NewTicker(changeFeedPollInterval)
+	defer ticker.Stop()
+	for {
+		envs, err := f.store.ReadTenantAfter(ctx, tenantID, types, position, 100)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, env := range envs {
+			if err := send(notificationFromEvent(env)); err != nil {
+				return err
+			}
+			position = env.ID
+		}
+
+		if len(envs) == 100 {
+			continue // More events are waiting
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// notificationFromEvent converts an employee or entity event into a change notification.
+func notificationFromEvent(env events.Envelope) ChangeNotification {
+	notification := ChangeNotification{ID: env.ID.Hex(), OccurredAt: env.OccurredAt}
+	switch e := env.Event.(type) {
+	case events.EmployeeCreated:
+		notification.Operation, notification.EntityType, notification.EntityID = "created", "employees", e.Employee.ID.Hex()
+		notification.Document = e.Employee
+	case events.EmployeeUpdated:
+		notification.Operation, notification.EntityType, notification.EntityID = "updated", "employees", e.EmployeeID
+	case events.EmployeeDeleted:
+		notification.Operation, notification.EntityType, notification.EntityID = "deleted", "employees", e.Employee.ID.Hex()
+	case events.EmployeeRestored:
+		notification.Operation, notification.EntityType, notification.EntityID = "restored", "employees", e.Employee.ID.Hex()
+		notification.Document = e.Employee
+	case events.EntityCreated:
+		id, _ := e.Entity["_id"].(primitive.ObjectID)
+		notification.Operation, notification.EntityType, notification.EntityID = "created", e.EntityType, id.Hex()
+		notification.Document = e.Entity
+	case events.EntityUpdated:
+		notification.Operation, notification.EntityType, notification.EntityID = "updated", e.EntityType, e.EntityID
+	case events.EntityDeleted:
+		notification.Operation, notification.EntityType, notification.EntityID = "deleted", e.EntityType, e.EntityID
+	case events.EntityRestored:
+		id, _ := e.Entity["_id"].(primitive.ObjectID)
+		notification.Operation, notification.EntityType, notification.EntityID = "restored", e.EntityType, id.Hex()
+		notification.Document = e.Entity
+	}
+	return notification
+}
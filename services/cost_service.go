@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MaxCostReportMonths is the longest period a cost report may cover.
+const MaxCostReportMonths = 24
+
+// noCostCenterName names the report row of new hires without a known cost center.
+const noCostCenterName = "No cost center"
+
+// CostReportMonth is one month of a cost center's onboarding plan.
+type CostReportMonth struct {
+	Month           string  `json:"month"` // e.g. "2026-03"
+	PlannedHires    int     `json:"plannedHires"`
+	HeadcountBudget int     `json:"headcountBudget"`
+	OverBudget      bool    `json:"overBudget"`
+	HireCost        float64 `json:"hireCost"`     // Planned hires times the per-hire cost
+	HardwareCost    float64 `json:"hardwareCost"` // Units assigned to the new hires, or the model they need
+	TotalCost       float64 `json:"totalCost"`
+}
+
+// CostCenterReport is the onboarding plan of one cost center, month by month.
+type CostCenterReport struct {
+	CostCenterID    string            `json:"costCenterId,omitempty"` // Unset for new hires without a known cost center
+	Name            string            `json:"name"`
+	Code            string            `json:"code"`
+	HeadcountBudget int               `json:"headcountBudget"`
+	PerHireCost     float64           `json:"perHireCost"`
+	PlannedHires    int               `json:"plannedHires"`
+	TotalCost       float64           `json:"totalCost"`
+	Months          []CostReportMonth `json:"months"`
+}
+
+// costReportEmployee is the part of an employee the cost report needs.
+type costReportEmployee struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	CostCenterID    primitive.ObjectID `bson:"costCenterId"`
+	HardwareAssetID primitive.ObjectID `bson:"hardwareAssetId"`
+	OnboardingDate  primitive.DateTime `bson:"onboardingDate"`
+}
+
+// GetCostReport compares the onboardings planned for each month from one month to
+// another (both included) with the headcount budget of each cost center, and estimates
+// what they cost. A new hire costs the per-hire cost of their cost center plus their
+// hardware: the units assigned to them, or the model they need if none is assigned yet.
+func GetCostReport(ctx context.Context, tenantID string, from, to time.Time) ([]CostCenterReport, error) {
+	from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	var months []string
+	for month := from; month.Before(end); month = month.AddDate(0, 1, 0) {
+		months = append(months, month.Format("2006-01"))
+	}
+
+	// 1. Load the cost centers, hardware prices and the new hires of the period.
+	costCenters, err := GetEntitiesByTenant[models.CostCenter](ctx, "cost_centers", tenantID, Pagination{})
+	if err != nil {
+		return nil, err
+	}
+	hardware, err := GetEntitiesByTenant[models.HardwareAsset](ctx, "hardware_assets", tenantID, Pagination{})
+	if err != nil {
+		return nil, err
+	}
+	unitCosts := make(map[primitive.ObjectID]float64, len(hardware))
+	for _, model := range hardware {
+		unitCosts[model.ID] = model.UnitCost
+	}
+
+	cursor, err := db.GetCollection("employees").Find(ctx,
+		notDeleted(bson.M{"tenantId": tenantID, "onboardingDate": bson.M{"$gte": primitive.NewDateTimeFromTime(from), "$lt": primitive.NewDateTimeFromTime(end)}}),
+		options.Find().SetProjection(bson.M{"costCenterId": 1, "hardwareAssetId": 1, "onboardingDate": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var employees []costReportEmployee
+	if err := cursor.All(ctx, &employees); err != nil {
+		return nil, err
+	}
+
+	// 2. Price the hardware of every new hire.
+	employeeIDs := make([]primitive.ObjectID, len(employees))
+	for i, employee := range employees {
+		employeeIDs[i] = employee.ID
+	}
+	cursor, err = db.GetCollection("asset_units").Find(ctx,
+		bson.M{"tenantId": tenantID, "status": AssetAssigned, "assignedTo": bson.M{"$in": employeeIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var units []models.AssetUnit
+	if err := cursor.All(ctx, &units); err != nil {
+		return nil, err
+	}
+	hardwareCosts := map[primitive.ObjectID]float64{}
+	for _, unit := range units {
+		hardwareCosts[unit.AssignedTo] += unitCosts[unit.ModelID]
+	}
+	for _, employee := range employees {
+		if _, assigned := hardwareCosts[employee.ID]; !assigned {
+			hardwareCosts[employee.ID] = unitCosts[employee.HardwareAssetID]
+		}
+	}
+
+	// 3. Fill in one row per cost center, and one for new hires without a known one.
+	reports := make(map[primitive.ObjectID]*CostCenterReport, len(costCenters)+1)
+	newReport := func(id primitive.ObjectID, report *CostCenterReport) *CostCenterReport {
+		for _, month := range months {
+			report.Months = append(report.Months, CostReportMonth{Month: month, HeadcountBudget: report.HeadcountBudget})
+		}
+		reports[id] = report
+		return report
+	}
+	for _, costCenter := range costCenters {
+		newReport(costCenter.ID, &CostCenterReport{
+			CostCenterID:    costCenter.ID.Hex(),
+			Name:            costCenter.Name,
+			Code:            costCenter.Code,
+			HeadcountBudget: costCenter.HeadcountBudget,
+			PerHireCost:     costCenter.PerHireCost,
+		})
+	}
+	for _, employee := range employees {
+		report, ok := reports[employee.CostCenterID]
+		if !ok {
+			if report, ok = reports[primitive.NilObjectID]; !ok {
+				report = newReport(primitive.NilObjectID, &CostCenterReport{Name: noCostCenterName})
+			}
+		}
+		onboarding := employee.OnboardingDate.Time().UTC()
+		month := &report.Months[(onboarding.Year()-from.Year())*12+int(onboarding.Month()-from.Month())]
+		month.PlannedHires++
+		month.HireCost += report.PerHireCost
+		month.HardwareCost += hardwareCosts[employee.ID]
+	}
+
+	result := make([]CostCenterReport, 0, len(reports))
+	for _, report := range reports {
+		for i := range report.Months {
+			month := &report.Months[i]
+			month.TotalCost = month.HireCost + month.HardwareCost
+			month.OverBudget = month.HeadcountBudget > 0 && month.PlannedHires > month.HeadcountBudget
+			report.PlannedHires += month.PlannedHires
+			report.TotalCost += month.TotalCost
+		}
+		result = append(result, *report)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		// The row without a cost center goes last.
+		if (result[i].CostCenterID == "") != (result[j].CostCenterID == "") {
+			return result[j].CostCenterID == ""
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
@@ -0,0 +1,519 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"github.com/your-username/onboarding/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MaxDocumentSize caps the size of an uploaded document (10 MB). Templates may set a lower limit.
+const MaxDocumentSize = 10 << 20
+
+// Document statuses.
+const (
+	DocumentRequested = "requested" // Waiting for the employee to upload it
+	DocumentSubmitted = "submitted" // Uploaded, waiting for review
+	DocumentApproved  = "approved"
+	DocumentRejected  = "rejected" // The employee has to upload it again
+)
+
+// SupportedDocumentTypes are the content types accepted for uploads, as detected from the
+// file's content rather than trusted from the client.
+var SupportedDocumentTypes = []string{"application/pdf", "image/jpeg", "image/png"}
+
+// blobs holds the content of the documents. It is set by UseBlobStore.
+var blobs storage.BlobStore
+
+// UseBlobStore sets the store for document files. It is called once at startup.
+func UseBlobStore(store storage.BlobStore) {
+	blobs = store
+}
+
+// --- Document Templates ---
+
+// DocumentTemplateInput holds the fields a tenant admin can set on a document template.
+type DocumentTemplateInput struct {
+	Name          string   `json:"name" binding:"required"`
+	Description   string   `json:"description"`
+	ContentTypes  []string `json:"contentTypes"`
+	MaxSize       int64    `json:"maxSize"`
+	RetentionDays int      `json:"retentionDays"`
+	Active        *bool    `json:"active"` // Defaults to true
+}
+
+// validate checks the content types and limits.
+func (in *DocumentTemplateInput) validate() error {
+	for _, contentType := range in.ContentTypes {
+		if !containsString(SupportedDocumentTypes, contentType) {
+			return fmt.Errorf("unsupported content type %q, use one of %s", contentType, strings.Join(SupportedDocumentTypes, ", "))
+		}
+	}
+	if in.MaxSize < 0 || in.MaxSize > MaxDocumentSize {
+		return fmt.Errorf("maxSize must be between 0 and %d bytes", MaxDocumentSize)
+	}
+	if in.RetentionDays < 0 {
+		return errors.New("retentionDays must not be negative")
+	}
+	return nil
+}
+
+func (in *DocumentTemplateInput) toModel(tenantID string) models.DocumentTemplate {
+	return models.DocumentTemplate{
+		TenantID:      tenantID,
+		Name:          in.Name,
+		Description:   in.Description,
+		ContentTypes:  in.ContentTypes,
+		MaxSize:       in.MaxSize,
+		RetentionDays: in.RetentionDays,
+		Active:        in.Active == nil || *in.Active,
+	}
+}
+
+// CreateDocumentTemplate stores a new document template for the tenant. It applies to
+// employees created from now on; use RequestDocument for existing employees.
+func CreateDocumentTemplate(ctx context.Context, tenantID string, in *DocumentTemplateInput) (*models.DocumentTemplate, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	tmpl := in.toModel(tenantID)
+	tmpl.ID = primitive.NewObjectID()
+	if _, err := db.GetCollection("document_templates").InsertOne(ctx, tmpl); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// GetDocumentTemplates lists the tenant's document templates.
+func GetDocumentTemplates(ctx context.Context, tenantID string) ([]models.DocumentTemplate, error) {
+	return GetEntitiesByTenant[models.DocumentTemplate](ctx, "document_templates", tenantID, Pagination{})
+}
+
+// UpdateDocumentTemplate replaces a document template. Documents already requested keep
+// their status; new limits apply to the next upload.
+func UpdateDocumentTemplate(ctx context.Context, id, tenantID string, in *DocumentTemplateInput) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	tmpl := in.toModel(tenantID)
+	return UpdateEntity[models.DocumentTemplate](ctx, "document_templates", id, tenantID, "", bson.M{
+		"name":          tmpl.Name,
+		"description":   tmpl.Description,
+		"contentTypes":  tmpl.ContentTypes,
+		"maxSize":       tmpl.MaxSize,
+		"retentionDays": tmpl.RetentionDays,
+		"active":        tmpl.Active,
+	})
+}
+
+// DeleteDocumentTemplate removes a document template. Documents collected with it are kept.
+func DeleteDocumentTemplate(ctx context.Context, id, tenantID string) error {
+	return DeleteEntity[models.DocumentTemplate](ctx, "document_templates", id, tenantID, "")
+}
+
+// --- Employee Documents ---
+
+// requestDocumentsForNewEmployee creates a requested document from every active template
+// of the tenant. It is subscribed to employee.created.
+func requestDocumentsForNewEmployee(ctx context.Context, env events.Envelope) error {
+	e, ok := env.Event.(events.EmployeeCreated)
+	if !ok {
+		return nil
+	}
+	cursor, err := db.GetCollection("document_templates").Find(ctx, bson.M{"tenantId": e.Employee.TenantID, "active": true})
+	if err != nil {
+		return err
+	}
+	var templates []models.DocumentTemplate
+	if err := cursor.All(ctx, &templates); err != nil {
+		return err
+	}
+	for _, tmpl := range templates {
+		if _, err := requestDocument(ctx, e.Employee.ID, &tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequestDocument asks an existing employee for the document of a template. If the employee
+// already has a document for the template, that one is returned.
+func RequestDocument(ctx context.Context, employeeID, tenantID, templateID string) (*models.EmployeeDocument, error) {
+	employee, err := GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return nil, errors.New("employee not found or does not belong to this tenant")
+	}
+	tmpl, err := GetEntityByID[models.DocumentTemplate](ctx, "document_templates", templateID, tenantID)
+	if err != nil {
+		return nil, errors.New("document template not found or does not belong to this tenant")
+	}
+	return requestDocument(ctx, employee.ID, tmpl)
+}
+
+// requestDocument creates a requested document unless the employee already has one for the template.
+func requestDocument(ctx context.Context, employeeID primitive.ObjectID, tmpl *models.DocumentTemplate) (*models.EmployeeDocument, error) {
+	filter := bson.M{"tenantId": tmpl.TenantID, "employeeId": employeeID, "templateId": tmpl.ID}
+	document := models.EmployeeDocument{
+		ID:         primitive.NewObjectID(),
+		TenantID:   tmpl.TenantID,
+		EmployeeID: employeeID,
+		TemplateID: tmpl.ID,
+		Name:       tmpl.Name,
+		Status:     DocumentRequested,
+		CreatedAt:  primitive.NewDateTimeFromTime(time.Now()),
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var stored models.EmployeeDocument
+	err := db.GetCollection("employee_documents").FindOneAndUpdate(ctx, filter, bson.M{"$setOnInsert": document}, opts).Decode(&stored)
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// GetEmployeeDocuments lists the documents of an employee, requested ones included.
+func GetEmployeeDocuments(ctx context.Context, employeeID, tenantID string) ([]models.EmployeeDocument, error) {
+	objID, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := db.GetCollection("employee_documents").Find(ctx, bson.M{"tenantId": tenantID, "employeeId": objID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	documents := []models.EmployeeDocument{}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// getEmployeeDocument fetches one document of an employee.
+func getEmployeeDocument(ctx context.Context, documentID, employeeID, tenantID string) (*models.EmployeeDocument, error) {
+	docID, err := primitive.ObjectIDFromHex(documentID)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	empID, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	var document models.EmployeeDocument
+	filter := bson.M{"_id": docID, "tenantId": tenantID, "employeeId": empID}
+	if err := db.GetCollection("employee_documents").FindOne(ctx, filter).Decode(&document); err != nil {
+		return nil, errors.New("document not found or does not belong to this employee")
+	}
+	return &document, nil
+}
+
+// DocumentUpload describes a file uploaded for an employee.
+type DocumentUpload struct {
+	DocumentID string // A requested or rejected document to fulfil; empty for an ad-hoc upload
+	Name       string // Name of an ad-hoc document; defaults to the file name
+	FileName   string
+	UploadedBy string // A user ID, or "portal" for the new hire
+}
+
+// UploadEmployeeDocument stores a file for an employee, either for a requested (or rejected)
+// document or as a new ad-hoc document. The document is then waiting for review.
+func UploadEmployeeDocument(ctx context.Context, employeeID, tenantID string, upload *DocumentUpload, content io.Reader) (*models.EmployeeDocument, error) {
+	employee, err := GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return nil, errors.New("employee not found or does not belong to this tenant")
+	}
+
+	// 1. Find the document to fulfil and the limits that apply to it.
+	now := time.Now()
+	var document *models.EmployeeDocument
+	var tmpl *models.DocumentTemplate
+	if upload.DocumentID != "" {
+		if document, err = getEmployeeDocument(ctx, upload.DocumentID, employeeID, tenantID); err != nil {
+			return nil, err
+		}
+		if document.Status == DocumentApproved {
+			return nil, errors.New("the document has already been approved")
+		}
+		if !document.TemplateID.IsZero() {
+			tmpl, _ = GetEntityByID[models.DocumentTemplate](ctx, "document_templates", document.TemplateID.Hex(), tenantID)
+		}
+	} else {
+		name := strings.TrimSpace(upload.Name)
+		if name == "" {
+			name = upload.FileName
+		}
+		document = &models.EmployeeDocument{
+			ID:         primitive.NewObjectID(),
+			TenantID:   tenantID,
+			EmployeeID: employee.ID,
+			Name:       name,
+			CreatedAt:  primitive.NewDateTimeFromTime(now),
+		}
+	}
+	maxSize, contentTypes := int64(MaxDocumentSize), SupportedDocumentTypes
+	if tmpl != nil {
+		if tmpl.MaxSize > 0 {
+			maxSize = tmpl.MaxSize
+		}
+		if len(tmpl.ContentTypes) > 0 {
+			contentTypes = tmpl.ContentTypes
+		}
+	}
+
+	// 2. Check size and type. One byte more than allowed is read to detect oversized files.
+	data, err := io.ReadAll(io.LimitReader(content, maxSize+1))
+	if err != nil {
+		return nil, errors.New("could not read the uploaded file")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("the uploaded file is empty")
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("the file is larger than %d KB", maxSize>>10)
+	}
+	contentType := http.DetectContentType(data)
+	if !containsString(contentTypes, contentType) {
+		return nil, fmt.Errorf("files of type %s are not accepted here, use %s", contentType, strings.Join(contentTypes, ", "))
+	}
+
+	// 3. Store the file under a new key, then point the document at it. A replaced file is
+	// deleted afterwards, so a failed upload never loses the previous one.
+	previousKey := document.StorageKey
+	key := fmt.Sprintf("%s/documents/%s/%s", tenantID, document.ID.Hex(), primitive.NewObjectID().Hex())
+	if err := blobs.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	document.Status = DocumentSubmitted
+	document.RejectionReason = ""
+	document.FileName = upload.FileName
+	document.ContentType = contentType
+	document.Size = int64(len(data))
+	document.StorageKey = key
+	document.UploadedBy = upload.UploadedBy
+	document.UploadedAt = primitive.NewDateTimeFromTime(now)
+	document.ReviewedBy, document.ReviewedAt = "", 0
+	document.RetentionDays = 0
+	if tmpl != nil {
+		document.RetentionDays = tmpl.RetentionDays
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := db.GetCollection("employee_documents").ReplaceOne(ctx, bson.M{"_id": document.ID}, document, opts); err != nil {
+		blobs.Delete(ctx, key)
+		return nil, err
+	}
+	if previousKey != "" {
+		blobs.Delete(ctx, previousKey)
+	}
+	return document, nil
+}
+
+// OpenEmployeeDocument returns a document of an employee and a reader for its file.
+// The caller must close the reader.
+func OpenEmployeeDocument(ctx context.Context, documentID, employeeID, tenantID string) (*models.EmployeeDocument, io.ReadCloser, error) {
+	document, err := getEmployeeDocument(ctx, documentID, employeeID, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if document.StorageKey == "" {
+		return nil, nil, errors.New("no file has been uploaded for this document")
+	}
+	content, err := blobs.Get(ctx, document.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, content, nil
+}
+
+// ReviewEmployeeDocument approves or rejects a submitted document. A rejected document can
+// be uploaded again; the reason is shown to the employee.
+func ReviewEmployeeDocument(ctx context.Context, documentID, employeeID, tenantID, reviewerID string, approve bool, reason string) error {
+	document, err := getEmployeeDocument(ctx, documentID, employeeID, tenantID)
+	if err != nil {
+		return err
+	}
+	if document.Status != DocumentSubmitted {
+		return fmt.Errorf("only submitted documents can be reviewed, this one is %s", document.Status)
+	}
+
+	set := bson.M{
+		"status":     DocumentApproved,
+		"reviewedBy": reviewerID,
+		"reviewedAt": primitive.NewDateTimeFromTime(time.Now()),
+	}
+	if !approve {
+		if strings.TrimSpace(reason) == "" {
+			return errors.New("a reason is required to reject a document")
+		}
+		set["status"] = DocumentRejected
+		set["rejectionReason"] = strings.TrimSpace(reason)
+	}
+	// The status condition makes a concurrent upload or review win cleanly.
+	result, err := db.GetCollection("employee_documents").UpdateOne(ctx,
+		bson.M{"_id": document.ID, "status": DocumentSubmitted}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("the document changed while it was being reviewed, please reload it")
+	}
+	return nil
+}
+
+// DeleteEmployeeDocument removes a document and its file.
+func DeleteEmployeeDocument(ctx context.Context, documentID, employeeID, tenantID string) error {
+	document, err := getEmployeeDocument(ctx, documentID, employeeID, tenantID)
+	if err != nil {
+		return err
+	}
+	if _, err := db.GetCollection("employee_documents").DeleteOne(ctx, bson.M{"_id": document.ID}); err != nil {
+		return err
+	}
+	if document.StorageKey != "" {
+		return blobs.Delete(ctx, document.StorageKey)
+	}
+	return nil
+}
+
+// --- Retention ---
+
+// deleteDocumentsOfPurgedEmployees removes the documents of offboarded employees that are
+// purged from the trash, unless they have a retention period. Documents with one are kept
+// until the retention job deletes them.
+func deleteDocumentsOfPurgedEmployees(ctx context.Context, tenantID string, employeeIDs []primitive.ObjectID) error {
+	return deleteDocuments(ctx, bson.M{
+		"tenantId":      tenantID,
+		"employeeId":    bson.M{"$in": employeeIDs},
+		"retentionDays": bson.M{"$exists": false},
+	})
+}
+
+// startDocumentRetention starts the retention period of an employee's documents when the
+// employee is offboarded, and stops it again when the employee is restored from the trash.
+// It runs as a sync subscriber, so the period is set in the same transaction as the deletion.
+func startDocumentRetention(ctx context.Context, env events.Envelope) error {
+	collection := db.GetCollection("employee_documents")
+	switch e := env.Event.(type) {
+	case events.EmployeeDeleted:
+		filter := bson.M{"tenantId": e.Employee.TenantID, "employeeId": e.Employee.ID, "retentionDays": bson.M{"$gt": 0}}
+		cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"retentionDays": 1}))
+		if err != nil {
+			return err
+		}
+		var documents []models.EmployeeDocument
+		if err := cursor.All(ctx, &documents); err != nil {
+			return err
+		}
+		for _, document := range documents {
+			retainUntil := primitive.NewDateTimeFromTime(env.OccurredAt.AddDate(0, 0, document.RetentionDays))
+			if _, err := collection.UpdateByID(ctx, document.ID, bson.M{"$set": bson.M{"retainUntil": retainUntil}}); err != nil {
+				return err
+			}
+		}
+	case events.EmployeeRestored:
+		filter := bson.M{"tenantId": e.Employee.TenantID, "employeeId": e.Employee.ID}
+		if _, err := collection.UpdateMany(ctx, filter, bson.M{"$unset": bson.M{"retainUntil": ""}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateDocumentRetention converts the documents whose retention was counted from the
+// upload: the period is kept as retentionDays and starts when the employee is offboarded,
+// or right away for employees that are already in the trash. It runs at startup and finds
+// nothing to do once every document is converted.
+func MigrateDocumentRetention(ctx context.Context) error {
+	collection := db.GetCollection("employee_documents")
+	legacy := bson.M{"retainUntil": bson.M{"$exists": true}, "retentionDays": bson.M{"$exists": false}}
+	cursor, err := collection.Find(ctx, legacy)
+	if err != nil {
+		return err
+	}
+	var documents []models.EmployeeDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return err
+	}
+
+	offboardedAt := map[primitive.ObjectID]primitive.DateTime{}
+	for _, document := range documents {
+		days := int(math.Round(document.RetainUntil.Time().Sub(document.UploadedAt.Time()).Hours() / 24))
+		if days < 1 {
+			days = 1
+		}
+		update := bson.M{"$set": bson.M{"retentionDays": days}, "$unset": bson.M{"retainUntil": ""}}
+
+		deletedAt, ok := offboardedAt[document.EmployeeID]
+		if !ok {
+			var employee struct {
+				DeletedAt primitive.DateTime `bson:"deletedAt"`
+			}
+			err := db.GetCollection("employees").FindOne(ctx, bson.M{"_id": document.EmployeeID}).Decode(&employee)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return err
+			}
+			deletedAt = employee.DeletedAt
+			if err == mongo.ErrNoDocuments {
+				deletedAt = primitive.NewDateTimeFromTime(time.Now()) // Already purged
+			}
+			offboardedAt[document.EmployeeID] = deletedAt
+		}
+		if deletedAt != 0 {
+			update = bson.M{"$set": bson.M{
+				"retentionDays": days,
+				"retainUntil":   primitive.NewDateTimeFromTime(deletedAt.Time().AddDate(0, 0, days)),
+			}}
+		}
+		if _, err := collection.UpdateByID(ctx, document.ID, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDocumentRetention deletes the documents whose retention period has ended.
+func applyDocumentRetention(ctx context.Context, job *models.ScheduledJob) error {
+	return deleteDocuments(ctx, bson.M{"retainUntil": bson.M{"$lte": primitive.NewDateTimeFromTime(time.Now())}})
+}
+
+// deleteDocuments removes the matching documents and their files. Files are deleted first,
+// so a failure leaves the document in place to be retried.
+func deleteDocuments(ctx context.Context, filter bson.M) error {
+	collection := db.GetCollection("employee_documents")
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var document models.EmployeeDocument
+		if err := cursor.Decode(&document); err != nil {
+			return err
+		}
+		if document.StorageKey != "" {
+			if err := blobs.Delete(ctx, document.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return err
+			}
+		}
+		if _, err := collection.DeleteOne(ctx, bson.M{"_id": document.ID}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -3,29 +3,50 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
 	"github.com/your-username/onboarding/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// CreateEmployee creates a new employee record.
+// ErrEmployeeNotFound is returned when an employee does not exist in the tenant.
+var ErrEmployeeNotFound = errors.New("employee not found or does not belong to this tenant")
+
+// CreateEmployee creates a new employee record. New employees start without an access
+// level; it is granted through an access request.
 func CreateEmployee(employee *models.Employee) (*models.Employee, error) {
 	var employeeCollection = db.GetCollection("employees")
+	if !employee.AccessLevelID.IsZero() {
+		return nil, ErrAccessLevelReadOnly
+	}
+	if err := checkReportsTo(context.Background(), employee.TenantID, primitive.NilObjectID, employee.ReportsToID); err != nil {
+		return nil, err
+	}
 	employee.ID = primitive.NewObjectID()
-	_, err := employeeCollection.InsertOne(context.Background(), employee)
+	err := writeWithEvents(context.Background(), func(ctx context.Context) ([]events.Event, error) {
+		if _, err := employeeCollection.InsertOne(ctx, employee); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.EmployeeCreated{Employee: *employee}}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	return employee, nil
 }
 
-// GetEmployeesByTenant fetches all employees associated with a specific tenant.
-func GetEmployeesByTenant(tenantID string) ([]models.Employee, error) {
+// GetEmployeesByTenant fetches the employees associated with a specific tenant,
+// optionally restricted to a single page.
+func GetEmployeesByTenant(tenantID string, page Pagination) ([]models.Employee, error) {
 	var employeeCollection = db.GetCollection("employees")
 	var employees []models.Employee
-	cursor, err := employeeCollection.Find(context.Background(), bson.M{"tenantId": tenantID})
+	cursor, err := employeeCollection.Find(context.Background(), page.query(tenantID), page.findOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +55,11 @@ func GetEmployeesByTenant(tenantID string) ([]models.Employee, error) {
 	if err = cursor.All(context.Background(), &employees); err != nil {
 		return nil, err
 	}
+
+	// Return an empty slice instead of nil so the JSON response is [] as the spec declares.
+	if employees == nil {
+		return []models.Employee{}, nil
+	}
 	return employees, nil
 }
 
@@ -43,32 +69,220 @@ func GetEmployeeByID(id, tenantID string) (*models.Employee, error) {
 	var employeeCollection = db.GetCollection("employees")
 	objID, _ := primitive.ObjectIDFromHex(id)
 	var employee models.Employee
-	filter := bson.M{"_id": objID, "tenantId": tenantID}
+	filter := notDeleted(bson.M{"_id": objID, "tenantId": tenantID})
 	err := employeeCollection.FindOne(context.Background(), filter).Decode(&employee)
 	return &employee, err
 }
 
-// UpdateEmployee updates an existing employee's data.
-func UpdateEmployee(id, tenantID string, employeeData bson.M) error {
+// UpdateEmployee updates an existing employee's data on behalf of a user. The access
+// level can only be submitted unchanged; it is set when an access request is carried
+// out. An update that an approval policy covers is not applied but stored as a change
+// request, and returned as a *ChangeHeldError. The update is normalized first, so that
+// the policies compare it with the stored values.
+func UpdateEmployee(id, tenantID, userID string, employeeData bson.M) error {
+	if err := normalizeEmployeeUpdate(employeeData); err != nil {
+		return err
+	}
+	if err := guardAccessLevel(id, tenantID, employeeData); err != nil {
+		return err
+	}
+	if err := requireApproval(context.Background(), tenantID, "employees", id, userID, employeeData); err != nil {
+		return err
+	}
+	return updateEmployee(id, tenantID, employeeData)
+}
+
+// guardAccessLevel refuses a change of the access level. An unchanged access level is
+// dropped from the update.
+func guardAccessLevel(id, tenantID string, employeeData bson.M) error {
+	value, ok := employeeData["accessLevelId"]
+	if !ok {
+		return nil
+	}
+	levelID, err := ObjectIDValue(value)
+	if err != nil {
+		return errors.New("invalid format for accessLevelId")
+	}
+	employee, err := GetEmployeeByID(id, tenantID)
+	if err != nil {
+		return ErrEmployeeNotFound
+	}
+	if levelID != employee.AccessLevelID {
+		return ErrAccessLevelReadOnly
+	}
+	delete(employeeData, "accessLevelId")
+	return nil
+}
+
+// updateEmployee does the work of UpdateEmployee without guarding the access level or
+// checking the approval policies.
+// A change of department is also published as its own event.
+func updateEmployee(id, tenantID string, employeeData bson.M) error {
 	var employeeCollection = db.GetCollection("employees")
 	objID, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{"_id": objID, "tenantId": tenantID}
-	update := bson.M{"$set": employeeData}
-	result, err := employeeCollection.UpdateOne(context.Background(), filter, update)
-	if result.MatchedCount == 0 {
-		return errors.New("employee not found or does not belong to this tenant")
+	filter := notDeleted(bson.M{"_id": objID, "tenantId": tenantID})
+
+	// Keep the previous state to detect a department change.
+	var previous models.Employee
+	if err := employeeCollection.FindOne(context.Background(), filter).Decode(&previous); err != nil {
+		return ErrEmployeeNotFound
+	}
+
+	if err := normalizeEmployeeUpdate(employeeData); err != nil {
+		return err
+	}
+
+	// A new manager must be an employee of the tenant and must not close a reporting cycle.
+	if managerID, ok := employeeData["reportsToId"].(primitive.ObjectID); ok {
+		if err := checkReportsTo(context.Background(), tenantID, objID, managerID); err != nil {
+			return err
+		}
+	}
+
+	// Deleting and restoring have their own endpoints.
+	delete(employeeData, "deletedAt")
+	delete(employeeData, "deletedBy")
+
+	return writeWithEvents(context.Background(), func(ctx context.Context) ([]events.Event, error) {
+		if err := ensureBaselineVersion(ctx, "employees", "employees", objID); err != nil {
+			return nil, err
+		}
+		result, err := employeeCollection.UpdateOne(ctx, filter, bson.M{"$set": employeeData})
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, ErrEmployeeNotFound
+		}
+
+		published := []events.Event{events.EmployeeUpdated{TenantID: tenantID, EmployeeID: id, Changes: employeeData}}
+		if departmentID, ok := employeeData["departmentId"].(primitive.ObjectID); ok && departmentID != previous.DepartmentID {
+			published = append(published, events.EmployeeDepartmentChanged{
+				TenantID:             tenantID,
+				EmployeeID:           id,
+				PreviousDepartmentID: previous.DepartmentID.Hex(),
+				DepartmentID:         departmentID.Hex(),
+			})
+		}
+		return published, nil
+	})
+}
+
+// normalizeEmployeeUpdate converts the values of an update that arrive as strings from
+// JSON into the types stored on create: references become ObjectIDs and the onboarding
+// date becomes a date. Values that already have the stored type are kept.
+func normalizeEmployeeUpdate(employeeData bson.M) error {
+	for _, ref := range EmployeeReferences {
+		if value, ok := employeeData[ref.Field]; ok {
+			id, err := ObjectIDValue(value)
+			if err != nil {
+				return fmt.Errorf("invalid format for %s", ref.Field)
+			}
+			employeeData[ref.Field] = id
+		}
+	}
+	if value, ok := employeeData["reportsToId"]; ok {
+		id, err := ObjectIDValue(value)
+		if err != nil {
+			return errors.New("invalid format for reportsToId")
+		}
+		employeeData["reportsToId"] = id
+	}
+	if value, ok := employeeData["onboardingDate"]; ok {
+		date, err := dateValue(value)
+		if err != nil {
+			return errors.New("onboardingDate must be a date such as 2026-03-01 or an RFC3339 timestamp")
+		}
+		employeeData["onboardingDate"] = date
 	}
-	return err
+	return nil
 }
 
-// DeleteEmployee deletes an employee record.
-func DeleteEmployee(id, tenantID string) error {
+// dateValue converts a date from a decoded JSON body or a stored document. It accepts a
+// date, or a string as YYYY-MM-DD or RFC3339.
+func dateValue(value interface{}) (primitive.DateTime, error) {
+	switch v := value.(type) {
+	case primitive.DateTime:
+		return v, nil
+	case time.Time:
+		return primitive.NewDateTimeFromTime(v), nil
+	case string:
+		date, err := parseImportDate(v)
+		if err != nil {
+			return 0, err
+		}
+		return primitive.NewDateTimeFromTime(date), nil
+	}
+	return 0, fmt.Errorf("invalid date %v", value)
+}
+
+// FixEmployeeValues converts the values that UpdateEmployee used to store as strings: the
+// references become ObjectIDs, an empty string becoming the empty reference as on create,
+// and the onboarding date becomes a date. It runs at startup and finds nothing to do once
+// every record is fixed.
+func FixEmployeeValues(ctx context.Context) error {
+	employeeCollection := db.GetCollection("employees")
+	for _, ref := range EmployeeReferences {
+		field := "$" + ref.Field
+		_, err := employeeCollection.UpdateMany(ctx, bson.M{ref.Field: bson.M{"$type": "string"}}, mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{ref.Field: bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{field, ""}},
+				primitive.NilObjectID,
+				bson.M{"$convert": bson.M{"input": field, "to": "objectId", "onError": field}},
+			}}}}},
+		})
+		if err != nil {
+			return fmt.Errorf("fixing %s: %w", ref.Field, err)
+		}
+	}
+	_, err := employeeCollection.UpdateMany(ctx, bson.M{"onboardingDate": bson.M{"$type": "string"}}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"onboardingDate": bson.M{"$convert": bson.M{
+			"input": "$onboardingDate", "to": "date", "onError": "$onboardingDate",
+		}}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("fixing onboardingDate: %w", err)
+	}
+	return nil
+}
+
+// DeleteEmployee moves an employee record to the trash, recording when and by which user.
+// The published event carries the deleted record, since this is how an employee is
+// offboarded.
+func DeleteEmployee(id, tenantID, userID string) error {
 	var employeeCollection = db.GetCollection("employees")
 	objID, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{"_id": objID, "tenantId": tenantID}
-	result, err := employeeCollection.DeleteOne(context.Background(), filter)
-	if result.DeletedCount == 0 {
-		return errors.New("employee not found or does not belong to this tenant")
+	filter := notDeleted(bson.M{"_id": objID, "tenantId": tenantID})
+	return writeWithEvents(context.Background(), func(ctx context.Context) ([]events.Event, error) {
+		if err := ensureBaselineVersion(ctx, "employees", "employees", objID); err != nil {
+			return nil, err
+		}
+		var employee models.Employee
+		err := employeeCollection.FindOneAndUpdate(ctx, filter, bson.M{"$set": trashFields(userID)},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&employee)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrEmployeeNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []events.Event{events.EmployeeDeleted{Employee: employee}}, nil
+	})
+}
+
+// ObjectIDValue converts a reference from a decoded JSON body or a stored document. It
+// accepts an ObjectID, its hex string, or nil or "" for no reference.
+func ObjectIDValue(value interface{}) (primitive.ObjectID, error) {
+	switch v := value.(type) {
+	case nil:
+		return primitive.NilObjectID, nil
+	case primitive.ObjectID:
+		return v, nil
+	case string:
+		if v == "" {
+			return primitive.NilObjectID, nil
+		}
+		return primitive.ObjectIDFromHex(v)
 	}
-	return err
+	return primitive.NilObjectID, errors.New("value must be a string")
 }
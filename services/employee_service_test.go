@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeEmployeeUpdate(t *testing.T) {
+	ref := primitive.NewObjectID()
+	day := primitive.NewDateTimeFromTime(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name    string
+		field   string
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"date string", "onboardingDate", "2026-03-01", day, false},
+		{"RFC3339 string", "onboardingDate", "2026-03-01T00:00:00Z", day, false},
+		{"stored date", "onboardingDate", day, day, false},
+		{"invalid date", "onboardingDate", "1st of March", nil, true},
+		{"number as date", "onboardingDate", 42.0, nil, true},
+		{"hex reference", "teamId", ref.Hex(), ref, false},
+		{"stored reference", "teamId", ref, ref, false},
+		{"cleared reference", "teamId", "", primitive.NilObjectID, false},
+		{"invalid reference", "teamId", "team-1", nil, true},
+		{"hex manager", "reportsToId", ref.Hex(), ref, false},
+		{"plain field", "firstName", "Ada", "Ada", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bson.M{tt.field: tt.value}
+			err := normalizeEmployeeUpdate(data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s = %v", tt.field, data[tt.field])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if data[tt.field] != tt.want {
+				t.Fatalf("%s = %#v, want %#v", tt.field, data[tt.field], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import "strings"
+
+// Reference links a field holding an ObjectID to the entity type it points at.
+type Reference struct {
+	Field string // JSON/BSON field name, e.g. "locationId"
+	Slug  string // Slug of the referenced entity, e.g. "locations"
+}
+
+// NameField is the column used when the reference is given or shown by name
+// instead of by ID, e.g. "location" for "locationId".
+func (r Reference) NameField() string {
+	return strings.TrimSuffix(r.Field, "Id")
+}
+
+// EntityDefinition describes one of the dynamic entity types.
+// Features that work across all entities (import, export, ...) use these definitions
+// instead of repeating the slug-to-collection mapping from the router and handlers.
+type EntityDefinition struct {
+	Slug       string      // URL and permission slug, e.g. "job-roles"
+	Collection string      // MongoDB collection, e.g. "job_roles"
+	Fields     []string    // String fields in addition to "name", e.g. "address"
+	References []Reference // ObjectID fields pointing at other entities
+	IDFields   []string    // Other ObjectID fields, e.g. "employeeId"
+}
+
+// EntityDefinitions lists every dynamic entity, in the same order as the router.
+var EntityDefinitions = []EntityDefinition{
+	{Slug: "locations", Collection: "locations", Fields: []string{"address", "postalCode"}},
+	{Slug: "departments", Collection: "departments", Fields: []string{"head"}, References: []Reference{{Field: "parentId", Slug: "departments"}}},
+	{Slug: "managers", Collection: "managers", Fields: []string{"email"}},
+	{Slug: "job-roles", Collection: "job_roles", Fields: []string{"description"}},
+	{Slug: "employement-types", Collection: "employment_types"},
+	{Slug: "teams", Collection: "teams", References: []Reference{{Field: "departmentId", Slug: "departments"}}},
+	{Slug: "costs", Collection: "cost_centers", Fields: []string{"code"}},
+	{Slug: "hardware-assets", Collection: "hardware_assets", Fields: []string{"modelNumber"}},
+	{Slug: "onboarding-buddy", Collection: "onboarding_buddies", Fields: []string{"email"}, References: []Reference{{Field: "teamId", Slug: "teams"}}, IDFields: []string{"employeeId"}},
+	{Slug: "access-levels", Collection: "access_levels"},
+}
+
+// EmployeeFields are the plain string fields of an employee, in display order.
+var EmployeeFields = []string{"firstName", "lastName", "email", "phoneNumber", "onboardingDate"}
+
+// EmployeeReferences are the entity references stored on an employee, in display order.
+var EmployeeReferences = []Reference{
+	{Field: "locationId", Slug: "locations"},
+	{Field: "departmentId", Slug: "departments"},
+	{Field: "managerId", Slug: "managers"},
+	{Field: "jobRoleId", Slug: "job-roles"},
+	{Field: "employmentTypeId", Slug: "employement-types"},
+	{Field: "teamId", Slug: "teams"},
+	{Field: "costCenterId", Slug: "costs"},
+	{Field: "hardwareAssetId", Slug: "hardware-assets"},
+	{Field: "onboardingBuddyId", Slug: "onboarding-buddy"},
+	{Field: "accessLevelId", Slug: "access-levels"},
+}
+
+// LookupEntity returns the definition for an entity slug.
+func LookupEntity(slug string) (EntityDefinition, bool) {
+	for _, def := range EntityDefinitions {
+		if def.Slug == slug {
+			return def, true
+		}
+	}
+	return EntityDefinition{}, false
+}
+
+// LookupEntityByCollection returns the definition of the entity type stored in a collection.
+func LookupEntityByCollection(collectionName string) (EntityDefinition, bool) {
+	for _, def := range EntityDefinitions {
+		if def.Collection == collectionName {
+			return def, true
+		}
+	}
+	return EntityDefinition{}, false
+}
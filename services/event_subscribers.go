@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+)
+
+// RegisterEventSubscribers subscribes the services' side effects to the event bus.
+// It is called once at startup, before events.Start.
+func RegisterEventSubscribers() {
+	// Webhooks already have their own durable outbox. Writing to it synchronously, in the
+	// transaction of the change (see writeWithEvents), makes sure no event is lost.
+	events.Subscribe("webhooks", events.Sync, enqueueWebhooks, WebhookEventTypes...)
+	// Versions read the record right after the change, so they must not lag behind.
+	events.Subscribe("record-versions", events.Sync, recordVersion,
+		events.TypeEmployeeCreated, events.TypeEmployeeUpdated, events.TypeEmployeeDeleted, events.TypeEmployeeRestored,
+		events.TypeEntityCreated, events.TypeEntityUpdated, events.TypeEntityDeleted, events.TypeEntityRestored)
+	// Retention periods start with the deletion, so they are set in its transaction.
+	events.Subscribe("document-retention", events.Sync, startDocumentRetention,
+		events.TypeEmployeeDeleted, events.TypeEmployeeRestored)
+
+	events.Subscribe("document-requests", events.Async, requestDocumentsForNewEmployee, events.TypeEmployeeCreated)
+	events.Subscribe("reporting-lines", events.Async, reassignReportsOfDeletedEmployee, events.TypeEmployeeDeleted)
+	events.Subscribe("access-revocations", events.Async, revokeAccessOfDeletedEmployee, events.TypeEmployeeDeleted)
+	events.Subscribe("buddy-assignments", events.Async, syncBuddyAssignments,
+		events.TypeEmployeeCreated, events.TypeEmployeeUpdated, events.TypeEmployeeDeleted, events.TypeEntityDeleted)
+	events.Subscribe("search-index", events.Async, updateSearchIndex,
+		events.TypeEmployeeCreated, events.TypeEmployeeUpdated, events.TypeEmployeeDeleted, events.TypeEmployeeRestored,
+		events.TypeEntityCreated, events.TypeEntityUpdated, events.TypeEntityDeleted, events.TypeEntityRestored)
+}
+
+// writeWithEvents runs write and records the events it returns in one transaction, so a
+// change is committed together with its stored events and with what the sync subscribers
+// write: the webhook outbox and the record versions. The async subscribers are notified
+// after the commit. Without transactions (a standalone MongoDB) the change stays written
+// when recording fails, but the error is still returned so the request does not succeed
+// with its webhooks missing.
+func writeWithEvents(ctx context.Context, write func(ctx context.Context) ([]events.Event, error)) error {
+	var recorded []events.Envelope
+	err := db.Transaction(ctx, func(ctx context.Context) error {
+		recorded = recorded[:0] // The transaction may be retried
+		published, err := write(ctx)
+		if err != nil {
+			return err
+		}
+		for _, event := range published {
+			env, err := events.Record(ctx, event)
+			if err != nil {
+				return err
+			}
+			recorded = append(recorded, env)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, env := range recorded {
+		events.Dispatch(env)
+	}
+	return nil
+}
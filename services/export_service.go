@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExportOptions controls the output of an export.
+type ExportOptions struct {
+	Format      string // "csv", "xlsx" or "ndjson"
+	Denormalize bool   // Replace reference IDs with the names of the referenced entities
+	Page        Pagination
+}
+
+// Export writes the tenant's employees or entities of one type to w, one row per document.
+// Documents are read from a cursor and written as they arrive, so memory use does not
+// grow with the size of the tenant. The columns use the same names the import accepts,
+// so an export can be edited and imported again.
+func Export(ctx context.Context, w io.Writer, tenantID, entityType string, opts ExportOptions) error {
+	var collectionName string
+	var fields []string
+	var references []Reference
+	if entityType == "employees" {
+		collectionName, fields, references = "employees", EmployeeFields, EmployeeReferences
+	} else if def, ok := LookupEntity(entityType); ok {
+		collectionName, fields, references = def.Collection, append([]string{"name"}, def.Fields...), def.References
+	} else {
+		return fmt.Errorf("unknown entity type %q", entityType)
+	}
+
+	// 1. Build the header, and load the names of referenced entities if requested.
+	header := append([]string{"id"}, fields...)
+	indexes := map[string]*nameIndex{}
+	for _, ref := range references {
+		if !opts.Denormalize {
+			header = append(header, ref.Field)
+			continue
+		}
+		header = append(header, ref.NameField())
+		if _, ok := indexes[ref.Slug]; !ok {
+			index, err := loadNameIndex(ctx, ref.Slug, tenantID)
+			if err != nil {
+				return err
+			}
+			indexes[ref.Slug] = index
+		}
+	}
+
+	// 2. Stream the documents.
+	cursor, err := db.GetCollection(collectionName).Find(ctx, opts.Page.query(tenantID), opts.Page.findOptions())
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	writer, err := utils.NewRowWriter(opts.Format, w, header)
+	if err != nil {
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return err
+		}
+
+		row := []string{exportValue(document["_id"])}
+		for _, field := range fields {
+			row = append(row, exportValue(document[field]))
+		}
+		for _, ref := range references {
+			id, _ := document[ref.Field].(primitive.ObjectID)
+			if opts.Denormalize {
+				row = append(row, indexes[ref.Slug].names[id])
+			} else {
+				row = append(row, exportValue(document[ref.Field]))
+			}
+		}
+
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// exportValue formats a BSON value as a cell. Unset references (the zero ObjectID) become empty cells.
+func exportValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case primitive.ObjectID:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -3,11 +3,14 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Entity is a constraint that serves as a marker for our generic functions.
@@ -17,6 +20,46 @@ type Entity interface {
 	// We could enforce methods here if needed, e.g., GetID() string
 }
 
+// Pagination limits the documents returned by list queries.
+// A zero Limit returns every document, which keeps list endpoints backwards compatible.
+// Filter, if set, is a compiled filter expression (see ListFilter) the documents must match.
+type Pagination struct {
+	Limit  int64
+	Offset int64
+	Filter bson.M
+}
+
+// query returns the query for the tenant's documents that match the filter, leaving out
+// the ones in the trash.
+func (p Pagination) query(tenantID string) bson.M {
+	query := notDeleted(bson.M{"tenantId": tenantID})
+	if p.Filter == nil {
+		return query
+	}
+	return bson.M{"$and": bson.A{query, p.Filter}}
+}
+
+// notDeleted adds the condition that leaves out deleted documents, which stay in the trash
+// with a deletedAt until they are restored or purged, to a query and returns it. Every read
+// of employees and entities goes through it, unless it is meant to see the trash.
+func notDeleted(query bson.M) bson.M {
+	query["deletedAt"] = bson.M{"$exists": false}
+	return query
+}
+
+// findOptions converts the pagination into MongoDB find options.
+// Results are sorted by _id so that consecutive pages are stable.
+func (p Pagination) findOptions() *options.FindOptions {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if p.Limit > 0 {
+		opts.SetLimit(p.Limit)
+	}
+	if p.Offset > 0 {
+		opts.SetSkip(p.Offset)
+	}
+	return opts
+}
+
 // CreateEntity creates a new document in the specified collection for a given tenant.
 // It uses generics to work with any of our specific entity types (e.g., models.Location).
 func CreateEntity[T Entity](ctx context.Context, collectionName string, entity *T) (*T, error) {
@@ -36,7 +79,16 @@ func CreateEntity[T Entity](ctx context.Context, collectionName string, entity *
 
 	docMap["_id"] = primitive.NewObjectID()
 
-	_, err = collection.InsertOne(ctx, docMap)
+	err = writeWithEvents(ctx, func(ctx context.Context) ([]events.Event, error) {
+		if _, err := collection.InsertOne(ctx, docMap); err != nil {
+			return nil, err
+		}
+		if def, ok := LookupEntityByCollection(collectionName); ok {
+			tenantID, _ := docMap["tenantId"].(string)
+			return []events.Event{events.EntityCreated{TenantID: tenantID, EntityType: def.Slug, Entity: docMap}}, nil
+		}
+		return nil, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -54,12 +106,13 @@ func CreateEntity[T Entity](ctx context.Context, collectionName string, entity *
 	return entity, nil
 }
 
-// GetEntitiesByTenant fetches all documents from a collection for a specific tenant.
-func GetEntitiesByTenant[T Entity](ctx context.Context, collectionName, tenantID string) ([]T, error) {
+// GetEntitiesByTenant fetches the documents from a collection for a specific tenant,
+// optionally restricted to a single page.
+func GetEntitiesByTenant[T Entity](ctx context.Context, collectionName, tenantID string, page Pagination) ([]T, error) {
 	collection := db.GetCollection(collectionName)
 	var results []T
 
-	cursor, err := collection.Find(ctx, bson.M{"tenantId": tenantID})
+	cursor, err := collection.Find(ctx, page.query(tenantID), page.findOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +140,7 @@ func GetEntityByID[T Entity](ctx context.Context, collectionName, id, tenantID s
 	collection := db.GetCollection(collectionName)
 	var result T
 
-	filter := bson.M{"_id": objID, "tenantId": tenantID}
+	filter := notDeleted(bson.M{"_id": objID, "tenantId": tenantID})
 	err = collection.FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -99,48 +152,121 @@ func GetEntityByID[T Entity](ctx context.Context, collectionName, id, tenantID s
 	return &result, nil
 }
 
-// UpdateEntity updates a document in a collection.
+// UpdateEntity updates a document in a collection on behalf of a user.
 // It uses bson.M for the update data to allow for partial updates (PATCH-like behavior).
-func UpdateEntity[T Entity](ctx context.Context, collectionName, id, tenantID string, updateData bson.M) error {
+// An update of an entity that an approval policy covers is not applied but stored as a
+// change request, and returned as a *ChangeHeldError.
+func UpdateEntity[T Entity](ctx context.Context, collectionName, id, tenantID, userID string, updateData bson.M) error {
+	if def, ok := LookupEntityByCollection(collectionName); ok {
+		if err := requireApproval(ctx, tenantID, def.Slug, id, userID, updateData); err != nil {
+			return err
+		}
+	}
+	return updateEntity(ctx, collectionName, id, tenantID, updateData)
+}
+
+// updateEntity does the work of UpdateEntity without checking the approval policies.
+func updateEntity(ctx context.Context, collectionName, id, tenantID string, updateData bson.M) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return errors.New("invalid id format")
 	}
 
-	collection := db.GetCollection(collectionName)
-	filter := bson.M{"_id": objID, "tenantId": tenantID}
-	update := bson.M{"$set": updateData}
-
-	result, err := collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
+	// ID fields arrive as hex strings from JSON; store them as ObjectIDs, as on create.
+	def, isEntity := LookupEntityByCollection(collectionName)
+	if isEntity {
+		if err := convertIDFields(updateData, def); err != nil {
+			return err
+		}
 	}
+	// Deleting and restoring have their own endpoints.
+	delete(updateData, "deletedAt")
+	delete(updateData, "deletedBy")
 
-	if result.MatchedCount == 0 {
-		return errors.New("entity not found or does not belong to this tenant")
-	}
+	collection := db.GetCollection(collectionName)
+	filter := notDeleted(bson.M{"_id": objID, "tenantId": tenantID})
+	update := bson.M{"$set": updateData}
 
-	return nil
+	return writeWithEvents(ctx, func(ctx context.Context) ([]events.Event, error) {
+		if isEntity {
+			if err := ensureBaselineVersion(ctx, def.Slug, collectionName, objID); err != nil {
+				return nil, err
+			}
+		}
+		result, err := collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, errors.New("entity not found or does not belong to this tenant")
+		}
+		if isEntity {
+			return []events.Event{events.EntityUpdated{TenantID: tenantID, EntityType: def.Slug, EntityID: id, Changes: updateData}}, nil
+		}
+		return nil, nil
+	})
 }
 
-// DeleteEntity deletes a document from a collection.
-func DeleteEntity[T Entity](ctx context.Context, collectionName, id, tenantID string) error {
+// DeleteEntity deletes a document from a collection. Entities (see EntityDefinitions) are
+// moved to the trash, recording when and by which user, and can be restored until they are
+// purged; documents of other collections are removed for good.
+func DeleteEntity[T Entity](ctx context.Context, collectionName, id, tenantID, userID string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return errors.New("invalid id format")
 	}
 
 	collection := db.GetCollection(collectionName)
-	filter := bson.M{"_id": objID, "tenantId": tenantID}
+	filter := notDeleted(bson.M{"_id": objID, "tenantId": tenantID})
 
-	result, err := collection.DeleteOne(ctx, filter)
-	if err != nil {
-		return err
+	def, isEntity := LookupEntityByCollection(collectionName)
+	if !isEntity {
+		result, err := collection.DeleteOne(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return errors.New("entity not found or does not belong to this tenant")
+		}
+		return nil
 	}
 
-	if result.DeletedCount == 0 {
-		return errors.New("entity not found or does not belong to this tenant")
-	}
+	return writeWithEvents(ctx, func(ctx context.Context) ([]events.Event, error) {
+		if err := ensureBaselineVersion(ctx, def.Slug, collectionName, objID); err != nil {
+			return nil, err
+		}
+		result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": trashFields(userID)})
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, errors.New("entity not found or does not belong to this tenant")
+		}
+		return []events.Event{events.EntityDeleted{TenantID: tenantID, EntityType: def.Slug, EntityID: id}}, nil
+	})
+}
 
+// convertIDFields replaces the hex strings in an entity's ID fields with ObjectIDs.
+// An empty string clears the reference.
+func convertIDFields(data bson.M, def EntityDefinition) error {
+	fields := append([]string(nil), def.IDFields...)
+	for _, ref := range def.References {
+		fields = append(fields, ref.Field)
+	}
+	for _, field := range fields {
+		hex, ok := data[field].(string)
+		if !ok {
+			continue
+		}
+		if hex == "" {
+			data[field] = primitive.NilObjectID
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return fmt.Errorf("invalid format for %s", field)
+		}
+		data[field] = id
+	}
 	return nil
 }
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// importBatchSize is the number of documents written per InsertMany call.
+const importBatchSize = 100
+
+// importStallTimeout is how long an unfinished import job may go without progress before
+// it is marked failed. Jobs run in the process that started them, so a restart ends them.
+const importStallTimeout = 15 * time.Minute
+
+// ImportOptions controls how the columns of an import file are interpreted.
+type ImportOptions struct {
+	// Mapping renames file columns to fields, e.g. {"Office": "location"}.
+	// Columns without a mapping are matched to fields by name, ignoring case.
+	Mapping map[string]string `json:"mapping"`
+}
+
+// ImportReport is the result of validating an import file. It is returned as-is
+// for dry runs and is the input for StartImport.
+type ImportReport struct {
+	EntityType     string                  `json:"entityType"`
+	TotalRows      int                     `json:"totalRows"`
+	ValidRows      int                     `json:"validRows"`
+	Columns        map[string]string       `json:"columns"`        // File column -> field it was mapped to
+	IgnoredColumns []string                `json:"ignoredColumns"` // File columns that did not match any field
+	Errors         []models.ImportRowError `json:"errors"`
+
+	documents []interface{} // Validated documents, ready to be inserted
+}
+
+// PrepareImport maps and validates the rows of an import file for the given entity type
+// ("employees" or an entity slug). The first row must be the header. Nothing is written;
+// references given by name (e.g. a "department" column) are resolved to IDs here.
+func PrepareImport(ctx context.Context, tenantID, entityType string, rows [][]string, opts ImportOptions) (*ImportReport, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("the file is empty")
+	}
+
+	var fields []string
+	var references []Reference
+	if entityType == "employees" {
+		fields, references = EmployeeFields, EmployeeReferences
+	} else if def, ok := LookupEntity(entityType); ok {
+		fields, references = append([]string{"name"}, def.Fields...), def.References
+	} else {
+		return nil, fmt.Errorf("unknown entity type %q", entityType)
+	}
+
+	// 1. Map the header row to field names.
+	report := &ImportReport{
+		EntityType:     entityType,
+		TotalRows:      len(rows) - 1,
+		Columns:        map[string]string{},
+		IgnoredColumns: []string{},
+		Errors:         []models.ImportRowError{},
+	}
+	known := map[string]string{} // lower-case field name -> field name
+	for _, field := range fields {
+		known[strings.ToLower(field)] = field
+	}
+	for _, ref := range references {
+		known[strings.ToLower(ref.Field)] = ref.Field
+		known[strings.ToLower(ref.NameField())] = ref.NameField()
+	}
+	columnFields := make([]string, len(rows[0]))
+	for i, header := range rows[0] {
+		header = strings.TrimSpace(header)
+		target := header
+		if mapped, ok := opts.Mapping[header]; ok {
+			target = mapped
+		}
+		if field, ok := known[strings.ToLower(target)]; ok {
+			columnFields[i] = field
+			report.Columns[header] = field
+		} else if header != "" {
+			report.IgnoredColumns = append(report.IgnoredColumns, header)
+		}
+	}
+
+	// 2. Load the referenced entities once, so names and IDs can be resolved per row.
+	indexes := map[string]*nameIndex{}
+	for _, ref := range references {
+		if _, ok := indexes[ref.Slug]; ok {
+			continue
+		}
+		index, err := loadNameIndex(ctx, ref.Slug, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		indexes[ref.Slug] = index
+	}
+
+	// 3. Load the values that must stay unique within the tenant.
+	uniqueField := "name"
+	collectionName := ""
+	if entityType == "employees" {
+		uniqueField, collectionName = "email", "employees"
+	} else {
+		def, _ := LookupEntity(entityType)
+		collectionName = def.Collection
+	}
+	seen, err := loadExistingValues(ctx, collectionName, uniqueField, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Validate every data row.
+	for i, row := range rows[1:] {
+		rowNumber := i + 2 // 1-based, and the header is row 1
+		values := map[string]string{}
+		for col, cell := range row {
+			if col < len(columnFields) && columnFields[col] != "" {
+				values[columnFields[col]] = strings.TrimSpace(cell)
+			}
+		}
+		if isBlankRow(values) {
+			report.TotalRows--
+			continue
+		}
+
+		var rowErrors []models.ImportRowError
+		addError := func(field, message string) {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: rowNumber, Field: field, Message: message})
+		}
+
+		unique := strings.ToLower(values[uniqueField])
+		if unique != "" && seen[unique] {
+			addError(uniqueField, fmt.Sprintf("%s %q already exists", uniqueField, values[uniqueField]))
+		}
+
+		refIDs := map[string]primitive.ObjectID{}
+		for _, ref := range references {
+			id, err := indexes[ref.Slug].resolve(values[ref.Field], values[ref.NameField()])
+			if err != nil {
+				addError(ref.NameField(), err.Error())
+				continue
+			}
+			refIDs[ref.Field] = id
+		}
+
+		var document interface{}
+		if entityType == "employees" {
+			employee, fieldErrors := employeeFromRow(values, refIDs, tenantID)
+			for _, fe := range fieldErrors {
+				addError(fe.Field, fe.Message)
+			}
+			document = employee
+		} else {
+			document, err = entityFromRow(values, fields, refIDs, tenantID)
+			if err != nil {
+				addError("name", err.Error())
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+		if unique != "" {
+			seen[unique] = true
+		}
+		report.ValidRows++
+		report.documents = append(report.documents, document)
+	}
+
+	return report, nil
+}
+
+// StartImport records an ImportJob for a validated report and inserts its documents
+// in batches in the background. The returned job can be polled with GetImportJob.
+func StartImport(tenantID, userID, fileName string, report *ImportReport) (*models.ImportJob, error) {
+	if report.ValidRows == 0 {
+		return nil, errors.New("the file does not contain any valid rows")
+	}
+
+	job := &models.ImportJob{
+		ID:         primitive.NewObjectID(),
+		TenantID:   tenantID,
+		EntityType: report.EntityType,
+		FileName:   fileName,
+		Status:     "pending",
+		TotalRows:  report.ValidRows,
+		Errors:     []models.ImportRowError{},
+		CreatedBy:  userID,
+		CreatedAt:  primitive.NewDateTimeFromTime(time.Now()),
+	}
+	job.UpdatedAt = job.CreatedAt
+	if _, err := db.GetCollection("import_jobs").InsertOne(context.Background(), job); err != nil {
+		return nil, err
+	}
+
+	go runImport(job.ID, tenantID, report)
+	return job, nil
+}
+
+// runImport writes the documents of a report and keeps the job's progress up to date.
+func runImport(jobID primitive.ObjectID, tenantID string, report *ImportReport) {
+	ctx := context.Background()
+	jobs := db.GetCollection("import_jobs")
+
+	collectionName := "employees"
+	if def, ok := LookupEntity(report.EntityType); ok {
+		collectionName = def.Collection
+	}
+	collection := db.GetCollection(collectionName)
+
+	jobs.UpdateByID(ctx, jobID, bson.M{"$set": bson.M{"status": "running", "updatedAt": primitive.NewDateTimeFromTime(time.Now())}})
+
+	created := 0
+	for start := 0; start < len(report.documents); start += importBatchSize {
+		end := min(start+importBatchSize, len(report.documents))
+		batch := report.documents[start:end]
+
+		update := bson.M{}
+		_, err := collection.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+		created += insertedCount(batch, err)
+		if err != nil {
+			log.Printf("Import job %s: batch starting at %d failed: %v", jobID.Hex(), start, err)
+			update["$push"] = bson.M{"errors": models.ImportRowError{
+				Message: fmt.Sprintf("failed to write rows %d-%d: %v", start+1, end, err),
+			}}
+		}
+		update["$set"] = bson.M{"processedRows": end, "createdRows": created, "updatedAt": primitive.NewDateTimeFromTime(time.Now())}
+		jobs.UpdateByID(ctx, jobID, update)
+
+		publishImported(ctx, tenantID, report.EntityType, batch, err)
+	}
+
+	status := "completed"
+	if created == 0 {
+		status = "failed"
+	}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	jobs.UpdateByID(ctx, jobID, bson.M{"$set": bson.M{
+		"status":      status,
+		"updatedAt":   now,
+		"completedAt": now,
+	}})
+}
+
+// insertedCount returns how many documents of an unordered batch were written. With a bulk
+// write exception the driver still reports every attempted ID, so the rejected documents
+// are counted from its write errors.
+func insertedCount(batch []interface{}, err error) int {
+	if err == nil {
+		return len(batch)
+	}
+	if bulkErr, ok := err.(mongo.BulkWriteException); ok {
+		return len(batch) - len(bulkErr.WriteErrors)
+	}
+	return 0 // Nothing is known about which documents were written
+}
+
+// failStalledImports marks import jobs failed that stopped making progress, such as jobs
+// whose process was restarted. It runs as a recurring job.
+func failStalledImports(ctx context.Context, job *models.ScheduledJob) error {
+	cutoff := primitive.NewDateTimeFromTime(time.Now().Add(-importStallTimeout))
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := db.GetCollection("import_jobs").UpdateMany(ctx,
+		bson.M{
+			"status": bson.M{"$in": bson.A{"pending", "running"}},
+			"$or": bson.A{
+				bson.M{"updatedAt": bson.M{"$lt": cutoff}},
+				bson.M{"updatedAt": bson.M{"$exists": false}, "createdAt": bson.M{"$lt": cutoff}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{"status": "failed", "updatedAt": now, "completedAt": now},
+			"$push": bson.M{"errors": models.ImportRowError{
+				Message: "the import was interrupted, e.g. by a server restart; import the remaining rows again",
+			}},
+		})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Marked %d interrupted import jobs as failed", result.ModifiedCount)
+	}
+	return nil
+}
+
+// publishImported publishes a "created" event for every document of a batch that was
+// written. Documents rejected by the database are listed in the bulk write exception.
+func publishImported(ctx context.Context, tenantID, entityType string, batch []interface{}, err error) {
+	failed := map[int]bool{}
+	if err != nil {
+		bulkErr, ok := err.(mongo.BulkWriteException)
+		if !ok {
+			return // Nothing is known about which documents were written
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			failed[writeErr.Index] = true
+		}
+	}
+
+	for i, document := range batch {
+		if failed[i] {
+			continue
+		}
+		switch doc := document.(type) {
+		case *models.Employee:
+			events.Publish(ctx, events.EmployeeCreated{Employee: *doc})
+		case bson.M:
+			events.Publish(ctx, events.EntityCreated{TenantID: tenantID, EntityType: entityType, Entity: doc})
+		}
+	}
+}
+
+// GetImportJob fetches an import job, scoped to the tenant.
+func GetImportJob(ctx context.Context, id, tenantID string) (*models.ImportJob, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+
+	var job models.ImportJob
+	err = db.GetCollection("import_jobs").FindOne(ctx, bson.M{"_id": objID, "tenantId": tenantID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("import job not found or does not belong to this tenant")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetImportJobsByTenant lists the import jobs of a tenant, newest first.
+func GetImportJobsByTenant(ctx context.Context, tenantID string) ([]models.ImportJob, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := db.GetCollection("import_jobs").Find(ctx, bson.M{"tenantId": tenantID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []models.ImportJob{}
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// --- Row Conversion ---
+
+// employeeFromRow builds an employee from a validated set of cell values.
+func employeeFromRow(values map[string]string, refIDs map[string]primitive.ObjectID, tenantID string) (*models.Employee, []models.ImportRowError) {
+	var fieldErrors []models.ImportRowError
+	for _, field := range []string{"firstName", "lastName", "email"} {
+		if values[field] == "" {
+			fieldErrors = append(fieldErrors, models.ImportRowError{Field: field, Message: field + " is required"})
+		}
+	}
+	if email := values["email"]; email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			fieldErrors = append(fieldErrors, models.ImportRowError{Field: "email", Message: "invalid email address"})
+		}
+	}
+
+	if !refIDs["accessLevelId"].IsZero() {
+		fieldErrors = append(fieldErrors, models.ImportRowError{Field: "accessLevelId", Message: ErrAccessLevelReadOnly.Error()})
+	}
+
+	// Default to now when no date is provided, like CreateEmployeeHandler does.
+	onboardingDate := time.Now()
+	if raw := values["onboardingDate"]; raw != "" {
+		parsed, err := parseImportDate(raw)
+		if err != nil {
+			fieldErrors = append(fieldErrors, models.ImportRowError{Field: "onboardingDate", Message: err.Error()})
+		}
+		onboardingDate = parsed
+	}
+
+	return &models.Employee{
+		ID:                primitive.NewObjectID(),
+		FirstName:         values["firstName"],
+		LastName:          values["lastName"],
+		Email:             values["email"],
+		PhoneNumber:       values["phoneNumber"],
+		OnboardingDate:    primitive.NewDateTimeFromTime(onboardingDate),
+		TenantID:          tenantID,
+		LocationID:        refIDs["locationId"],
+		DepartmentID:      refIDs["departmentId"],
+		ManagerID:         refIDs["managerId"],
+		JobRoleID:         refIDs["jobRoleId"],
+		EmploymentTypeID:  refIDs["employmentTypeId"],
+		TeamID:            refIDs["teamId"],
+		CostCenterID:      refIDs["costCenterId"],
+		HardwareAssetID:   refIDs["hardwareAssetId"],
+		OnboardingBuddyID: refIDs["onboardingBuddyId"],
+	}, fieldErrors
+}
+
+// entityFromRow builds an entity document from a validated set of cell values.
+func entityFromRow(values map[string]string, fields []string, refIDs map[string]primitive.ObjectID, tenantID string) (bson.M, error) {
+	if values["name"] == "" {
+		return nil, errors.New("name is required")
+	}
+	document := bson.M{"_id": primitive.NewObjectID(), "tenantId": tenantID}
+	for _, field := range fields {
+		document[field] = values[field]
+	}
+	for field, id := range refIDs {
+		if !id.IsZero() {
+			document[field] = id
+		}
+	}
+	return document, nil
+}
+
+// parseImportDate accepts RFC3339 timestamps as well as plain dates like "2025-03-01".
+func parseImportDate(raw string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date, use YYYY-MM-DD or RFC3339")
+}
+
+func isBlankRow(values map[string]string) bool {
+	for _, value := range values {
+		if value != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// --- Reference Resolution ---
+
+// nameIndex resolves references to one entity type by ID or by name.
+type nameIndex struct {
+	slug   string
+	byName map[string][]primitive.ObjectID // lower-case name -> IDs with that name
+	names  map[primitive.ObjectID]string
+}
+
+func loadNameIndex(ctx context.Context, slug, tenantID string) (*nameIndex, error) {
+	def, ok := LookupEntity(slug)
+	if !ok {
+		return nil, fmt.Errorf("unknown entity type %q", slug)
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "name": 1})
+	cursor, err := db.GetCollection(def.Collection).Find(ctx, notDeleted(bson.M{"tenantId": tenantID}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entities []models.BaseEntity
+	if err = cursor.All(ctx, &entities); err != nil {
+		return nil, err
+	}
+
+	index := &nameIndex{slug: slug, byName: map[string][]primitive.ObjectID{}, names: map[primitive.ObjectID]string{}}
+	for _, entity := range entities {
+		key := strings.ToLower(strings.TrimSpace(entity.Name))
+		index.byName[key] = append(index.byName[key], entity.ID)
+		index.names[entity.ID] = entity.Name
+	}
+	return index, nil
+}
+
+// resolve returns the referenced ID. An explicit ID takes precedence over a name.
+// Both empty means "no reference" and yields the zero ObjectID.
+func (idx *nameIndex) resolve(id, name string) (primitive.ObjectID, error) {
+	if id != "" {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if _, exists := idx.names[objID]; err != nil || !exists {
+			return primitive.NilObjectID, fmt.Errorf("no %s with id %q", idx.slug, id)
+		}
+		return objID, nil
+	}
+	if name == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	matches := idx.byName[strings.ToLower(name)]
+	switch len(matches) {
+	case 0:
+		return primitive.NilObjectID, fmt.Errorf("no %s named %q", idx.slug, name)
+	case 1:
+		return matches[0], nil
+	default:
+		return primitive.NilObjectID, fmt.Errorf("%d %s are named %q, use the id column instead", len(matches), idx.slug, name)
+	}
+}
+
+// loadExistingValues returns the lower-cased values of field across the tenant's documents.
+func loadExistingValues(ctx context.Context, collectionName, field, tenantID string) (map[string]bool, error) {
+	values, err := db.GetCollection(collectionName).Distinct(ctx, field, notDeleted(bson.M{"tenantId": tenantID}))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if s, ok := value.(string); ok && s != "" {
+			seen[strings.ToLower(s)] = true
+		}
+	}
+	return seen, nil
+}
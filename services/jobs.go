@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"github.com/your-username/onboarding/scheduler"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// operationalRetention is how long finished import jobs, delivered webhooks and sent
+// emails are kept before the cleanup job removes them.
+const operationalRetention = 30 * 24 * time.Hour
+
+// RegisterJobs registers the services' recurring background jobs.
+// It is called once at startup, before scheduler.Start.
+func RegisterJobs() {
+	scheduler.Recurring("trial-expiry", "15 2 * * *", expireTrialTenants)
+	scheduler.Recurring("cleanup", "30 3 * * *", cleanupOperationalData)
+	scheduler.Recurring("document-retention", "45 3 * * *", applyDocumentRetention)
+	scheduler.Recurring("trash-purge", "0 4 * * *", purgeTrash)
+	scheduler.Recurring("scheduled-changes", "5 * * * *", applyScheduledChanges)
+	scheduler.Recurring("stalled-imports", "*/10 * * * *", failStalledImports)
+}
+
+// expireTrialTenants moves trial tenants older than the trial period to the "expired" status.
+func expireTrialTenants(ctx context.Context, job *models.ScheduledJob) error {
+	cutoff := time.Now().AddDate(0, 0, -config.AppConfig.TrialPeriodDays)
+	filter := bson.M{"status": "trial", "createdAt": bson.M{"$lt": primitive.NewDateTimeFromTime(cutoff)}}
+	result, err := db.GetCollection("tenants").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": "expired"}})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Expired %d trial tenants", result.ModifiedCount)
+	}
+	return nil
+}
+
+// cleanupOperationalData removes bookkeeping documents that are no longer useful.
+// Failed webhook deliveries and emails are kept, so they can still be retried.
+func cleanupOperationalData(ctx context.Context, job *models.ScheduledJob) error {
+	cutoff := primitive.NewDateTimeFromTime(time.Now().Add(-operationalRetention))
+	deletions := []struct {
+		collection string
+		filter     bson.M
+	}{
+		{"import_jobs", bson.M{"status": bson.M{"$in": []string{"completed", "failed"}}, "createdAt": bson.M{"$lt": cutoff}}},
+		{"webhook_deliveries", bson.M{"status": "delivered", "createdAt": bson.M{"$lt": cutoff}}},
+		{"email_messages", bson.M{"status": "sent", "createdAt": bson.M{"$lt": cutoff}}},
+	}
+	for _, d := range deletions {
+		if _, err := db.GetCollection(d.collection).DeleteMany(ctx, d.filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// requireMongo points db at a database of its own on MONGO_URI, which is dropped when the
+// test ends. Without MongoDB the test is skipped.
+func requireMongo(t *testing.T) {
+	t.Helper()
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		uri = "mongodb://localhost:27017"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerSelectionTimeout(2*time.Second))
+	if err == nil {
+		err = client.Ping(ctx, nil)
+	}
+	if err != nil {
+		t.Skipf("MongoDB is not available: %v", err)
+	}
+
+	previousClient, previousName := db.MongoClient, config.AppConfig.DatabaseName
+	db.MongoClient = client
+	config.AppConfig.DatabaseName = fmt.Sprintf("onboarding_services_%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		client.Database(config.AppConfig.DatabaseName).Drop(context.Background())
+		client.Disconnect(context.Background())
+		db.MongoClient, config.AppConfig.DatabaseName = previousClient, previousName
+	})
+}
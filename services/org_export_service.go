@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/your-username/onboarding/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrgChartLabels are the fields that can be shown in the boxes of an exported org chart,
+// each with the entity type its name comes from ("" for fields of the employee itself).
+var OrgChartLabels = map[string]string{
+	"name":       "",
+	"email":      "",
+	"jobRole":    "job-roles",
+	"team":       "teams",
+	"department": "departments",
+	"location":   "locations",
+}
+
+// DefaultOrgChartLabels are the lines of each box when no labels are requested.
+var DefaultOrgChartLabels = []string{"name", "jobRole"}
+
+// OrgChartExportOptions controls what an org chart export contains and how it looks.
+type OrgChartExportOptions struct {
+	Format       string             // "dot", "mermaid" or "svg"
+	DepartmentID primitive.ObjectID // Only employees of this department and the ones below it
+	LocationID   primitive.ObjectID // Only employees at this location
+	Labels       []string           // Lines of each box, keys of OrgChartLabels
+	Depth        int                // Levels of reports below the top (0 for all)
+}
+
+// ParseOrgChartLabels reads a comma-separated list of labels, such as "name,jobRole,team".
+// An empty list yields DefaultOrgChartLabels.
+func ParseOrgChartLabels(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return DefaultOrgChartLabels, nil
+	}
+	var labels []string
+	for _, label := range strings.Split(raw, ",") {
+		label = strings.TrimSpace(label)
+		if _, ok := OrgChartLabels[label]; !ok {
+			return nil, fmt.Errorf("unknown label %q, use name, email, jobRole, team, department or location", label)
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// ExportOrgChart renders the tenant's reporting tree to w as Graphviz DOT, Mermaid or SVG.
+// With a department or location filter, only the matching employees are drawn; anyone whose
+// manager is filtered out is drawn at the top. Nothing is written if loading the data fails.
+func ExportOrgChart(ctx context.Context, w io.Writer, tenantID string, opts OrgChartExportOptions) error {
+	// 1. Load and filter the employees.
+	employees, err := loadOrgEmployees(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if !opts.DepartmentID.IsZero() {
+		departments, err := orgChartDepartments(ctx, tenantID, opts.DepartmentID)
+		if err != nil {
+			return err
+		}
+		for id, employee := range employees {
+			if !departments[employee.DepartmentID] {
+				delete(employees, id)
+			}
+		}
+	}
+	if !opts.LocationID.IsZero() {
+		for id, employee := range employees {
+			if employee.LocationID != opts.LocationID {
+				delete(employees, id)
+			}
+		}
+	}
+
+	// 2. Load the names the labels refer to.
+	indexes := map[string]*nameIndex{}
+	for _, label := range opts.Labels {
+		slug := OrgChartLabels[label]
+		if slug == "" || indexes[slug] != nil {
+			continue
+		}
+		index, err := loadNameIndex(ctx, slug, tenantID)
+		if err != nil {
+			return err
+		}
+		indexes[slug] = index
+	}
+
+	// 3. Build the tree and render it.
+	_, roots := buildReportTree(employees)
+	visited := map[primitive.ObjectID]bool{}
+	tree := make([]*utils.TreeNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, orgTreeNode(pruneOrgNode(root, opts.Depth, visited), opts.Labels, indexes))
+	}
+	return utils.RenderTree(opts.Format, w, tree)
+}
+
+// orgChartDepartments returns the department and every department below it.
+func orgChartDepartments(ctx context.Context, tenantID string, rootID primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	departments, err := departmentsByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	matched := map[primitive.ObjectID]bool{}
+	for id := range departments {
+		seen := map[primitive.ObjectID]bool{}
+		for current, ok := departments[id]; ok && !seen[current.ID]; current, ok = departments[current.ParentID] {
+			if current.ID == rootID {
+				matched[id] = true
+				break
+			}
+			seen[current.ID] = true
+		}
+	}
+	return matched, nil
+}
+
+// orgTreeNode turns a reporting subtree into boxes with one line per label. Labels without
+// a value, such as the team of an employee without one, are left out.
+func orgTreeNode(node *OrgNode, labels []string, indexes map[string]*nameIndex) *utils.TreeNode {
+	tree := &utils.TreeNode{ID: node.ID.Hex()}
+	for _, label := range labels {
+		var line string
+		switch label {
+		case "name":
+			line = strings.TrimSpace(node.FirstName + " " + node.LastName)
+		case "email":
+			line = node.Email
+		case "jobRole":
+			line = indexes["job-roles"].names[node.JobRoleID]
+		case "team":
+			line = indexes["teams"].names[node.TeamID]
+		case "department":
+			line = indexes["departments"].names[node.DepartmentID]
+		case "location":
+			line = indexes["locations"].names[node.LocationID]
+		}
+		if line != "" {
+			tree.Lines = append(tree.Lines, line)
+		}
+	}
+	for _, report := range node.Reports {
+		tree.Children = append(tree.Children, orgTreeNode(report, labels, indexes))
+	}
+	return tree
+}
@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The org chart is built in memory from the whole tenant: one query for the employees (or
+// departments) and a map from parent to children. Onboarding tenants are small enough for
+// this, and it keeps cycle detection and depth limits simple.
+
+// ErrOrgCycle is returned when a change would make someone their own manager, or a
+// department its own parent, directly or through others.
+var ErrOrgCycle = errors.New("the change would create a cycle in the org chart")
+
+// ErrUnknownManager is returned when reportsToId does not name an employee of the tenant.
+var ErrUnknownManager = errors.New("reportsToId does not refer to an employee of this tenant")
+
+// OrgEmployee is the part of an employee shown in the org chart.
+type OrgEmployee struct {
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	FirstName    string             `bson:"firstName" json:"firstName"`
+	LastName     string             `bson:"lastName" json:"lastName"`
+	Email        string             `bson:"email" json:"email"`
+	LocationID   primitive.ObjectID `bson:"locationId" json:"locationId"`
+	JobRoleID    primitive.ObjectID `bson:"jobRoleId" json:"jobRoleId"`
+	DepartmentID primitive.ObjectID `bson:"departmentId" json:"departmentId"`
+	TeamID       primitive.ObjectID `bson:"teamId" json:"teamId"`
+	ReportsToID  primitive.ObjectID `bson:"reportsToId,omitempty" json:"reportsToId,omitempty"`
+}
+
+// OrgNode is an employee with their direct reports.
+type OrgNode struct {
+	OrgEmployee
+	Reports []*OrgNode `json:"reports"`
+}
+
+// DepartmentNode is a department with its sub-departments, teams and head count.
+type DepartmentNode struct {
+	ID          primitive.ObjectID `json:"id"`
+	Name        string             `json:"name"`
+	Head        string             `json:"head"`
+	ParentID    primitive.ObjectID `json:"parentId,omitempty"`
+	Teams       []models.Team      `json:"teams"`
+	Employees   int                `json:"employees"` // Employees directly in this department
+	Departments []*DepartmentNode  `json:"departments"`
+}
+
+// --- Reporting Lines ---
+
+// loadOrgEmployees returns every employee of the tenant, keyed by ID.
+func loadOrgEmployees(ctx context.Context, tenantID string) (map[primitive.ObjectID]OrgEmployee, error) {
+	opts := options.Find().SetProjection(bson.M{
+		"firstName": 1, "lastName": 1, "email": 1, "locationId": 1, "jobRoleId": 1, "departmentId": 1, "teamId": 1, "reportsToId": 1,
+	})
+	cursor, err := db.GetCollection("employees").Find(ctx, notDeleted(bson.M{"tenantId": tenantID}), opts)
+	if err != nil {
+		return nil, err
+	}
+	var employees []OrgEmployee
+	if err := cursor.All(ctx, &employees); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]OrgEmployee, len(employees))
+	for _, employee := range employees {
+		byID[employee.ID] = employee
+	}
+	return byID, nil
+}
+
+// buildReportTree links the employees to their managers. Employees whose manager is unset
+// or no longer exists are roots.
+func buildReportTree(employees map[primitive.ObjectID]OrgEmployee) (nodes map[primitive.ObjectID]*OrgNode, roots []*OrgNode) {
+	nodes = make(map[primitive.ObjectID]*OrgNode, len(employees))
+	for id, employee := range employees {
+		nodes[id] = &OrgNode{OrgEmployee: employee, Reports: []*OrgNode{}}
+	}
+	for _, node := range nodes {
+		if manager, ok := nodes[node.ReportsToID]; ok && node.ReportsToID != node.ID {
+			manager.Reports = append(manager.Reports, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	for _, node := range nodes {
+		sortOrgNodes(node.Reports)
+	}
+	sortOrgNodes(roots)
+	return nodes, roots
+}
+
+func sortOrgNodes(nodes []*OrgNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].LastName != nodes[j].LastName {
+			return nodes[i].LastName < nodes[j].LastName
+		}
+		return nodes[i].FirstName < nodes[j].FirstName
+	})
+}
+
+// pruneOrgNode copies a subtree down to depth levels of reports (0 for all). The visited
+// set guards against cycles left by data written before cycle detection existed.
+func pruneOrgNode(node *OrgNode, depth int, visited map[primitive.ObjectID]bool) *OrgNode {
+	visited[node.ID] = true
+	pruned := &OrgNode{OrgEmployee: node.OrgEmployee, Reports: []*OrgNode{}}
+	if depth == 1 {
+		return pruned
+	}
+	for _, report := range node.Reports {
+		if !visited[report.ID] {
+			pruned.Reports = append(pruned.Reports, pruneOrgNode(report, depth-1, visited))
+		}
+	}
+	return pruned
+}
+
+// GetOrgChart returns the tenant's whole reporting tree: the employees without a manager,
+// each with their reports. depth limits the levels below the roots (0 for all).
+func GetOrgChart(ctx context.Context, tenantID string, depth int) ([]*OrgNode, error) {
+	employees, err := loadOrgEmployees(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	_, roots := buildReportTree(employees)
+	visited := map[primitive.ObjectID]bool{}
+	chart := make([]*OrgNode, 0, len(roots))
+	for _, root := range roots {
+		chart = append(chart, pruneOrgNode(root, depth, visited))
+	}
+	return chart, nil
+}
+
+// GetReportingSubtree returns an employee with everyone reporting to them, directly or not.
+func GetReportingSubtree(ctx context.Context, employeeID, tenantID string, depth int) (*OrgNode, error) {
+	id, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	employees, err := loadOrgEmployees(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	nodes, _ := buildReportTree(employees)
+	node, ok := nodes[id]
+	if !ok {
+		return nil, errors.New("employee not found or does not belong to this tenant")
+	}
+	return pruneOrgNode(node, depth, map[primitive.ObjectID]bool{}), nil
+}
+
+// GetChainOfCommand returns the employee followed by their manager, their manager's
+// manager and so on up to the top.
+func GetChainOfCommand(ctx context.Context, employeeID, tenantID string) ([]OrgEmployee, error) {
+	id, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	employees, err := loadOrgEmployees(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	employee, ok := employees[id]
+	if !ok {
+		return nil, errors.New("employee not found or does not belong to this tenant")
+	}
+	chain := []OrgEmployee{employee}
+	seen := map[primitive.ObjectID]bool{id: true}
+	for {
+		manager, ok := employees[employee.ReportsToID]
+		if !ok || seen[manager.ID] {
+			return chain, nil
+		}
+		chain = append(chain, manager)
+		seen[manager.ID] = true
+		employee = manager
+	}
+}
+
+// checkReportsTo validates a new manager for an employee: the manager must be an employee
+// of the tenant, and the employee must not appear in the manager's chain of command.
+// employeeID is zero for an employee that is being created.
+func checkReportsTo(ctx context.Context, tenantID string, employeeID, managerID primitive.ObjectID) error {
+	if managerID.IsZero() {
+		return nil
+	}
+	if managerID == employeeID {
+		return ErrOrgCycle
+	}
+	employees, err := loadOrgEmployees(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	manager, ok := employees[managerID]
+	if !ok {
+		return ErrUnknownManager
+	}
+	if employeeID.IsZero() {
+		return nil
+	}
+	seen := map[primitive.ObjectID]bool{}
+	for !seen[manager.ID] {
+		if manager.ReportsToID == employeeID {
+			return ErrOrgCycle
+		}
+		seen[manager.ID] = true
+		if manager, ok = employees[manager.ReportsToID]; !ok {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ManagerOf returns the person an employee reports to: the employee named by reportsToId,
+// presented as a Manager, or else the Manager entity named by managerId. It returns nil if
+// neither is set or exists.
+func ManagerOf(ctx context.Context, employee *models.Employee) *models.Manager {
+	if !employee.ReportsToID.IsZero() {
+		var manager models.Employee
+		filter := notDeleted(bson.M{"_id": employee.ReportsToID, "tenantId": employee.TenantID})
+		if err := db.GetCollection("employees").FindOne(ctx, filter).Decode(&manager); err == nil {
+			return &models.Manager{
+				BaseEntity: models.BaseEntity{ID: manager.ID, Name: manager.FirstName + " " + manager.LastName, TenantID: manager.TenantID},
+				Email:      manager.Email,
+			}
+		}
+	}
+	if employee.ManagerID.IsZero() {
+		return nil
+	}
+	manager, err := GetEntityByID[models.Manager](ctx, "managers", employee.ManagerID.Hex(), employee.TenantID)
+	if err != nil {
+		return nil
+	}
+	return manager
+}
+
+// reassignReportsOfDeletedEmployee moves the direct reports of an offboarded employee to
+// that employee's own manager, so the reporting tree stays connected. It is subscribed to
+// employee.deleted. The moves are requested by "system"; one that an approval policy
+// holds back waits as a change request.
+func reassignReportsOfDeletedEmployee(ctx context.Context, env events.Envelope) error {
+	e, ok := env.Event.(events.EmployeeDeleted)
+	if !ok {
+		return nil
+	}
+	cursor, err := db.GetCollection("employees").Find(ctx,
+		notDeleted(bson.M{"tenantId": e.Employee.TenantID, "reportsToId": e.Employee.ID}),
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var reports []models.Employee
+	if err := cursor.All(ctx, &reports); err != nil {
+		return err
+	}
+	for _, report := range reports {
+		err := UpdateEmployee(report.ID.Hex(), e.Employee.TenantID, "system", bson.M{"reportsToId": e.Employee.ReportsToID})
+		if err != nil && !isChangeHeld(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- Departments ---
+
+// GetDepartmentTree returns the tenant's departments nested by parent, with their teams
+// and head counts. With rootID set, only that department and the ones below it are returned.
+func GetDepartmentTree(ctx context.Context, tenantID, rootID string) ([]*DepartmentNode, error) {
+	var root primitive.ObjectID
+	if rootID != "" {
+		var err error
+		if root, err = primitive.ObjectIDFromHex(rootID); err != nil {
+			return nil, errors.New("invalid id format")
+		}
+	}
+
+	// 1. Load departments, teams and head counts.
+	departments, err := GetEntitiesByTenant[models.Department](ctx, "departments", tenantID, Pagination{})
+	if err != nil {
+		return nil, err
+	}
+	teams, err := GetEntitiesByTenant[models.Team](ctx, "teams", tenantID, Pagination{})
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := db.GetCollection("employees").Aggregate(ctx, []bson.M{
+		{"$match": notDeleted(bson.M{"tenantId": tenantID})},
+		{"$group": bson.M{"_id": "$departmentId", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		DepartmentID primitive.ObjectID `bson:"_id"`
+		Count        int                `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	// 2. Link them up.
+	nodes := make(map[primitive.ObjectID]*DepartmentNode, len(departments))
+	for _, department := range departments {
+		nodes[department.ID] = &DepartmentNode{
+			ID:          department.ID,
+			Name:        department.Name,
+			Head:        department.Head,
+			ParentID:    department.ParentID,
+			Teams:       []models.Team{},
+			Departments: []*DepartmentNode{},
+		}
+	}
+	for _, team := range teams {
+		if node, ok := nodes[team.DepartmentID]; ok {
+			node.Teams = append(node.Teams, team)
+		}
+	}
+	for _, count := range counts {
+		if node, ok := nodes[count.DepartmentID]; ok {
+			node.Employees = count.Count
+		}
+	}
+	var roots []*DepartmentNode
+	for _, node := range nodes {
+		if parent, ok := nodes[node.ParentID]; ok && node.ParentID != node.ID {
+			parent.Departments = append(parent.Departments, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	for _, node := range nodes {
+		sortDepartmentNodes(node.Departments)
+	}
+	sortDepartmentNodes(roots)
+
+	if rootID == "" {
+		return roots, nil
+	}
+	node, ok := nodes[root]
+	if !ok {
+		return nil, errors.New("department not found or does not belong to this tenant")
+	}
+	return []*DepartmentNode{node}, nil
+}
+
+func sortDepartmentNodes(nodes []*DepartmentNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+}
+
+// GetDepartmentChain returns a department followed by its parent, grandparent and so on
+// up to the top-level department.
+func GetDepartmentChain(ctx context.Context, departmentID, tenantID string) ([]models.Department, error) {
+	departments, err := departmentsByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := primitive.ObjectIDFromHex(departmentID)
+	if err != nil {
+		return nil, errors.New("invalid id format")
+	}
+	department, ok := departments[id]
+	if !ok {
+		return nil, errors.New("department not found or does not belong to this tenant")
+	}
+	chain := []models.Department{department}
+	seen := map[primitive.ObjectID]bool{id: true}
+	for {
+		parent, ok := departments[department.ParentID]
+		if !ok || seen[parent.ID] {
+			return chain, nil
+		}
+		chain = append(chain, parent)
+		seen[parent.ID] = true
+		department = parent
+	}
+}
+
+func departmentsByID(ctx context.Context, tenantID string) (map[primitive.ObjectID]models.Department, error) {
+	departments, err := GetEntitiesByTenant[models.Department](ctx, "departments", tenantID, Pagination{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]models.Department, len(departments))
+	for _, department := range departments {
+		byID[department.ID] = department
+	}
+	return byID, nil
+}
+
+// CheckDepartmentParent validates a new parent for a department: the parent must be a
+// department of the tenant and must not be the department itself or one below it.
+// departmentID is empty for a department that is being created.
+func CheckDepartmentParent(ctx context.Context, tenantID, departmentID string, parentID primitive.ObjectID) error {
+	if parentID.IsZero() {
+		return nil
+	}
+	departments, err := departmentsByID(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	parent, ok := departments[parentID]
+	if !ok {
+		return errors.New("parentId does not refer to a department of this tenant")
+	}
+	if departmentID == "" {
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(departmentID)
+	if err != nil {
+		return errors.New("invalid id format")
+	}
+	seen := map[primitive.ObjectID]bool{}
+	for !seen[parent.ID] {
+		if parent.ID == id {
+			return ErrOrgCycle
+		}
+		seen[parent.ID] = true
+		if parent, ok = departments[parent.ParentID]; !ok {
+			return nil
+		}
+	}
+	return nil
+}
+
+// DeleteDepartment moves a department to the trash. Its sub-departments move up to its
+// parent, and its teams are left without a department; restoring it does not undo that.
+// A move that an approval policy holds back waits as a change request of the user.
+func DeleteDepartment(ctx context.Context, id, tenantID, userID string) error {
+	department, err := GetEntityByID[models.Department](ctx, "departments", id, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := DeleteEntity[models.Department](ctx, "departments", id, tenantID, userID); err != nil {
+		return err
+	}
+
+	children, err := db.GetCollection("departments").Find(ctx, notDeleted(bson.M{"tenantId": tenantID, "parentId": department.ID}))
+	if err != nil {
+		return err
+	}
+	var subDepartments []models.Department
+	if err := children.All(ctx, &subDepartments); err != nil {
+		return err
+	}
+	for _, child := range subDepartments {
+		err := UpdateEntity[models.Department](ctx, "departments", child.ID.Hex(), tenantID, userID, bson.M{"parentId": department.ParentID})
+		if err != nil && !isChangeHeld(err) {
+			return err
+		}
+	}
+
+	teams, err := db.GetCollection("teams").Find(ctx, notDeleted(bson.M{"tenantId": tenantID, "departmentId": department.ID}))
+	if err != nil {
+		return err
+	}
+	var orphans []models.Team
+	if err := teams.All(ctx, &orphans); err != nil {
+		return err
+	}
+	for _, team := range orphans {
+		err := UpdateEntity[models.Team](ctx, "teams", team.ID.Hex(), tenantID, userID, bson.M{"departmentId": primitive.NilObjectID})
+		if err != nil && !isChangeHeld(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/auth"
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PortalLinkLifetime is how long a magic link can be used to open the portal.
+const PortalLinkLifetime = 7 * 24 * time.Hour
+
+// --- Magic Links ---
+
+// CreatePortalLink issues a new magic link for an employee and revokes the previous ones.
+// It returns the link and the token to put into the URL; the token is not stored.
+func CreatePortalLink(ctx context.Context, employeeID, tenantID, createdBy string) (*models.PortalLink, string, error) {
+	employee, err := GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return nil, "", errors.New("employee not found or does not belong to this tenant")
+	}
+	if employee.Email == "" {
+		return nil, "", errors.New("the employee has no email address to send the link to")
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	links := db.GetCollection("portal_links")
+	now := time.Now()
+	_, err = links.UpdateMany(ctx,
+		bson.M{"tenantId": tenantID, "employeeId": employee.ID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": primitive.NewDateTimeFromTime(now)}})
+	if err != nil {
+		return nil, "", err
+	}
+
+	link := &models.PortalLink{
+		ID:         primitive.NewObjectID(),
+		TenantID:   tenantID,
+		EmployeeID: employee.ID,
+		TokenHash:  hashPortalToken(token),
+		CreatedBy:  createdBy,
+		CreatedAt:  primitive.NewDateTimeFromTime(now),
+		ExpiresAt:  primitive.NewDateTimeFromTime(now.Add(PortalLinkLifetime)),
+	}
+	if _, err := links.InsertOne(ctx, link); err != nil {
+		return nil, "", err
+	}
+	return link, token, nil
+}
+
+// PortalURL returns the magic link URL for a token.
+func PortalURL(token string) string {
+	return config.AppConfig.PortalURL + "?token=" + url.QueryEscape(token)
+}
+
+// RevokePortalLinks invalidates all magic links of an employee. Portal sessions that were
+// already started end when their token expires.
+func RevokePortalLinks(ctx context.Context, employeeID, tenantID string) error {
+	objID, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return errors.New("invalid id format")
+	}
+	_, err = db.GetCollection("portal_links").UpdateMany(ctx,
+		bson.M{"tenantId": tenantID, "employeeId": objID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": primitive.NewDateTimeFromTime(time.Now())}})
+	return err
+}
+
+// StartPortalSession exchanges the token of a magic link for a short-lived portal token.
+// A link can be used any number of times until it expires or is revoked.
+func StartPortalSession(ctx context.Context, token string) (string, time.Time, error) {
+	now := time.Now()
+	filter := bson.M{
+		"tokenHash": hashPortalToken(token),
+		"revokedAt": bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": primitive.NewDateTimeFromTime(now)},
+	}
+	var link models.PortalLink
+	err := db.GetCollection("portal_links").FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"lastUsedAt": primitive.NewDateTimeFromTime(now)}}).Decode(&link)
+	if err != nil {
+		return "", time.Time{}, errors.New("the link is invalid or has expired")
+	}
+
+	// The employee may have been deleted since the link was sent.
+	if _, err := GetEmployeeByID(link.EmployeeID.Hex(), link.TenantID); err != nil {
+		return "", time.Time{}, errors.New("the link is invalid or has expired")
+	}
+	return auth.GeneratePortalToken(link.EmployeeID.Hex(), link.TenantID)
+}
+
+// hashPortalToken returns the hex SHA-256 of a magic link token. The tokens are random,
+// so an unsalted hash is enough to keep a database leak from exposing usable links.
+func hashPortalToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// --- Portal Profile ---
+
+// PortalContact is a person or place shown to a new hire, without internal references.
+type PortalContact struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// PortalProfile is what a new hire sees about their own onboarding.
+type PortalProfile struct {
+	FirstName        string                   `json:"firstName"`
+	LastName         string                   `json:"lastName"`
+	Email            string                   `json:"email"`
+	PhoneNumber      string                   `json:"phoneNumber"`
+	OnboardingDate   primitive.DateTime       `json:"onboardingDate"`
+	EmergencyContact *models.EmergencyContact `json:"emergencyContact,omitempty"`
+	Location         *PortalContact           `json:"location,omitempty"`
+	Manager          *PortalContact           `json:"manager,omitempty"`
+	Buddy            *PortalContact           `json:"buddy,omitempty"`
+	Department       *PortalContact           `json:"department,omitempty"`
+	Team             *PortalContact           `json:"team,omitempty"`
+	JobRole          *PortalContact           `json:"jobRole,omitempty"`
+}
+
+// GetPortalProfile gathers the onboarding details of an employee for the portal.
+// References that are unset or no longer exist are left out.
+func GetPortalProfile(ctx context.Context, employeeID, tenantID string) (*PortalProfile, error) {
+	employee, err := GetEmployeeByID(employeeID, tenantID)
+	if err != nil {
+		return nil, errors.New("employee not found or does not belong to this tenant")
+	}
+
+	profile := &PortalProfile{
+		FirstName:        employee.FirstName,
+		LastName:         employee.LastName,
+		Email:            employee.Email,
+		PhoneNumber:      employee.PhoneNumber,
+		OnboardingDate:   employee.OnboardingDate,
+		EmergencyContact: employee.EmergencyContact,
+	}
+	if location := portalLookup[models.Location](ctx, "locations", employee.LocationID, tenantID); location != nil {
+		profile.Location = &PortalContact{Name: location.Name, Address: location.Address}
+	}
+	if manager := ManagerOf(ctx, employee); manager != nil {
+		profile.Manager = &PortalContact{Name: manager.Name, Email: manager.Email}
+	}
+	if buddy := portalLookup[models.OnboardingBuddy](ctx, "onboarding_buddies", employee.OnboardingBuddyID, tenantID); buddy != nil {
+		profile.Buddy = &PortalContact{Name: buddy.Name, Email: buddy.Email}
+	}
+	if department := portalLookup[models.Department](ctx, "departments", employee.DepartmentID, tenantID); department != nil {
+		profile.Department = &PortalContact{Name: department.Name}
+	}
+	if team := portalLookup[models.Team](ctx, "teams", employee.TeamID, tenantID); team != nil {
+		profile.Team = &PortalContact{Name: team.Name}
+	}
+	if jobRole := portalLookup[models.JobRole](ctx, "job_roles", employee.JobRoleID, tenantID); jobRole != nil {
+		profile.JobRole = &PortalContact{Name: jobRole.Name}
+	}
+	return profile, nil
+}
+
+// portalLookup fetches a referenced entity, or returns nil if the reference is unset or dangling.
+func portalLookup[T Entity](ctx context.Context, collectionName string, id primitive.ObjectID, tenantID string) *T {
+	if id.IsZero() {
+		return nil
+	}
+	entity, err := GetEntityByID[T](ctx, collectionName, id.Hex(), tenantID)
+	if err != nil {
+		return nil
+	}
+	return entity
+}
+
+// PortalProfileInput holds the fields a new hire may change. Omitted fields are left as they are.
+type PortalProfileInput struct {
+	PhoneNumber      *string                  `json:"phoneNumber"`
+	EmergencyContact *models.EmergencyContact `json:"emergencyContact"`
+}
+
+// UpdatePortalProfile saves the personal details a new hire entered on the portal.
+func UpdatePortalProfile(ctx context.Context, employeeID, tenantID string, input *PortalProfileInput) error {
+	update := bson.M{}
+	if input.PhoneNumber != nil {
+		phone := strings.TrimSpace(*input.PhoneNumber)
+		if !validPhoneNumber(phone) {
+			return errors.New("phoneNumber may only contain digits, spaces and + - ( )")
+		}
+		update["phoneNumber"] = phone
+	}
+	if contact := input.EmergencyContact; contact != nil {
+		contact.Name = strings.TrimSpace(contact.Name)
+		contact.Relationship = strings.TrimSpace(contact.Relationship)
+		contact.PhoneNumber = strings.TrimSpace(contact.PhoneNumber)
+		if contact.Name == "" || contact.PhoneNumber == "" {
+			return errors.New("emergencyContact needs a name and a phoneNumber")
+		}
+		if !validPhoneNumber(contact.PhoneNumber) {
+			return errors.New("emergencyContact.phoneNumber may only contain digits, spaces and + - ( )")
+		}
+		update["emergencyContact"] = contact
+	}
+	if len(update) == 0 {
+		return errors.New("nothing to update")
+	}
+	return UpdateEmployee(employeeID, tenantID, "portal", update)
+}
+
+// validPhoneNumber accepts the usual ways of writing a phone number, e.g. "+49 (30) 123-456".
+// An empty number is valid, so that it can be cleared.
+func validPhoneNumber(phone string) bool {
+	if phone == "" {
+		return true
+	}
+	if len(phone) > 32 {
+		return false
+	}
+	digits := 0
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case strings.ContainsRune(" +-()", r):
+		default:
+			return false
+		}
+	}
+	return digits >= 3
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Scheduled change statuses besides those of change requests. A change is applying while
+// the job applies it, and can no longer be cancelled then. A change that an approval
+// policy holds back is held until its change request ends, and then takes the outcome of
+// the request.
+const (
+	ChangeApplying = "applying"
+	ChangeHeld     = "held"
+)
+
+// ErrScheduledChangeNotFound is returned for scheduled changes that do not exist for the employee.
+var ErrScheduledChangeNotFound = errors.New("scheduled change not found or does not belong to this tenant")
+
+// EnsureScheduledChangeIndexes creates the indexes the job and the employee lookups use.
+func EnsureScheduledChangeIndexes(ctx context.Context) error {
+	_, err := db.GetCollection("scheduled_changes").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "effectiveDate", Value: 1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "employeeId", Value: 1}, {Key: "effectiveDate", Value: 1}}},
+	})
+	return err
+}
+
+// --- Scheduling ---
+
+// ScheduledChangeInput describes an update of an employee that takes effect on a later day.
+type ScheduledChangeInput struct {
+	EffectiveDate string                 `json:"effectiveDate" binding:"required"` // YYYY-MM-DD
+	Changes       map[string]interface{} `json:"changes" binding:"required"`
+	Comment       string                 `json:"comment"`
+}
+
+// validate checks the date and the fields, and returns the start of the effective day.
+// Only the plain fields and the references of employees may be scheduled. The onboarding
+// date is stored as a date, like on create.
+func (in *ScheduledChangeInput) validate(now time.Time) (time.Time, error) {
+	day, err := time.Parse("2006-01-02", in.EffectiveDate)
+	if err != nil {
+		return time.Time{}, errors.New("effectiveDate must be a date such as 2026-03-01")
+	}
+	if !day.After(now.UTC().Truncate(24 * time.Hour)) {
+		return time.Time{}, errors.New("effectiveDate must be after today; update the employee directly instead")
+	}
+	if len(in.Changes) == 0 {
+		return time.Time{}, errors.New("changes must name at least one field")
+	}
+	for field, value := range in.Changes {
+		switch {
+		case field == "accessLevelId":
+			return time.Time{}, ErrAccessLevelReadOnly
+		case field == "onboardingDate":
+			date, err := dateValue(value)
+			if err != nil {
+				return time.Time{}, errors.New("onboardingDate must be a date such as 2026-03-01 or an RFC3339 timestamp")
+			}
+			in.Changes[field] = date
+		case containsString(EmployeeFields, field):
+			if _, ok := value.(string); !ok {
+				return time.Time{}, fmt.Errorf("%s must be a string", field)
+			}
+		case field == "reportsToId" || isEmployeeReference(field):
+			if _, err := ObjectIDValue(value); err != nil {
+				return time.Time{}, fmt.Errorf("invalid format for %s", field)
+			}
+		default:
+			return time.Time{}, fmt.Errorf("%s cannot be scheduled", field)
+		}
+	}
+	in.Comment = strings.TrimSpace(in.Comment)
+	return day, nil
+}
+
+// isEmployeeReference reports whether a field is one of EmployeeReferences.
+func isEmployeeReference(field string) bool {
+	for _, ref := range EmployeeReferences {
+		if ref.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduleEmployeeChange stores a pending change of an employee. Several changes may be
+// scheduled for the same day; they are applied in the order they were scheduled.
+func ScheduleEmployeeChange(ctx context.Context, tenantID, employeeID, userID string, in *ScheduledChangeInput) (*models.ScheduledChange, error) {
+	now := time.Now()
+	day, err := in.validate(now)
+	if err != nil {
+		return nil, err
+	}
+	id, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return nil, ErrEmployeeNotFound
+	}
+	count, err := db.GetCollection("employees").CountDocuments(ctx, notDeleted(bson.M{"_id": id, "tenantId": tenantID}))
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrEmployeeNotFound
+	}
+
+	change := models.ScheduledChange{
+		ID:            primitive.NewObjectID(),
+		TenantID:      tenantID,
+		EmployeeID:    id,
+		Changes:       in.Changes,
+		EffectiveDate: primitive.NewDateTimeFromTime(day),
+		Comment:       in.Comment,
+		Status:        ApprovalPending,
+		CreatedBy:     userID,
+		CreatedAt:     primitive.NewDateTimeFromTime(now),
+	}
+	if _, err := db.GetCollection("scheduled_changes").InsertOne(ctx, change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// scheduledChangesOf matches the scheduled changes of an employee of the tenant.
+func scheduledChangesOf(tenantID, employeeID string) (bson.M, error) {
+	id, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return nil, ErrEmployeeNotFound
+	}
+	return bson.M{"tenantId": tenantID, "employeeId": id}, nil
+}
+
+// GetScheduledChanges lists the scheduled changes of an employee by effective date,
+// optionally only those with a status.
+func GetScheduledChanges(ctx context.Context, tenantID, employeeID, status string) ([]models.ScheduledChange, error) {
+	query, err := scheduledChangesOf(tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if status != "" {
+		query["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "effectiveDate", Value: 1}, {Key: "createdAt", Value: 1}})
+	cursor, err := db.GetCollection("scheduled_changes").Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	changes := []models.ScheduledChange{}
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// CancelScheduledChange withdraws a change that has not taken effect yet. Only the user
+// who scheduled it and admins may cancel.
+func CancelScheduledChange(ctx context.Context, tenantID, employeeID, id, userID string) (*models.ScheduledChange, error) {
+	query, err := scheduledChangesOf(tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrScheduledChangeNotFound
+	}
+	query["_id"] = objID
+	var change models.ScheduledChange
+	if err := db.GetCollection("scheduled_changes").FindOne(ctx, query).Decode(&change); err != nil {
+		return nil, ErrScheduledChangeNotFound
+	}
+	if change.Status != ApprovalPending {
+		return nil, fmt.Errorf("the change is %s and can no longer be cancelled", change.Status)
+	}
+	if change.CreatedBy != userID {
+		if role, err := userRole(ctx, userID, tenantID); err != nil || role != "admin" {
+			return nil, ErrRoleForbidden
+		}
+	}
+
+	// Matching on the status makes sure the job has not started to apply the change.
+	query["status"] = ApprovalPending
+	err = db.GetCollection("scheduled_changes").FindOneAndUpdate(ctx, query,
+		bson.M{"$set": bson.M{
+			"status":      ChangeCancelled,
+			"cancelledBy": userID,
+			"cancelledAt": primitive.NewDateTimeFromTime(time.Now()),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&change)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.New("the change took effect in the meantime and can no longer be cancelled")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// deleteScheduledChanges removes the scheduled changes of employees that are purged.
+func deleteScheduledChanges(ctx context.Context, tenantID string, employeeIDs []primitive.ObjectID) error {
+	_, err := db.GetCollection("scheduled_changes").DeleteMany(ctx, bson.M{"tenantId": tenantID, "employeeId": bson.M{"$in": employeeIDs}})
+	return err
+}
+
+// --- Applying ---
+
+// applyScheduledChanges applies the pending changes whose effective date has come, through
+// the same update as the update endpoint, so each one adds a version to the employee's
+// history and passes the approval policies on behalf of the user who scheduled it. It runs
+// as a recurring job. A change of a deleted employee, or one the update refuses, ends up
+// failed; one that a policy holds back is held with its change request.
+func applyScheduledChanges(ctx context.Context, job *models.ScheduledJob) error {
+	changes := db.GetCollection("scheduled_changes")
+	// Changes left applying by an earlier run that was interrupted are applied again;
+	// the update only sets fields, so that is safe.
+	due := bson.M{
+		"status":        bson.M{"$in": bson.A{ApprovalPending, ChangeApplying}},
+		"effectiveDate": bson.M{"$lte": primitive.NewDateTimeFromTime(time.Now())},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "effectiveDate", Value: 1}, {Key: "createdAt", Value: 1}})
+	cursor, err := changes.Find(ctx, due, opts)
+	if err != nil {
+		return err
+	}
+	var pending []models.ScheduledChange
+	if err := cursor.All(ctx, &pending); err != nil {
+		return err
+	}
+
+	var applied, failed, heldBack int
+	for _, change := range pending {
+		// 1. Claim the change, unless it was cancelled in the meantime.
+		if change.Status == ApprovalPending {
+			result, err := changes.UpdateOne(ctx, bson.M{"_id": change.ID, "status": ApprovalPending},
+				bson.M{"$set": bson.M{"status": ChangeApplying}})
+			if err != nil {
+				return err
+			}
+			if result.ModifiedCount == 0 {
+				continue
+			}
+		}
+
+		// 2. Apply it, and record the outcome.
+		data := bson.M{}
+		for field, value := range change.Changes {
+			data[field] = value
+		}
+		outcome := bson.M{"status": ChangeApplied, "appliedAt": primitive.NewDateTimeFromTime(time.Now())}
+		err := UpdateEmployee(change.EmployeeID.Hex(), change.TenantID, change.CreatedBy, data)
+		var held *ChangeHeldError
+		if errors.As(err, &held) {
+			outcome = bson.M{"status": ChangeHeld, "changeRequestId": held.Request.ID}
+			heldBack++
+		} else if err != nil {
+			if errors.Is(err, ErrEmployeeNotFound) {
+				err = errors.New("the employee no longer exists")
+			}
+			outcome = bson.M{"status": ChangeFailed, "error": err.Error()}
+			failed++
+		} else {
+			applied++
+		}
+		if _, err := changes.UpdateOne(ctx, bson.M{"_id": change.ID}, bson.M{"$set": outcome}); err != nil {
+			return err
+		}
+	}
+	if applied+failed+heldBack > 0 {
+		log.Printf("Applied %d scheduled employee changes, %d failed, %d wait for approval", applied, failed, heldBack)
+	}
+	return nil
+}
+
+// settleHeldScheduledChange gives a held scheduled change the outcome of its change
+// request once the request has ended.
+func settleHeldScheduledChange(ctx context.Context, request *models.ChangeRequest) error {
+	var outcome bson.M
+	switch request.Status {
+	case ChangeApplied:
+		outcome = bson.M{"status": ChangeApplied, "appliedAt": request.AppliedAt}
+	case ChangeFailed:
+		outcome = bson.M{"status": ChangeFailed, "error": request.Error}
+	case ApprovalRejected:
+		outcome = bson.M{"status": ChangeFailed, "error": "the change was rejected"}
+	case ChangeCancelled:
+		outcome = bson.M{"status": ChangeCancelled}
+	default:
+		return nil
+	}
+	_, err := db.GetCollection("scheduled_changes").UpdateOne(ctx,
+		bson.M{"tenantId": request.TenantID, "changeRequestId": request.ID, "status": ChangeHeld},
+		bson.M{"$set": outcome})
+	return err
+}
+
+// --- Timeline ---
+
+// TimelineEntry is one event in the timeline of an employee: a version of the record, or a
+// scheduled change that has not taken effect.
+type TimelineEntry struct {
+	Date            time.Time               `json:"date"`
+	Kind            string                  `json:"kind"` // "version" or "scheduled"
+	Version         *models.RecordVersion   `json:"version,omitempty"`
+	ScheduledChange *models.ScheduledChange `json:"scheduledChange,omitempty"`
+}
+
+// GetEmployeeTimeline combines the history of an employee with the changes scheduled for
+// it, newest first, so upcoming changes lead. Applied changes appear as the versions they
+// produced and cancelled ones are left out; held and failed ones stay visible.
+func GetEmployeeTimeline(ctx context.Context, tenantID, employeeID string) ([]TimelineEntry, error) {
+	query, err := scheduledChangesOf(tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := db.GetCollection("employees").CountDocuments(ctx, bson.M{"_id": query["employeeId"], "tenantId": tenantID})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrEmployeeNotFound
+	}
+
+	versions, err := GetVersions(ctx, tenantID, "employees", employeeID, Pagination{})
+	if err != nil {
+		return nil, err
+	}
+	query["status"] = bson.M{"$in": bson.A{ApprovalPending, ChangeApplying, ChangeHeld, ChangeFailed}}
+	cursor, err := db.GetCollection("scheduled_changes").Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var scheduled []models.ScheduledChange
+	if err := cursor.All(ctx, &scheduled); err != nil {
+		return nil, err
+	}
+
+	timeline := make([]TimelineEntry, 0, len(versions)+len(scheduled))
+	for i := range versions {
+		timeline = append(timeline, TimelineEntry{Date: versions[i].ChangedAt.Time().UTC(), Kind: "version", Version: &versions[i]})
+	}
+	for i := range scheduled {
+		timeline = append(timeline, TimelineEntry{Date: scheduled[i].EffectiveDate.Time().UTC(), Kind: "scheduled", ScheduledChange: &scheduled[i]})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].Date.After(timeline[j].Date) })
+	return timeline, nil
+}
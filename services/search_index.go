@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchIndexTTL is how long a tenant's embedded index is used before it is loaded again,
+// which picks up writes that published no event, such as a tenant import.
+const searchIndexTTL = 10 * time.Minute
+
+// memorySearch is the embedded search index. It holds the searched fields of every document
+// of a tenant, loaded on the tenant's first search and kept up to date through the event
+// bus, and answers without querying MongoDB. It only sees the events of its own process, so
+// it suits deployments with a single instance.
+type memorySearch struct {
+	mu      sync.RWMutex
+	tenants map[string]*tenantSearchIndex
+}
+
+// tenantSearchIndex holds the documents of one tenant by type and ID.
+type tenantSearchIndex struct {
+	loadedAt time.Time
+	docs     map[string]map[primitive.ObjectID]searchDocument
+}
+
+func newMemorySearch() *memorySearch {
+	return &memorySearch{tenants: map[string]*tenantSearchIndex{}}
+}
+
+func (m *memorySearch) candidates(ctx context.Context, tenantID string, source searchSource, terms []string) ([]searchDocument, error) {
+	index, err := m.tenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	docs := make([]searchDocument, 0, len(index.docs[source.Type]))
+	for _, doc := range index.docs[source.Type] {
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// tenant returns the index of a tenant, loading it if it is missing or too old.
+func (m *memorySearch) tenant(ctx context.Context, tenantID string) (*tenantSearchIndex, error) {
+	m.mu.RLock()
+	index, ok := m.tenants[tenantID]
+	m.mu.RUnlock()
+	if ok && time.Since(index.loadedAt) < searchIndexTTL {
+		return index, nil
+	}
+
+	index = &tenantSearchIndex{loadedAt: time.Now(), docs: map[string]map[primitive.ObjectID]searchDocument{}}
+	for _, source := range searchSources() {
+		cursor, err := db.GetCollection(source.Collection).Find(ctx, notDeleted(bson.M{"tenantId": tenantID}),
+			options.Find().SetProjection(searchProjection(source)))
+		if err != nil {
+			return nil, err
+		}
+		var raws []bson.M
+		if err := cursor.All(ctx, &raws); err != nil {
+			return nil, err
+		}
+		docs := make(map[primitive.ObjectID]searchDocument, len(raws))
+		for _, raw := range raws {
+			doc := toSearchDocument(source, raw)
+			docs[doc.ID] = doc
+		}
+		index.docs[source.Type] = docs
+	}
+	m.mu.Lock()
+	m.tenants[tenantID] = index
+	m.mu.Unlock()
+	return index, nil
+}
+
+// refresh reloads one document of a loaded tenant, or drops it if it no longer exists.
+// Tenants that are not loaded yet are left alone; they are read in full on their first search.
+func (m *memorySearch) refresh(ctx context.Context, tenantID, sourceType string, id primitive.ObjectID) error {
+	m.mu.RLock()
+	_, loaded := m.tenants[tenantID]
+	m.mu.RUnlock()
+	if !loaded {
+		return nil
+	}
+	var source searchSource
+	for _, candidate := range searchSources() {
+		if candidate.Type == sourceType {
+			source = candidate
+		}
+	}
+	if source.Collection == "" {
+		return nil
+	}
+
+	var raw bson.M
+	err := db.GetCollection(source.Collection).FindOne(ctx, notDeleted(bson.M{"_id": id, "tenantId": tenantID}),
+		options.FindOne().SetProjection(searchProjection(source))).Decode(&raw)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	index, ok := m.tenants[tenantID]
+	if !ok {
+		return nil
+	}
+	if index.docs[source.Type] == nil {
+		index.docs[source.Type] = map[primitive.ObjectID]searchDocument{}
+	}
+	if err == mongo.ErrNoDocuments {
+		delete(index.docs[source.Type], id)
+	} else {
+		index.docs[source.Type][id] = toSearchDocument(source, raw)
+	}
+	return nil
+}
+
+// searchProjection selects the searched fields of a source.
+func searchProjection(source searchSource) bson.M {
+	projection := bson.M{}
+	for _, field := range source.Fields {
+		projection[field.Name] = 1
+	}
+	return projection
+}
+
+// updateSearchIndex keeps the embedded search index up to date with employee and entity
+// changes. It does nothing while the MongoDB backend is in use.
+func updateSearchIndex(ctx context.Context, env events.Envelope) error {
+	index, ok := search.(*memorySearch)
+	if !ok {
+		return nil
+	}
+	switch e := env.Event.(type) {
+	case events.EmployeeCreated:
+		return index.refresh(ctx, env.TenantID, "employees", e.Employee.ID)
+	case events.EmployeeDeleted:
+		return index.refresh(ctx, env.TenantID, "employees", e.Employee.ID)
+	case events.EmployeeRestored:
+		return index.refresh(ctx, env.TenantID, "employees", e.Employee.ID)
+	case events.EmployeeUpdated:
+		id, err := primitive.ObjectIDFromHex(e.EmployeeID)
+		if err != nil {
+			return nil
+		}
+		return index.refresh(ctx, env.TenantID, "employees", id)
+	case events.EntityCreated:
+		id, err := ObjectIDValue(e.Entity["_id"])
+		if err != nil {
+			return nil
+		}
+		return index.refresh(ctx, env.TenantID, e.EntityType, id)
+	case events.EntityRestored:
+		id, err := ObjectIDValue(e.Entity["_id"])
+		if err != nil {
+			return nil
+		}
+		return index.refresh(ctx, env.TenantID, e.EntityType, id)
+	case events.EntityUpdated:
+		id, err := primitive.ObjectIDFromHex(e.EntityID)
+		if err != nil {
+			return nil
+		}
+		return index.refresh(ctx, env.TenantID, e.EntityType, id)
+	case events.EntityDeleted:
+		id, err := primitive.ObjectIDFromHex(e.EntityID)
+		if err != nil {
+			return nil
+		}
+		return index.refresh(ctx, env.TenantID, e.EntityType, id)
+	}
+	return nil
+}
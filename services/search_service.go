@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// DefaultSearchLimit is the number of hits returned per type by default.
+	DefaultSearchLimit = 10
+	// MaxSearchLimit is the largest number of hits returned per type.
+	MaxSearchLimit = 50
+	// MaxSearchQueryLength is the longest query accepted, in characters.
+	MaxSearchQueryLength = 200
+	// MaxSearchTerms is the largest number of terms in a query. Every term adds a regular
+	// expression per field to the candidate query.
+	MaxSearchTerms = 8
+	// searchCandidates is how many documents per type and query are ranked at most.
+	searchCandidates = 200
+	// minFuzzyLength is the shortest search term that may match with a typo.
+	minFuzzyLength = 4
+)
+
+// ErrEmptySearch is returned for a query without any letters or digits.
+var ErrEmptySearch = errors.New("query must contain letters or digits")
+
+// ErrSearchTooLong is returned for a query above MaxSearchQueryLength or MaxSearchTerms.
+var ErrSearchTooLong = fmt.Errorf("query may have at most %d characters and %d terms", MaxSearchQueryLength, MaxSearchTerms)
+
+// SearchHit is one employee or entity found by a search.
+type SearchHit struct {
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`              // The name
+	Subtitle string  `json:"subtitle,omitempty"` // e.g. the email of an employee
+	Field    string  `json:"field"`              // The field that matched best
+	Score    float64 `json:"score"`              // From 0 to 1; 1 is an exact match of every term
+}
+
+// SearchGroup holds the hits of one type, best first.
+type SearchGroup struct {
+	Type string      `json:"type"` // "employees" or an entity slug
+	Hits []SearchHit `json:"hits"`
+}
+
+// SearchResult is the answer to a search, with the type of the best hit first.
+type SearchResult struct {
+	Query  string        `json:"query"`
+	Groups []SearchGroup `json:"groups"`
+}
+
+// searchField is a field that is searched, weighted by how much a match in it counts.
+type searchField struct {
+	Name   string
+	Weight float64
+}
+
+// searchSource is a collection that is searched.
+type searchSource struct {
+	Type       string
+	Collection string
+	Fields     []searchField
+}
+
+// searchDocument is a candidate hit: the searched fields of one document.
+type searchDocument struct {
+	ID     primitive.ObjectID
+	Values map[string]string
+}
+
+// title and subtitle return the lines a hit is shown with.
+func (s searchSource) title(doc searchDocument) string {
+	if s.Type == "employees" {
+		return strings.TrimSpace(doc.Values["firstName"] + " " + doc.Values["lastName"])
+	}
+	return doc.Values["name"]
+}
+
+func (s searchSource) subtitle(doc searchDocument) string {
+	for _, field := range s.Fields {
+		if field.Name != "name" && field.Name != "firstName" && field.Name != "lastName" && doc.Values[field.Name] != "" {
+			return doc.Values[field.Name]
+		}
+	}
+	return ""
+}
+
+// searchSources returns the employees and every entity type, in the order of the router.
+// Names count most, the other text fields of an entity least.
+func searchSources() []searchSource {
+	sources := []searchSource{{
+		Type:       "employees",
+		Collection: "employees",
+		Fields: []searchField{
+			{Name: "firstName", Weight: 1}, {Name: "lastName", Weight: 1},
+			{Name: "email", Weight: 0.8}, {Name: "phoneNumber", Weight: 0.6},
+		},
+	}}
+	for _, def := range EntityDefinitions {
+		source := searchSource{Type: def.Slug, Collection: def.Collection, Fields: []searchField{{Name: "name", Weight: 1}}}
+		for _, field := range def.Fields {
+			source.Fields = append(source.Fields, searchField{Name: field, Weight: 0.5})
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// --- Backends ---
+
+// searchBackend finds the documents that may match the terms. Search ranks them, so a
+// backend may return more than the matches, but must not miss any.
+type searchBackend interface {
+	candidates(ctx context.Context, tenantID string, source searchSource, terms []string) ([]searchDocument, error)
+}
+
+// search is the backend chosen by InitSearch.
+var search searchBackend = mongoSearch{}
+
+// InitSearch creates the text indexes of the searched collections and sets up the backend
+// configured by SEARCH_INDEX: "mongo" (the default) queries the collections, "memory"
+// keeps an embedded index of every tenant in the process.
+func InitSearch(ctx context.Context) error {
+	for _, source := range searchSources() {
+		keys := bson.D{}
+		weights := bson.D{}
+		for _, field := range source.Fields {
+			keys = append(keys, bson.E{Key: field.Name, Value: "text"})
+			weights = append(weights, bson.E{Key: field.Name, Value: int(field.Weight * 10)})
+		}
+		_, err := db.GetCollection(source.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    keys,
+			Options: options.Index().SetName("search_text").SetWeights(weights).SetDefaultLanguage("none"),
+		})
+		if err != nil {
+			return fmt.Errorf("creating the search index of %s: %w", source.Collection, err)
+		}
+	}
+
+	switch config.AppConfig.SearchIndex {
+	case "", "mongo":
+		search = mongoSearch{}
+	case "memory":
+		search = newMemorySearch()
+	default:
+		return fmt.Errorf("unknown SEARCH_INDEX %q, use \"mongo\" or \"memory\"", config.AppConfig.SearchIndex)
+	}
+	return nil
+}
+
+// mongoSearch finds whole words through the text index of each collection, and prefixes
+// and typos through a pattern scan of the tenant's documents. Whole words come from the
+// index so that they are never crowded out by the candidate limit of the scan.
+type mongoSearch struct{}
+
+func (mongoSearch) candidates(ctx context.Context, tenantID string, source searchSource, terms []string) ([]searchDocument, error) {
+	collection := db.GetCollection(source.Collection)
+	opts := options.Find().SetProjection(searchProjection(source)).SetLimit(searchCandidates)
+
+	// 1. Whole words, through the text index.
+	cursor, err := collection.Find(ctx, notDeleted(bson.M{"tenantId": tenantID, "$text": bson.M{"$search": strings.Join(terms, " ")}}), opts)
+	if err != nil {
+		return nil, err
+	}
+	var found []bson.M
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	// 2. Prefixes and typos: every term must start a word of one of the fields.
+	var conditions bson.A
+	for _, term := range terms {
+		pattern := termPattern(term)
+		var fields bson.A
+		for _, field := range source.Fields {
+			fields = append(fields, bson.M{field.Name: bson.M{"$regex": pattern, "$options": "i"}})
+		}
+		conditions = append(conditions, bson.M{"$or": fields})
+	}
+	cursor, err = collection.Find(ctx, notDeleted(bson.M{"tenantId": tenantID, "$and": conditions}), opts)
+	if err != nil {
+		return nil, err
+	}
+	var scanned []bson.M
+	if err := cursor.All(ctx, &scanned); err != nil {
+		return nil, err
+	}
+
+	seen := map[primitive.ObjectID]bool{}
+	var docs []searchDocument
+	for _, raw := range append(found, scanned...) {
+		doc := toSearchDocument(source, raw)
+		if !seen[doc.ID] {
+			seen[doc.ID] = true
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// termPattern returns a regular expression that matches the start of a word within one
+// typo of the term: a character left out, added, replaced or swapped with the next one.
+// Numbers match without typos, but across separators, as in phone numbers.
+func termPattern(term string) string {
+	runes := []rune(term)
+	if isNumber(term) {
+		digits := make([]string, len(runes))
+		for i, r := range runes {
+			digits[i] = string(r)
+		}
+		return `(^|[^\p{L}\p{N}])` + strings.Join(digits, `[^\p{L}\p{N}]*`)
+	}
+	variants := []string{regexp.QuoteMeta(term)}
+	if len(runes) >= minFuzzyLength {
+		quote := func(r []rune) string { return regexp.QuoteMeta(string(r)) }
+		for i := range runes {
+			variants = append(variants,
+				quote(runes[:i])+quote(runes[i+1:]),     // Left out
+				quote(runes[:i])+"."+quote(runes[i+1:]), // Replaced
+				quote(runes[:i])+"."+quote(runes[i:]))   // Added
+			if i+1 < len(runes) {
+				swapped := append([]rune{}, runes...)
+				swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+				variants = append(variants, quote(swapped)) // Swapped
+			}
+		}
+	}
+	return `(^|[^\p{L}\p{N}])(` + strings.Join(variants, "|") + `)`
+}
+
+// isNumber reports whether a search term consists of digits only.
+func isNumber(term string) bool {
+	for _, r := range term {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// toSearchDocument keeps the searched string fields of a document.
+func toSearchDocument(source searchSource, raw bson.M) searchDocument {
+	doc := searchDocument{Values: map[string]string{}}
+	doc.ID, _ = raw["_id"].(primitive.ObjectID)
+	for _, field := range source.Fields {
+		if value, ok := raw[field.Name].(string); ok {
+			doc.Values[field.Name] = value
+		}
+	}
+	return doc
+}
+
+// --- Search ---
+
+// Search finds employees and entities of the tenant by name, email, phone number and the
+// other text fields of each entity type. Every term of the query must match the start of a
+// word, allowing one typo in terms of four or more characters. Only the types the tenant
+// has enabled are searched; types narrows them down further.
+func Search(ctx context.Context, tenantID, query string, types []string, limit int) (*SearchResult, error) {
+	if utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return nil, ErrSearchTooLong
+	}
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearch
+	}
+	if len(terms) > MaxSearchTerms {
+		return nil, ErrSearchTooLong
+	}
+	if limit <= 0 || limit > MaxSearchLimit {
+		limit = DefaultSearchLimit
+	}
+
+	tenantObjID, _ := primitive.ObjectIDFromHex(tenantID)
+	var tenant models.Tenant
+	if err := db.GetCollection("tenants").FindOne(ctx, bson.M{"_id": tenantObjID}).Decode(&tenant); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{Query: query, Groups: []SearchGroup{}}
+	for _, source := range searchSources() {
+		if !containsString(tenant.EnabledEntities, source.Type) || (len(types) > 0 && !containsString(types, source.Type)) {
+			continue
+		}
+		docs, err := search.candidates(ctx, tenantID, source, terms)
+		if err != nil {
+			return nil, err
+		}
+		group := SearchGroup{Type: source.Type, Hits: []SearchHit{}}
+		for _, doc := range docs {
+			score, field := scoreDocument(source, doc, terms)
+			if score == 0 {
+				continue
+			}
+			group.Hits = append(group.Hits, SearchHit{
+				ID:       doc.ID.Hex(),
+				Title:    source.title(doc),
+				Subtitle: source.subtitle(doc),
+				Field:    field,
+				Score:    math.Round(score*1000) / 1000,
+			})
+		}
+		if len(group.Hits) == 0 {
+			continue
+		}
+		sort.SliceStable(group.Hits, func(i, j int) bool {
+			if group.Hits[i].Score != group.Hits[j].Score {
+				return group.Hits[i].Score > group.Hits[j].Score
+			}
+			return group.Hits[i].Title < group.Hits[j].Title
+		})
+		if len(group.Hits) > limit {
+			group.Hits = group.Hits[:limit]
+		}
+		result.Groups = append(result.Groups, group)
+	}
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		return result.Groups[i].Hits[0].Score > result.Groups[j].Hits[0].Score
+	})
+	return result, nil
+}
+
+// searchTerms splits a query into lower-case words.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// fieldWords splits a field into the words terms are matched against. Phone numbers also
+// match without their separators, so "5551234" finds "+1 555-1234".
+func fieldWords(field, value string) []string {
+	words := searchTerms(value)
+	if field == "phoneNumber" {
+		parts := words
+		for i := 0; i < len(parts)-1; i++ {
+			words = append(words, strings.Join(parts[i:], ""))
+		}
+	}
+	return words
+}
+
+// scoreDocument rates how well a document matches all terms, and names the field that
+// matched best. It is 0 unless every term matches a word of some field.
+func scoreDocument(source searchSource, doc searchDocument, terms []string) (float64, string) {
+	total := 0.0
+	bestField, bestScore := "", 0.0
+	for _, term := range terms {
+		termBest := 0.0
+		for _, field := range source.Fields {
+			for _, word := range fieldWords(field.Name, doc.Values[field.Name]) {
+				score := matchTerm(term, word) * field.Weight
+				if score > termBest {
+					termBest = score
+				}
+				if score > bestScore {
+					bestField, bestScore = field.Name, score
+				}
+			}
+		}
+		if termBest == 0 {
+			return 0, ""
+		}
+		total += termBest
+	}
+	return total / float64(len(terms)), bestField
+}
+
+// matchTerm rates how well a search term matches one word: 1 for the word itself, less for
+// a prefix of it, and less again with a typo.
+func matchTerm(term, word string) float64 {
+	t, w := []rune(term), []rune(word)
+	switch {
+	case term == word:
+		return 1
+	case strings.HasPrefix(word, term):
+		// Longer prefixes of shorter words rank higher.
+		return 0.7 + 0.2*float64(len(t))/float64(len(w))
+	case len(t) < minFuzzyLength || isNumber(term):
+		return 0
+	case editDistance(t, w) <= 1:
+		return 0.6
+	}
+	// A typo in a prefix: compare with the start of the word, one character shorter or longer.
+	for n := len(t) - 1; n <= len(t)+1; n++ {
+		if n < len(w) && editDistance(t, w[:n]) <= 1 {
+			return 0.4
+		}
+	}
+	return 0
+}
+
+// editDistance counts the characters to leave out, add, replace or swap with the next one
+// to turn a into b (the optimal string alignment distance).
+func editDistance(a, b []rune) int {
+	if diff := len(a) - len(b); diff > 1 || diff < -1 {
+		return 2 // Only distances up to 1 matter
+	}
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"github.com/your-username/onboarding/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TenantArchiveSchemaVersion is written to every tenant archive. Bump it whenever the
+// layout of the archive or of the stored documents changes in an incompatible way.
+const TenantArchiveSchemaVersion = 1
+
+// archiveBatchSize is the number of documents written per InsertMany call during an import.
+const archiveBatchSize = 500
+
+// TenantScopedCollections lists every collection whose documents carry a tenantId and
+// therefore belong in a tenant archive. New tenant-scoped collections must be added here.
+// Import jobs, webhooks, queued emails and domain events are left out on purpose: they are operational state, and a cloned
+// tenant must not start sending events to the original tenant's receivers. Employee documents are left out
+// because their files live in the blob store.
+var TenantScopedCollections = []string{"users", "employees", "notification_templates", "document_templates", "asset_units", "asset_assignments", "buddy_assignments", "access_requests", "approval_policies", "change_requests", "saved_views", "record_versions", "scheduled_changes"}
+
+func init() {
+	for _, def := range EntityDefinitions {
+		TenantScopedCollections = append(TenantScopedCollections, def.Collection)
+	}
+}
+
+// TenantArchiveManifest is stored as manifest.json at the root of a tenant archive.
+type TenantArchiveManifest struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	ExportedAt    time.Time      `json:"exportedAt"`
+	TenantID      string         `json:"tenantId"`
+	TenantName    string         `json:"tenantName"`
+	Collections   map[string]int `json:"collections"` // Collection name -> number of documents
+}
+
+// ExportTenant writes a zip archive with every document of the tenant to w.
+// Each collection is stored as <collection>.ndjson in canonical Extended JSON, so that
+// ObjectIDs and dates survive the round trip. Password hashes are never exported.
+func ExportTenant(ctx context.Context, w io.Writer, tenantID string) error {
+	tenantObjID, err := primitive.ObjectIDFromHex(tenantID)
+	if err != nil {
+		return errors.New("invalid tenant id")
+	}
+	var tenant bson.M
+	if err := db.GetCollection("tenants").FindOne(ctx, bson.M{"_id": tenantObjID}).Decode(&tenant); err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	manifest := TenantArchiveManifest{
+		SchemaVersion: TenantArchiveSchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		TenantID:      tenantID,
+		Collections:   map[string]int{},
+	}
+	manifest.TenantName, _ = tenant["name"].(string)
+
+	if err := writeArchiveDocuments(archive, "tenant.ndjson", []bson.M{tenant}); err != nil {
+		return err
+	}
+
+	for _, collectionName := range TenantScopedCollections {
+		file, err := archive.Create(collectionName + ".ndjson")
+		if err != nil {
+			return err
+		}
+		cursor, err := db.GetCollection(collectionName).Find(ctx, bson.M{"tenantId": tenantID})
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for cursor.Next(ctx) {
+			var document bson.M
+			if err := cursor.Decode(&document); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+			if collectionName == "users" {
+				delete(document, "password")
+			}
+			if err := writeExtJSONLine(file, document); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+			count++
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return err
+		}
+		manifest.Collections[collectionName] = count
+	}
+
+	// The manifest goes last because it contains the document counts.
+	file, err := archive.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// TenantImportOptions describes the tenant that an archive is imported into.
+type TenantImportOptions struct {
+	CompanyName   string `json:"companyName" form:"companyName" binding:"required"`
+	AdminUsername string `json:"adminUsername" form:"adminUsername" binding:"required"`
+	AdminPassword string `json:"adminPassword" form:"adminPassword" binding:"required"`
+	Status        string `json:"status" form:"status"` // Defaults to "active"
+}
+
+// TenantImportResult summarises an import.
+type TenantImportResult struct {
+	Tenant    *models.Tenant `json:"tenant"`
+	AdminUser *models.User   `json:"adminUser"`
+	Imported  map[string]int `json:"imported"` // Collection name -> number of documents created
+	// SkippedUsers lists usernames that already exist in this installation.
+	SkippedUsers []string `json:"skippedUsers"`
+	// Imported users have no password, since hashes are not exported. They cannot log in
+	// until an admin sets a new password for them.
+	UsersWithoutPassword []string `json:"usersWithoutPassword"`
+}
+
+// ImportTenant creates a new tenant from an archive written by ExportTenant.
+// Every document gets a new ObjectID and every reference to an exported document is
+// rewritten to the new ID, so the archive can be imported next to the original tenant.
+// If anything fails, everything written for the new tenant is removed again.
+func ImportTenant(ctx context.Context, r io.ReaderAt, size int64, opts TenantImportOptions) (*TenantImportResult, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("the file is not a valid tenant archive")
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	// 1. Check the manifest.
+	var manifest TenantArchiveManifest
+	if err := readArchiveJSON(files["manifest.json"], &manifest); err != nil {
+		return nil, errors.New("the archive has no valid manifest.json")
+	}
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > TenantArchiveSchemaVersion {
+		return nil, fmt.Errorf("unsupported archive schema version %d (this server supports up to %d)", manifest.SchemaVersion, TenantArchiveSchemaVersion)
+	}
+
+	// 2. First pass: assign a new ObjectID to every exported document.
+	newTenantID := primitive.NewObjectID()
+	idMap := map[primitive.ObjectID]primitive.ObjectID{}
+	oldTenantID, _ := primitive.ObjectIDFromHex(manifest.TenantID)
+	idMap[oldTenantID] = newTenantID
+	for _, collectionName := range TenantScopedCollections {
+		err := readArchiveDocuments(files[collectionName+".ndjson"], func(document bson.M) error {
+			id, ok := document["_id"].(primitive.ObjectID)
+			if !ok {
+				return fmt.Errorf("%s.ndjson has a document without an ObjectID _id", collectionName)
+			}
+			idMap[id] = primitive.NewObjectID()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Create the tenant and its admin, in the same way as a signup.
+	status := opts.Status
+	if status == "" {
+		status = "active"
+	}
+	var enabledEntities []string
+	readArchiveDocuments(files["tenant.ndjson"], func(document bson.M) error {
+		if entities, ok := document["enabledEntities"].(bson.A); ok {
+			for _, entity := range entities {
+				if slug, ok := entity.(string); ok {
+					enabledEntities = append(enabledEntities, slug)
+				}
+			}
+		}
+		return nil
+	})
+
+	tenant := &models.Tenant{
+		ID:              newTenantID,
+		Name:            opts.CompanyName,
+		Status:          status,
+		CreatedAt:       primitive.NewDateTimeFromTime(time.Now()),
+		EnabledEntities: enabledEntities,
+	}
+	if _, err := db.GetCollection("tenants").InsertOne(ctx, tenant); err != nil {
+		return nil, errors.New("failed to create tenant")
+	}
+	adminUser, err := CreateUserForTenant(&CreateUserData{
+		Username: opts.AdminUsername,
+		Password: opts.AdminPassword,
+		Role:     "admin",
+	}, newTenantID.Hex())
+	if err != nil {
+		deleteTenantData(newTenantID)
+		return nil, err
+	}
+
+	// 4. Second pass: rewrite the references and insert the documents.
+	result := &TenantImportResult{
+		Tenant:               tenant,
+		AdminUser:            adminUser,
+		Imported:             map[string]int{},
+		SkippedUsers:         []string{},
+		UsersWithoutPassword: []string{},
+	}
+	for _, collectionName := range TenantScopedCollections {
+		collection := db.GetCollection(collectionName)
+		var batch []interface{}
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			if _, err := collection.InsertMany(ctx, batch); err != nil {
+				return err
+			}
+			result.Imported[collectionName] += len(batch)
+			batch = batch[:0]
+			return nil
+		}
+
+		err := readArchiveDocuments(files[collectionName+".ndjson"], func(document bson.M) error {
+			if collectionName == "users" {
+				username, _ := document["username"].(string)
+				if username == opts.AdminUsername || usernameExists(ctx, username) {
+					result.SkippedUsers = append(result.SkippedUsers, username)
+					return nil
+				}
+				document["password"] = ""
+				result.UsersWithoutPassword = append(result.UsersWithoutPassword, username)
+			}
+
+			batch = append(batch, importedDocument(document, idMap, newTenantID))
+			if len(batch) >= archiveBatchSize {
+				return flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = flush()
+		}
+		if err != nil {
+			deleteTenantData(newTenantID)
+			return nil, fmt.Errorf("failed to import %s: %w", collectionName, err)
+		}
+	}
+
+	return result, nil
+}
+
+// CloneTenant copies a tenant into a new one, e.g. to create a sandbox for testing.
+// The archive is staged in a temporary file so large tenants are not held in memory.
+func CloneTenant(ctx context.Context, tenantID string, opts TenantImportOptions) (*TenantImportResult, error) {
+	file, size, err := utils.TempFileFrom(func(w io.Writer) error {
+		return ExportTenant(ctx, w, tenantID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ImportTenant(ctx, file, size, opts)
+}
+
+// --- Archive Helpers ---
+
+// importedDocument prepares an archive document for the new tenant. Its references are
+// remapped and it belongs to the new tenant, whatever tenantId the archive gives it.
+func importedDocument(document bson.M, idMap map[primitive.ObjectID]primitive.ObjectID, newTenantID primitive.ObjectID) bson.M {
+	imported := remapIDs(document, idMap).(bson.M)
+	imported["tenantId"] = newTenantID.Hex()
+	return imported
+}
+
+// remapIDs returns a copy of value where every ObjectID found in idMap is replaced,
+// including hex strings such as "createdBy" that hold an ObjectID. References to anything
+// outside the archive, such as another tenant's records, are cleared, so an import can
+// only refer to what it brings along.
+func remapIDs(value interface{}, idMap map[primitive.ObjectID]primitive.ObjectID) interface{} {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		if newID, ok := idMap[v]; ok {
+			return newID
+		}
+		return primitive.NilObjectID
+	case string:
+		if id, err := primitive.ObjectIDFromHex(v); err == nil {
+			if newID, ok := idMap[id]; ok {
+				return newID.Hex()
+			}
+			return ""
+		}
+		return v
+	case bson.M:
+		remapped := bson.M{}
+		for key, inner := range v {
+			remapped[key] = remapIDs(inner, idMap)
+		}
+		return remapped
+	case bson.D:
+		remapped := bson.D{}
+		for _, element := range v {
+			remapped = append(remapped, bson.E{Key: element.Key, Value: remapIDs(element.Value, idMap)})
+		}
+		return remapped
+	case bson.A:
+		remapped := bson.A{}
+		for _, inner := range v {
+			remapped = append(remapped, remapIDs(inner, idMap))
+		}
+		return remapped
+	default:
+		return v
+	}
+}
+
+// deleteTenantData removes a tenant and every document that belongs to it.
+// It is used to roll back a failed import.
+func deleteTenantData(tenantID primitive.ObjectID) {
+	ctx := context.Background()
+	for _, collectionName := range TenantScopedCollections {
+		db.GetCollection(collectionName).DeleteMany(ctx, bson.M{"tenantId": tenantID.Hex()})
+	}
+	db.GetCollection("tenants").DeleteOne(ctx, bson.M{"_id": tenantID})
+}
+
+func usernameExists(ctx context.Context, username string) bool {
+	err := db.GetCollection("users").FindOne(ctx, bson.M{"username": username}).Err()
+	return err != mongo.ErrNoDocuments
+}
+
+func writeArchiveDocuments(archive *zip.Writer, name string, documents []bson.M) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	for _, document := range documents {
+		if err := writeExtJSONLine(file, document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeExtJSONLine(w io.Writer, document bson.M) error {
+	line, err := bson.MarshalExtJSON(document, true, false)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+func readArchiveJSON(file *zip.File, out interface{}) error {
+	if file == nil {
+		return errors.New("missing file")
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return json.NewDecoder(reader).Decode(out)
+}
+
+// readArchiveDocuments calls fn for every Extended JSON line of an archive file.
+// A missing file is treated as an empty collection, so older archives stay importable.
+func readArchiveDocuments(file *zip.File, fn func(bson.M) error) error {
+	if file == nil {
+		return nil
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // Allow documents up to MongoDB's 16 MB limit.
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var document bson.M
+		if err := bson.UnmarshalExtJSON(scanner.Bytes(), true, &document); err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+		if err := fn(document); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
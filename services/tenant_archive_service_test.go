@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestImportedDocumentStaysInTheNewTenant(t *testing.T) {
+	exported, foreign := primitive.NewObjectID(), primitive.NewObjectID()
+	newID, newTenantID := primitive.NewObjectID(), primitive.NewObjectID()
+	idMap := map[primitive.ObjectID]primitive.ObjectID{exported: newID}
+
+	tests := []struct {
+		name     string
+		document bson.M
+		field    string
+		want     interface{}
+	}{
+		{"foreign tenantId", bson.M{"tenantId": foreign.Hex()}, "tenantId", newTenantID.Hex()},
+		{"missing tenantId", bson.M{}, "tenantId", newTenantID.Hex()},
+		{"exported reference", bson.M{"teamId": exported}, "teamId", newID},
+		{"foreign reference", bson.M{"teamId": foreign}, "teamId", primitive.NilObjectID},
+		{"exported hex reference", bson.M{"createdBy": exported.Hex()}, "createdBy", newID.Hex()},
+		{"foreign hex reference", bson.M{"createdBy": foreign.Hex()}, "createdBy", ""},
+		{"plain string", bson.M{"name": "Berlin"}, "name", "Berlin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := importedDocument(tt.document, idMap, newTenantID)
+			if got[tt.field] != tt.want {
+				t.Fatalf("%s = %v, want %v", tt.field, got[tt.field], tt.want)
+			}
+		})
+	}
+
+	nested := importedDocument(bson.M{"items": bson.A{bson.M{"assetId": foreign}}}, idMap, newTenantID)
+	if id := nested["items"].(bson.A)[0].(bson.M)["assetId"]; id != primitive.NilObjectID {
+		t.Fatalf("nested foreign reference = %v, want it cleared", id)
+	}
+}
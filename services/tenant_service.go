@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
 	"github.com/your-username/onboarding/models"
 	"github.com/your-username/onboarding/utils"
 	"go.mongodb.org/mongo-driver/bson"
@@ -81,5 +83,46 @@ func CreateTenantAndAdminUser(signupData *TenantSignupData) (*models.Tenant, *mo
 		return nil, nil, errors.New("failed to create admin user")
 	}
 
+	events.Publish(context.Background(), events.TenantSignedUp{Tenant: *newTenant, AdminUserID: adminUser.ID.Hex()})
 	return newTenant, adminUser, nil
 }
+
+// --- Tenant Settings ---
+
+// TenantSettings are the settings an admin can change for their tenant.
+type TenantSettings struct {
+	// TrashRetentionDays is how long deleted employees and entities can be restored.
+	TrashRetentionDays int `json:"trashRetentionDays" binding:"required"`
+}
+
+// GetTenantSettings returns the tenant's settings, with defaults for the unset ones.
+func GetTenantSettings(ctx context.Context, tenantID string) (*TenantSettings, error) {
+	objID, err := primitive.ObjectIDFromHex(tenantID)
+	if err != nil {
+		return nil, errors.New("invalid tenant id")
+	}
+	var tenant models.Tenant
+	if err := db.GetCollection("tenants").FindOne(ctx, bson.M{"_id": objID}).Decode(&tenant); err != nil {
+		return nil, err
+	}
+	return &TenantSettings{TrashRetentionDays: trashRetentionDays(&tenant)}, nil
+}
+
+// UpdateTenantSettings replaces the tenant's settings.
+func UpdateTenantSettings(ctx context.Context, tenantID string, in *TenantSettings) (*TenantSettings, error) {
+	if in.TrashRetentionDays < 1 || in.TrashRetentionDays > MaxTrashRetentionDays {
+		return nil, fmt.Errorf("trashRetentionDays must be from 1 to %d", MaxTrashRetentionDays)
+	}
+	objID, err := primitive.ObjectIDFromHex(tenantID)
+	if err != nil {
+		return nil, errors.New("invalid tenant id")
+	}
+	result, err := db.GetCollection("tenants").UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"trashRetentionDays": in.TrashRetentionDays}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.New("tenant not found")
+	}
+	return in, nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// DefaultTrashRetentionDays is how long deleted employees and entities stay in the trash
+	// when the tenant has not configured a retention period.
+	DefaultTrashRetentionDays = 30
+	// MaxTrashRetentionDays caps the retention period a tenant can configure.
+	MaxTrashRetentionDays = 365
+)
+
+// ErrNotInTrash is returned when a record to restore is not in the tenant's trash.
+var ErrNotInTrash = errors.New("record not found in the trash of this tenant")
+
+// trashRetentionDays returns how many days the tenant keeps deleted records.
+func trashRetentionDays(tenant *models.Tenant) int {
+	if tenant.TrashRetentionDays > 0 {
+		return tenant.TrashRetentionDays
+	}
+	return DefaultTrashRetentionDays
+}
+
+// trashFields are set on a document when it is moved to the trash.
+func trashFields(userID string) bson.M {
+	return bson.M{"deletedAt": primitive.NewDateTimeFromTime(time.Now()), "deletedBy": userID}
+}
+
+// recordCollection returns the collection of employees or an entity type (a slug).
+func recordCollection(entityType string) (string, error) {
+	if entityType == "employees" {
+		return "employees", nil
+	}
+	if def, ok := LookupEntity(entityType); ok {
+		return def.Collection, nil
+	}
+	return "", fmt.Errorf("unknown entity type %q", entityType)
+}
+
+// withID returns a copy of a document with its _id as "id", as the API presents records.
+func withID(doc bson.M) bson.M {
+	record := bson.M{"id": doc["_id"]}
+	for key, value := range doc {
+		if key != "_id" {
+			record[key] = value
+		}
+	}
+	return record
+}
+
+// GetTrash lists the deleted employees or entities of a type, most recently deleted first.
+// Each record carries its deletedAt and deletedBy.
+func GetTrash(ctx context.Context, tenantID, entityType string, page Pagination) ([]bson.M, error) {
+	collectionName, err := recordCollection(entityType)
+	if err != nil {
+		return nil, err
+	}
+	opts := page.findOptions().SetSort(bson.D{{Key: "deletedAt", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := db.GetCollection(collectionName).Find(ctx, bson.M{"tenantId": tenantID, "deletedAt": bson.M{"$exists": true}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	records := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		records[i] = withID(doc)
+	}
+	return records, nil
+}
+
+// RestoreFromTrash takes a deleted employee or entity out of the trash and returns it. The
+// side effects of the deletion, such as reassigned reports or revoked access, stay in place.
+func RestoreFromTrash(ctx context.Context, tenantID, entityType, id string) (bson.M, error) {
+	collectionName, err := recordCollection(entityType)
+	if err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotInTrash
+	}
+
+	var doc bson.M
+	err = writeWithEvents(ctx, func(ctx context.Context) ([]events.Event, error) {
+		if err := ensureBaselineVersion(ctx, entityType, collectionName, objID); err != nil {
+			return nil, err
+		}
+		err := db.GetCollection(collectionName).FindOneAndUpdate(ctx,
+			bson.M{"_id": objID, "tenantId": tenantID, "deletedAt": bson.M{"$exists": true}},
+			bson.M{"$unset": bson.M{"deletedAt": "", "deletedBy": ""}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotInTrash
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if entityType == "employees" {
+			var employee models.Employee
+			raw, err := bson.Marshal(doc)
+			if err == nil {
+				err = bson.Unmarshal(raw, &employee)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return []events.Event{events.EmployeeRestored{Employee: employee}}, nil
+		}
+		return []events.Event{events.EntityRestored{TenantID: tenantID, EntityType: entityType, Entity: doc}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withID(doc), nil
+}
+
+// --- Purge ---
+
+// purgeTrash permanently deletes the employees and entities that have been in the trash
+// longer than their tenant's retention period, together with their history and the
+// documents and scheduled changes of the employees. It runs as a recurring job.
+func purgeTrash(ctx context.Context, job *models.ScheduledJob) error {
+	cursor, err := db.GetCollection("tenants").Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"trashRetentionDays": 1}))
+	if err != nil {
+		return err
+	}
+	var tenants []models.Tenant
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return err
+	}
+
+	collections := []string{"employees"}
+	for _, def := range EntityDefinitions {
+		collections = append(collections, def.Collection)
+	}
+	var purged int64
+	for _, tenant := range tenants {
+		tenantID := tenant.ID.Hex()
+		expired := bson.M{
+			"tenantId":  tenantID,
+			"deletedAt": bson.M{"$lt": primitive.NewDateTimeFromTime(time.Now().AddDate(0, 0, -trashRetentionDays(&tenant)))},
+		}
+		for _, collectionName := range collections {
+			ids, err := db.GetCollection(collectionName).Distinct(ctx, "_id", expired)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			recordIDs := make([]primitive.ObjectID, 0, len(ids))
+			for _, id := range ids {
+				if objID, ok := id.(primitive.ObjectID); ok {
+					recordIDs = append(recordIDs, objID)
+				}
+			}
+
+			// Documents and history go first, so a failure leaves the records in the trash
+			// to be retried.
+			if collectionName == "employees" {
+				if err := deleteDocumentsOfPurgedEmployees(ctx, tenantID, recordIDs); err != nil {
+					return err
+				}
+				if err := deleteScheduledChanges(ctx, tenantID, recordIDs); err != nil {
+					return err
+				}
+			}
+			if err := deleteVersions(ctx, tenantID, recordIDs); err != nil {
+				return err
+			}
+			// A record restored in the meantime no longer matches expired and stays.
+			result, err := db.GetCollection(collectionName).DeleteMany(ctx, bson.M{
+				"tenantId":  tenantID,
+				"_id":       bson.M{"$in": recordIDs},
+				"deletedAt": expired["deletedAt"],
+			})
+			if err != nil {
+				return err
+			}
+			purged += result.DeletedCount
+		}
+	}
+	if purged > 0 {
+		log.Printf("Purged %d records from the trash", purged)
+	}
+	return nil
+}
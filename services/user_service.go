@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/your-username/onboarding/auth"
 	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
 	"github.com/your-username/onboarding/models"
 	"github.com/your-username/onboarding/utils"
 	"go.mongodb.org/mongo-driver/bson"
@@ -30,10 +32,49 @@ func LoginUser(username, password string) (string, models.User, error) {
 	}
 
 	// If credentials are valid, generate a JWT containing the user's ID and their tenant ID.
-	token, err := auth.GenerateToken(user.ID.Hex(), user.TenantID)
+	token, err := auth.GenerateToken(user.ID.Hex(), user.TenantID, time.Now())
 	return token, user, err
 }
 
+// ErrSessionExpired is returned when a token is refreshed after the session it belongs to
+// has ended. The user has to log in again.
+var ErrSessionExpired = errors.New("the session has expired, please log in again")
+
+// RefreshToken issues a new JWT for a user who already holds a valid token. The new token
+// belongs to the same session, which ends auth.MaxSessionDuration after the login at
+// loginAt (a Unix time). The user is looked up again so that deleted users cannot keep
+// extending their session.
+func RefreshToken(userID string, loginAt int64) (string, error) {
+	// Tokens issued before sessions were capped carry no login time and are not refreshed.
+	login := time.Unix(loginAt, 0)
+	if loginAt == 0 || time.Since(login) >= auth.MaxSessionDuration {
+		return "", ErrSessionExpired
+	}
+
+	var usersCollection = db.GetCollection("users")
+
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", errors.New("invalid user id")
+	}
+
+	var user models.User
+	err = usersCollection.FindOne(context.Background(), bson.M{"_id": objID}).Decode(&user)
+	if err != nil {
+		return "", errors.New("user no longer exists")
+	}
+
+	return auth.GenerateToken(user.ID.Hex(), user.TenantID, login)
+}
+
+// UserRoles are the roles a user can have. Managers and security officers decide approval
+// steps, see approval_service.go.
+var UserRoles = []string{"admin", "member", "manager", "security"}
+
+func isUserRole(role string) bool {
+	return containsString(UserRoles, role)
+}
+
 // CreateUserData holds the information needed to create a new user.
 type CreateUserData struct {
 	Username string `json:"username" binding:"required"`
@@ -45,8 +86,7 @@ type CreateUserData struct {
 func CreateUserForTenant(data *CreateUserData, tenantID string) (*models.User, error) {
 	var usersCollection = db.GetCollection("users")
 
-	// You might want to add validation for the role type here
-	if data.Role != "admin" && data.Role != "member" {
+	if !isUserRole(data.Role) {
 		return nil, errors.New("invalid role specified")
 	}
 
@@ -72,6 +112,12 @@ func CreateUserForTenant(data *CreateUserData, tenantID string) (*models.User, e
 		return nil, errors.New("failed to create user")
 	}
 
+	events.Publish(context.Background(), events.UserCreated{
+		UserID:   newUser.ID.Hex(),
+		TenantID: tenantID,
+		Username: newUser.Username,
+		Role:     newUser.Role,
+	})
 	return newUser, nil
 }
 
@@ -89,5 +135,10 @@ func GetUsersByTenant(tenantID string) ([]models.User, error) {
 	if err = cursor.All(context.Background(), &users); err != nil {
 		return nil, err
 	}
+
+	// Return an empty slice instead of nil so the JSON response is [] as the spec declares.
+	if users == nil {
+		return []models.User{}, nil
+	}
 	return users, nil
 }
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/events"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrVersionNotFound is returned when a record has no such version, or none as of a date.
+var ErrVersionNotFound = errors.New("version not found for this record")
+
+// maxVersionAttempts is how often a version is renumbered when a concurrent change of the
+// same record took its number.
+const maxVersionAttempts = 5
+
+// EnsureVersionIndexes creates the indexes of the record history. Version numbers are
+// unique per record.
+func EnsureVersionIndexes(ctx context.Context) error {
+	_, err := db.GetCollection("record_versions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "recordId", Value: 1}, {Key: "version", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "recordId", Value: 1}, {Key: "changedAt", Value: 1}}},
+	})
+	return err
+}
+
+// --- Recording ---
+
+// ensureBaselineVersion saves the state of a record that has no history yet, before its first
+// change is written. Records created before versioning thus keep the state they had; it is
+// dated at their creation, as nothing is known about earlier changes.
+func ensureBaselineVersion(ctx context.Context, entityType, collectionName string, id primitive.ObjectID) error {
+	count, err := db.GetCollection("record_versions").CountDocuments(ctx, bson.M{"recordId": id}, options.Count().SetLimit(1))
+	if err != nil || count > 0 {
+		return err
+	}
+	var doc bson.M
+	err = db.GetCollection(collectionName).FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil // The change will fail with its own error
+	}
+	if err != nil {
+		return err
+	}
+	return insertVersion(ctx, entityType, "baseline", doc, id.Timestamp())
+}
+
+// recordVersion saves the state of a record after a change as its next version. It is
+// subscribed synchronously to the employee and entity events, so that the state is read
+// right after the change.
+func recordVersion(ctx context.Context, env events.Envelope) error {
+	var (
+		entityType, operation string
+		id                    primitive.ObjectID
+		err                   error
+	)
+	switch e := env.Event.(type) {
+	case events.EmployeeCreated:
+		entityType, operation, id = "employees", "created", e.Employee.ID
+	case events.EmployeeUpdated:
+		entityType, operation = "employees", "updated"
+		id, err = primitive.ObjectIDFromHex(e.EmployeeID)
+	case events.EmployeeDeleted:
+		entityType, operation, id = "employees", "deleted", e.Employee.ID
+	case events.EmployeeRestored:
+		entityType, operation, id = "employees", "restored", e.Employee.ID
+	case events.EntityCreated:
+		entityType, operation = e.EntityType, "created"
+		id, err = ObjectIDValue(e.Entity["_id"])
+	case events.EntityUpdated:
+		entityType, operation = e.EntityType, "updated"
+		id, err = primitive.ObjectIDFromHex(e.EntityID)
+	case events.EntityDeleted:
+		entityType, operation = e.EntityType, "deleted"
+		id, err = primitive.ObjectIDFromHex(e.EntityID)
+	case events.EntityRestored:
+		entityType, operation = e.EntityType, "restored"
+		id, err = ObjectIDValue(e.Entity["_id"])
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	collectionName, err := recordCollection(entityType)
+	if err != nil {
+		return nil
+	}
+
+	var doc bson.M
+	if err := db.GetCollection(collectionName).FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return err
+	}
+	return insertVersion(ctx, entityType, operation, doc, env.OccurredAt)
+}
+
+// insertVersion saves a document as the next version of its record.
+func insertVersion(ctx context.Context, entityType, operation string, doc bson.M, changedAt time.Time) error {
+	id, _ := doc["_id"].(primitive.ObjectID)
+	tenantID, _ := doc["tenantId"].(string)
+	data := map[string]interface{}{}
+	for key, value := range doc {
+		if key != "_id" {
+			data[key] = value
+		}
+	}
+
+	versions := db.GetCollection("record_versions")
+	for attempt := 0; attempt < maxVersionAttempts; attempt++ {
+		next := 1
+		var last models.RecordVersion
+		err := versions.FindOne(ctx, bson.M{"recordId": id}, options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})).Decode(&last)
+		if err == nil {
+			next = last.Version + 1
+		} else if !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+
+		_, err = versions.InsertOne(ctx, models.RecordVersion{
+			ID:         primitive.NewObjectID(),
+			TenantID:   tenantID,
+			EntityType: entityType,
+			RecordID:   id,
+			Version:    next,
+			Operation:  operation,
+			Data:       data,
+			ChangedAt:  primitive.NewDateTimeFromTime(changedAt),
+		})
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return errors.New("could not number the version of the record")
+}
+
+// deleteVersions removes the history of records that are purged.
+func deleteVersions(ctx context.Context, tenantID string, recordIDs []primitive.ObjectID) error {
+	_, err := db.GetCollection("record_versions").DeleteMany(ctx, bson.M{"tenantId": tenantID, "recordId": bson.M{"$in": recordIDs}})
+	return err
+}
+
+// --- History ---
+
+// versionsOf matches the versions of a record of the tenant.
+func versionsOf(tenantID, entityType, id string) (bson.M, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrVersionNotFound
+	}
+	return bson.M{"tenantId": tenantID, "entityType": entityType, "recordId": objID}, nil
+}
+
+// GetVersions lists the versions of an employee or entity, newest first. The history
+// includes the versions from before a deletion.
+func GetVersions(ctx context.Context, tenantID, entityType, id string, page Pagination) ([]models.RecordVersion, error) {
+	query, err := versionsOf(tenantID, entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	opts := page.findOptions().SetSort(bson.D{{Key: "version", Value: -1}})
+	cursor, err := db.GetCollection("record_versions").Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	versions := []models.RecordVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetVersion returns one version of an employee or entity.
+func GetVersion(ctx context.Context, tenantID, entityType, id string, version int) (*models.RecordVersion, error) {
+	query, err := versionsOf(tenantID, entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	query["version"] = version
+	return findVersion(ctx, query, nil)
+}
+
+// GetVersionAsOf returns the version of an employee or entity that was current at a time:
+// the last one changed at or before it. If the record was deleted by then, that is the
+// "deleted" version.
+func GetVersionAsOf(ctx context.Context, tenantID, entityType, id string, at time.Time) (*models.RecordVersion, error) {
+	query, err := versionsOf(tenantID, entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	query["changedAt"] = bson.M{"$lte": primitive.NewDateTimeFromTime(at)}
+	return findVersion(ctx, query, options.FindOne().SetSort(bson.D{{Key: "changedAt", Value: -1}, {Key: "version", Value: -1}}))
+}
+
+func findVersion(ctx context.Context, query bson.M, opts *options.FindOneOptions) (*models.RecordVersion, error) {
+	if opts == nil {
+		opts = options.FindOne()
+	}
+	var version models.RecordVersion
+	if err := db.GetCollection("record_versions").FindOne(ctx, query, opts).Decode(&version); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	return &version, nil
+}
+
+// FieldChange is a field that differs between two versions. From or To is nil if the
+// field is missing from that version.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// VersionDiff lists the fields that changed from one version of a record to another.
+type VersionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// DiffVersions compares two versions of an employee or entity, field by field.
+func DiffVersions(ctx context.Context, tenantID, entityType, id string, from, to int) (*VersionDiff, error) {
+	older, err := GetVersion(ctx, tenantID, entityType, id, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := GetVersion(ctx, tenantID, entityType, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]bool{}
+	for field := range older.Data {
+		fields[field] = true
+	}
+	for field := range newer.Data {
+		fields[field] = true
+	}
+	diff := &VersionDiff{From: from, To: to, Changes: []FieldChange{}}
+	for field := range fields {
+		before, after := older.Data[field], newer.Data[field]
+		if !reflect.DeepEqual(before, after) {
+			diff.Changes = append(diff.Changes, FieldChange{Field: field, From: before, To: after})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool { return diff.Changes[i].Field < diff.Changes[j].Field })
+	return diff, nil
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/filter"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSavedViewNotFound is returned for views that do not exist in the tenant or that
+// belong to another user and are not shared.
+var ErrSavedViewNotFound = errors.New("saved view not found or does not belong to this tenant")
+
+// entityNumberFields are the number fields of entities, which EntityDefinition does not list.
+var entityNumberFields = map[string][]string{
+	"costs":            {"headcountBudget", "perHireCost"},
+	"hardware-assets":  {"unitCost"},
+	"onboarding-buddy": {"maxBuddies"},
+}
+
+// --- Filters ---
+
+// filterSchema lists the fields of employees or an entity type that filters may use. IDs
+// are compared as hex strings, and references also by name, e.g. location = "Berlin".
+func filterSchema(ctx context.Context, tenantID, entityType string) (filter.Schema, error) {
+	fields := map[string]filter.Field{"id": {Kind: filter.IDField, Column: "_id"}}
+	var references []Reference
+	if entityType == "employees" {
+		for _, name := range EmployeeFields {
+			fields[name] = filter.Field{Kind: filter.TextField}
+		}
+		fields["onboardingDate"] = filter.Field{Kind: filter.DateField}
+		fields["reportsToId"] = filter.Field{Kind: filter.IDField}
+		references = EmployeeReferences
+	} else if def, ok := LookupEntity(entityType); ok {
+		fields["name"] = filter.Field{Kind: filter.TextField}
+		for _, name := range def.Fields {
+			fields[name] = filter.Field{Kind: filter.TextField}
+		}
+		for _, name := range def.IDFields {
+			fields[name] = filter.Field{Kind: filter.IDField}
+		}
+		for _, name := range entityNumberFields[entityType] {
+			fields[name] = filter.Field{Kind: filter.NumberField}
+		}
+		references = def.References
+	} else {
+		return filter.Schema{}, fmt.Errorf("unknown entity type %q", entityType)
+	}
+	for _, ref := range references {
+		fields[ref.Field] = filter.Field{Kind: filter.IDField}
+		fields[ref.NameField()] = filter.Field{Kind: filter.NameField, Column: ref.Field, Slug: ref.Slug}
+	}
+
+	names := map[string]map[primitive.ObjectID]string{}
+	return filter.Schema{
+		Fields: fields,
+		Names: func(slug string) (map[primitive.ObjectID]string, error) {
+			if index, ok := names[slug]; ok {
+				return index, nil
+			}
+			index, err := loadNameIndex(ctx, slug, tenantID)
+			if err != nil {
+				return nil, err
+			}
+			names[slug] = index.names
+			return index.names, nil
+		},
+	}, nil
+}
+
+// CompileFilter turns a filter expression for employees or an entity type into a MongoDB
+// query. Problems with the expression are reported as a *filter.Error with their position.
+func CompileFilter(ctx context.Context, tenantID, entityType, expression string) (bson.M, error) {
+	expr, err := filter.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := filterSchema(ctx, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+	return filter.Compile(expr, schema, time.Now())
+}
+
+// ListFilter compiles the filter of a list request: a saved view, an expression, or both,
+// which must then both match. It returns nil if neither is given.
+func ListFilter(ctx context.Context, tenantID, userID, entityType, viewID, expression string) (bson.M, error) {
+	var parts bson.A
+	if viewID != "" {
+		view, err := GetSavedView(ctx, viewID, tenantID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if view.EntityType != entityType {
+			return nil, fmt.Errorf("the view %q lists %s, not %s", view.Name, view.EntityType, entityType)
+		}
+		query, err := CompileFilter(ctx, tenantID, entityType, view.Filter)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, query)
+	}
+	if strings.TrimSpace(expression) != "" {
+		query, err := CompileFilter(ctx, tenantID, entityType, expression)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, query)
+	}
+	switch len(parts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return parts[0].(bson.M), nil
+	}
+	return bson.M{"$and": parts}, nil
+}
+
+// --- Saved Views ---
+
+// SavedViewInput describes a saved view.
+type SavedViewInput struct {
+	Name       string `json:"name" binding:"required"`
+	EntityType string `json:"entityType" binding:"required"`
+	Filter     string `json:"filter" binding:"required"`
+	Shared     bool   `json:"shared"`
+}
+
+// validate checks the name and that the filter compiles for the entity type.
+func (in *SavedViewInput) validate(ctx context.Context, tenantID string) error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	_, err := CompileFilter(ctx, tenantID, in.EntityType, in.Filter)
+	return err
+}
+
+// CreateSavedView saves a view for the user.
+func CreateSavedView(ctx context.Context, tenantID, userID string, in *SavedViewInput) (*models.SavedView, error) {
+	if err := in.validate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	view := models.SavedView{
+		ID:         primitive.NewObjectID(),
+		TenantID:   tenantID,
+		Name:       in.Name,
+		EntityType: in.EntityType,
+		Filter:     in.Filter,
+		Shared:     in.Shared,
+		OwnerID:    userID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if _, err := db.GetCollection("saved_views").InsertOne(ctx, view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// visibleViews matches the views a user may use: their own and the shared ones.
+func visibleViews(tenantID, userID string) bson.M {
+	return bson.M{"tenantId": tenantID, "$or": bson.A{bson.M{"ownerId": userID}, bson.M{"shared": true}}}
+}
+
+// GetSavedViews lists the user's own views and the tenant's shared views by name,
+// optionally for one entity type.
+func GetSavedViews(ctx context.Context, tenantID, userID, entityType string) ([]models.SavedView, error) {
+	query := visibleViews(tenantID, userID)
+	if entityType != "" {
+		query["entityType"] = entityType
+	}
+	cursor, err := db.GetCollection("saved_views").Find(ctx, query, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	views := []models.SavedView{}
+	if err := cursor.All(ctx, &views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// GetSavedView returns a view the user owns or that is shared.
+func GetSavedView(ctx context.Context, id, tenantID, userID string) (*models.SavedView, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrSavedViewNotFound
+	}
+	query := visibleViews(tenantID, userID)
+	query["_id"] = objID
+	var view models.SavedView
+	if err := db.GetCollection("saved_views").FindOne(ctx, query).Decode(&view); err != nil {
+		return nil, ErrSavedViewNotFound
+	}
+	return &view, nil
+}
+
+// editableView returns a view the user may change: their own, or any shared one for admins.
+func editableView(ctx context.Context, id, tenantID, userID string) (*models.SavedView, error) {
+	view, err := GetSavedView(ctx, id, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if view.OwnerID != userID {
+		role, err := userRole(ctx, userID, tenantID)
+		if err != nil || role != "admin" {
+			return nil, ErrRoleForbidden
+		}
+	}
+	return view, nil
+}
+
+// UpdateSavedView replaces a view. Only its owner, or an admin for a shared view, may.
+func UpdateSavedView(ctx context.Context, id, tenantID, userID string, in *SavedViewInput) (*models.SavedView, error) {
+	if err := in.validate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	view, err := editableView(ctx, id, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = db.GetCollection("saved_views").FindOneAndUpdate(ctx,
+		bson.M{"_id": view.ID, "tenantId": tenantID},
+		bson.M{"$set": bson.M{
+			"name":       in.Name,
+			"entityType": in.EntityType,
+			"filter":     in.Filter,
+			"shared":     in.Shared,
+			"updatedAt":  primitive.NewDateTimeFromTime(time.Now()),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(view)
+	if err != nil {
+		return nil, ErrSavedViewNotFound
+	}
+	return view, nil
+}
+
+// DeleteSavedView removes a view. Only its owner, or an admin for a shared view, may.
+func DeleteSavedView(ctx context.Context, id, tenantID, userID string) error {
+	view, err := editableView(ctx, id, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	result, err := db.GetCollection("saved_views").DeleteOne(ctx, bson.M{"_id": view.ID, "tenantId": tenantID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrSavedViewNotFound
+	}
+	return nil
+}
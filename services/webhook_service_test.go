@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-username/onboarding/config"
+	"github.com/your-username/onboarding/db"
+	"github.com/your-username/onboarding/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// allowWebhookNetworks sets config.WebhookAllowedNetworks for the duration of a test.
+func allowWebhookNetworks(t *testing.T, entries ...string) {
+	previous := config.AppConfig.WebhookAllowedNetworks
+	config.AppConfig.WebhookAllowedNetworks = entries
+	t.Cleanup(func() { config.AppConfig.WebhookAllowedNetworks = previous })
+}
+
+func TestWebhookURLValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		url     string
+		wantErr bool
+	}{
+		{"loopback refused by default", nil, "http://127.0.0.1:8080/hook", true},
+		{"metadata endpoint refused", nil, "http://169.254.169.254/latest", true},
+		{"private network refused", []string{"127.0.0.0/8"}, "http://10.1.2.3/hook", true},
+		{"allowed network", []string{"127.0.0.0/8"}, "http://127.0.0.1:8080/hook", false},
+		{"allowed address", []string{"10.1.2.3"}, "http://10.1.2.3/hook", false},
+		{"allowed host", []string{"receiver.internal"}, "http://receiver.internal:9000/hook", false},
+		{"not a URL", nil, "receiver/hook", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowWebhookNetworks(t, tt.allowed...)
+			data := &WebhookSubscriptionData{URL: tt.url, EventTypes: []string{"*"}}
+			if err := data.validate(context.Background()); (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookClientDialsOnlyAllowedAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	allowWebhookNetworks(t)
+	if _, err := webhookHTTPClient.Get(receiver.URL); err == nil {
+		t.Fatal("the client reached a loopback receiver that is not allowed")
+	}
+	allowWebhookNetworks(t, "127.0.0.1")
+	resp, err := webhookHTTPClient.Get(receiver.URL)
+	if err != nil {
+		t.Fatalf("the client could not reach an allowed receiver: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestWebhookDeliveryIsSignedAndRetried(t *testing.T) {
+	requireMongo(t)
+	allowWebhookNetworks(t, "127.0.0.0/8")
+	ctx := context.Background()
+
+	// The receiver fails the first attempt and accepts the second.
+	var mu sync.Mutex
+	var requests []*http.Request
+	var bodies [][]byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		if len(requests) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	subscription := models.WebhookSubscription{
+		ID:         primitive.NewObjectID(),
+		TenantID:   primitive.NewObjectID().Hex(),
+		URL:        receiver.URL + "/hook",
+		EventTypes: []string{"*"},
+		Secret:     "shared-secret",
+		Active:     true,
+	}
+	if _, err := db.GetCollection("webhook_subscriptions").InsertOne(ctx, subscription); err != nil {
+		t.Fatal(err)
+	}
+	if err := enqueueWebhookEvent(ctx, subscription.TenantID, EventEmployeeCreated, bson.M{"firstName": "Ada"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	delivery := func() models.WebhookDelivery {
+		t.Helper()
+		var d models.WebhookDelivery
+		if err := db.GetCollection("webhook_deliveries").FindOne(ctx, bson.M{"subscriptionId": subscription.ID}).Decode(&d); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	if d := delivery(); d.Status != "pending" || d.Attempts != 0 {
+		t.Fatalf("queued delivery = %s after %d attempts, want pending after 0", d.Status, d.Attempts)
+	}
+
+	// 1. The 503 leaves the delivery pending, with a retry in the future.
+	if !dispatchNextWebhook(ctx) {
+		t.Fatal("the delivery was not dispatched")
+	}
+	d := delivery()
+	if d.Status != "pending" || d.Attempts != 1 || d.LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("after a 503: status %s, attempts %d, last status %d", d.Status, d.Attempts, d.LastStatusCode)
+	}
+	if !d.NextAttemptAt.Time().After(time.Now()) {
+		t.Fatal("the retry was not scheduled after a backoff")
+	}
+	if dispatchNextWebhook(ctx) {
+		t.Fatal("the delivery was retried before its backoff ended")
+	}
+
+	// 2. Once due, the retry succeeds.
+	db.GetCollection("webhook_deliveries").UpdateByID(ctx, d.ID, bson.M{"$set": bson.M{"nextAttemptAt": primitive.NewDateTimeFromTime(time.Now())}})
+	if !dispatchNextWebhook(ctx) {
+		t.Fatal("the retry was not dispatched")
+	}
+	d = delivery()
+	if d.Status != "delivered" || d.Attempts != 2 || d.LastStatusCode != http.StatusNoContent || d.DeliveredAt == 0 {
+		t.Fatalf("after a 204: status %s, attempts %d, last status %d", d.Status, d.Attempts, d.LastStatusCode)
+	}
+
+	// 3. Every attempt carries the same event and a valid signature of its body.
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("the receiver got %d requests, want 2", len(requests))
+	}
+	for i, r := range requests {
+		want := "sha256=" + SignWebhookPayload(subscription.Secret, r.Header.Get("X-Webhook-Timestamp"), bodies[i])
+		if got := r.Header.Get("X-Webhook-Signature"); got != want {
+			t.Errorf("attempt %d: signature %q, want %q", i+1, got, want)
+		}
+		if r.Header.Get("X-Webhook-Event") != EventEmployeeCreated || r.Header.Get("X-Webhook-Id") != d.EventID.Hex() {
+			t.Errorf("attempt %d: event headers %v", i+1, r.Header)
+		}
+		if string(bodies[i]) != d.Payload {
+			t.Errorf("attempt %d: body %s, want the stored payload", i+1, bodies[i])
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *LocalStore {
+	t.Helper()
+	store, err := NewLocalStore(filepath.Join(t.TempDir(), "blobs"))
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	return store
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	key := "tenant/documents/contract.pdf"
+
+	if err := store.Put(ctx, key, strings.NewReader("first"), 5, "application/pdf"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if err := store.Put(ctx, key, strings.NewReader("second"), 6, "application/pdf"); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(content) != "second" {
+		t.Fatalf("expected the overwritten content, got %q", content)
+	}
+
+	// No temporary upload files are left behind.
+	entries, err := os.ReadDir(filepath.Join(store.Dir, "tenant", "documents"))
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the blob in its directory, found %d entries", len(entries))
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("deleting a missing blob should succeed, got %v", err)
+	}
+}
+
+func TestLocalStoreGetMissing(t *testing.T) {
+	if _, err := newTestStore(t).Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLocalStoreRejectsKeysOutsideItsDirectory(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	outside := filepath.Join(filepath.Dir(store.Dir), "outside")
+
+	for _, key := range []string{"", "..", "../outside", "tenant/../../outside", "/etc/passwd"} {
+		if _, err := store.path(key); err == nil {
+			t.Errorf("path(%q): expected an error", key)
+		}
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Errorf("Put(%q): expected an error", key)
+		}
+		if _, err := store.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): expected an invalid key error, got %v", key, err)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q): expected an error", key)
+		}
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Fatalf("a blob was written outside the store: %v", err)
+	}
+
+	// Keys that only look like traversal stay inside.
+	for _, key := range []string{"tenant/..hidden", "tenant/./file", "a/b/../c"} {
+		path, err := store.path(key)
+		if err != nil {
+			t.Errorf("path(%q): unexpected error %v", key, err)
+			continue
+		}
+		if !strings.HasPrefix(path, store.Dir+string(filepath.Separator)) {
+			t.Errorf("path(%q) = %q is outside %q", key, path, store.Dir)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config holds the connection details of an S3-compatible object store.
+type S3Config struct {
+	Endpoint  string // e.g. "s3.amazonaws.com", or "localhost:9000" for a local MinIO
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3Store keeps blobs in a bucket of Amazon S3 or any S3-compatible service such as MinIO,
+// which also serves as a local stand-in during development.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store connects to the object store. The bucket must already exist.
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("BLOB_STORE=s3 requires S3_ENDPOINT and S3_BUCKET")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy; Stat surfaces a missing key before the caller starts reading.
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
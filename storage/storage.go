@@ -0,0 +1,43 @@
+// Package storage keeps uploaded files outside the database. Files are addressed by a key
+// chosen by the caller, such as "<tenantId>/documents/<documentId>".
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/your-username/onboarding/config"
+)
+
+// ErrNotFound is returned when no blob exists under a key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores and retrieves files.
+type BlobStore interface {
+	// Put stores size bytes from r under key, replacing any existing blob.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the blob store configured by BLOB_STORE.
+func New() (BlobStore, error) {
+	switch config.AppConfig.BlobStore {
+	case "", "local":
+		return NewLocalStore(config.AppConfig.BlobDir)
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  config.AppConfig.S3Endpoint,
+			Region:    config.AppConfig.S3Region,
+			Bucket:    config.AppConfig.S3Bucket,
+			AccessKey: config.AppConfig.S3AccessKey,
+			SecretKey: config.AppConfig.S3SecretKey,
+			UseSSL:    config.AppConfig.S3UseSSL,
+		})
+	}
+	return nil, fmt.Errorf("unknown BLOB_STORE %q, use \"local\" or \"s3\"", config.AppConfig.BlobStore)
+}
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedGraphFormat is returned for graph formats other than DOT, Mermaid and SVG.
+var ErrUnsupportedGraphFormat = errors.New("unsupported graph format, use dot, mermaid or svg")
+
+// TreeNode is one box of a rendered tree. The first line of the label is its title.
+type TreeNode struct {
+	ID       string
+	Lines    []string
+	Children []*TreeNode
+}
+
+// RenderTree writes a forest as Graphviz DOT ("dot"), Mermaid flowchart text ("mermaid")
+// or a standalone SVG image ("svg"). The nodes must form a tree: no node may be reachable
+// twice.
+func RenderTree(format string, w io.Writer, roots []*TreeNode) error {
+	out := bufio.NewWriter(w)
+	switch format {
+	case "dot":
+		renderDOT(out, roots)
+	case "mermaid":
+		renderMermaid(out, roots)
+	case "svg":
+		renderSVG(out, roots)
+	default:
+		return ErrUnsupportedGraphFormat
+	}
+	return out.Flush()
+}
+
+// walkTree calls fn for every node and its parent (nil for roots), parents first.
+func walkTree(roots []*TreeNode, fn func(node, parent *TreeNode)) {
+	var walk func(node, parent *TreeNode)
+	walk = func(node, parent *TreeNode) {
+		fn(node, parent)
+		for _, child := range node.Children {
+			walk(child, node)
+		}
+	}
+	for _, root := range roots {
+		walk(root, nil)
+	}
+}
+
+// --- DOT ---
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ")
+
+func renderDOT(w *bufio.Writer, roots []*TreeNode) {
+	w.WriteString("digraph Tree {\n")
+	w.WriteString("  node [shape=box, style=\"rounded\", fontname=\"Helvetica\"];\n")
+	walkTree(roots, func(node, parent *TreeNode) {
+		lines := make([]string, len(node.Lines))
+		for i, line := range node.Lines {
+			lines[i] = dotEscaper.Replace(line)
+		}
+		fmt.Fprintf(w, "  \"%s\" [label=\"%s\"];\n", dotEscaper.Replace(node.ID), strings.Join(lines, `\n`))
+		if parent != nil {
+			fmt.Fprintf(w, "  \"%s\" -> \"%s\";\n", dotEscaper.Replace(parent.ID), dotEscaper.Replace(node.ID))
+		}
+	})
+	w.WriteString("}\n")
+}
+
+// --- Mermaid ---
+
+// Mermaid labels are quoted and may not contain quotes or markup, so those characters
+// are written as Mermaid entity codes.
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "#", "#35;", "\n", " ")
+
+// mermaidID turns a node ID into an identifier Mermaid accepts.
+func mermaidID(id string) string {
+	var b strings.Builder
+	b.WriteString("n")
+	for _, r := range id {
+		if r < utf8.RuneSelf && (r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func renderMermaid(w *bufio.Writer, roots []*TreeNode) {
+	w.WriteString("flowchart TD\n")
+	walkTree(roots, func(node, parent *TreeNode) {
+		lines := make([]string, len(node.Lines))
+		for i, line := range node.Lines {
+			lines[i] = mermaidEscaper.Replace(line)
+		}
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", mermaidID(node.ID), strings.Join(lines, "<br/>"))
+		if parent != nil {
+			fmt.Fprintf(w, "  %s --> %s\n", mermaidID(parent.ID), mermaidID(node.ID))
+		}
+	})
+}
+
+// --- SVG ---
+
+// Box geometry of the SVG layout, in pixels. Every box has the same size so the
+// levels line up; labels that do not fit are shortened.
+const (
+	svgBoxWidth   = 200
+	svgLineHeight = 16
+	svgPadding    = 10
+	svgGapX       = 20
+	svgGapY       = 40
+	svgMargin     = 20
+	svgMaxChars   = 26
+)
+
+type svgBox struct {
+	node *TreeNode
+	x, y int // Top-left corner
+}
+
+// layoutTree places the leaves side by side from left to right and centres every parent
+// above its children. It returns the boxes, parents first, and the width and height used.
+func layoutTree(roots []*TreeNode, boxHeight int) (boxes []*svgBox, width, height int) {
+	next := svgMargin
+	maxDepth := -1
+	var place func(node *TreeNode, depth int) *svgBox
+	place = func(node *TreeNode, depth int) *svgBox {
+		box := &svgBox{node: node, y: svgMargin + depth*(boxHeight+svgGapY)}
+		boxes = append(boxes, box)
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		if len(node.Children) == 0 {
+			box.x = next
+			next += svgBoxWidth + svgGapX
+			return box
+		}
+		var first, last *svgBox
+		for i, child := range node.Children {
+			childBox := place(child, depth+1)
+			if i == 0 {
+				first = childBox
+			}
+			last = childBox
+		}
+		box.x = (first.x + last.x) / 2
+		return box
+	}
+	for _, root := range roots {
+		place(root, 0)
+	}
+	width = next - svgGapX + svgMargin
+	height = svgMargin*2 + (maxDepth+1)*(boxHeight+svgGapY) - svgGapY
+	if len(roots) == 0 {
+		width, height = svgMargin*2, svgMargin*2
+	}
+	return boxes, width, height
+}
+
+// shorten cuts a label line to the characters that fit in a box.
+func shorten(line string) string {
+	if utf8.RuneCountInString(line) <= svgMaxChars {
+		return line
+	}
+	return string([]rune(line)[:svgMaxChars-1]) + "…"
+}
+
+func svgText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func renderSVG(w *bufio.Writer, roots []*TreeNode) {
+	lines := 1
+	walkTree(roots, func(node, _ *TreeNode) {
+		if len(node.Lines) > lines {
+			lines = len(node.Lines)
+		}
+	})
+	boxHeight := lines*svgLineHeight + 2*svgPadding
+	boxes, width, height := layoutTree(roots, boxHeight)
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="12">`+"\n", width, height, width, height)
+
+	// 1. Connectors, drawn first so the boxes cover their ends.
+	positions := make(map[*TreeNode]*svgBox, len(boxes))
+	for _, box := range boxes {
+		positions[box.node] = box
+	}
+	w.WriteString(`<g fill="none" stroke="#888888" stroke-width="1">` + "\n")
+	for _, box := range boxes {
+		for _, child := range box.node.Children {
+			childBox := positions[child]
+			midY := box.y + boxHeight + svgGapY/2
+			fmt.Fprintf(w, `<path d="M%d %d V%d H%d V%d"/>`+"\n",
+				box.x+svgBoxWidth/2, box.y+boxHeight, midY, childBox.x+svgBoxWidth/2, childBox.y)
+		}
+	}
+	w.WriteString("</g>\n")
+
+	// 2. Boxes with their labels; the first line is the title.
+	for _, box := range boxes {
+		fmt.Fprintf(w, `<g><rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="#ffffff" stroke="#333333"/>`,
+			box.x, box.y, svgBoxWidth, boxHeight)
+		for i, line := range box.node.Lines {
+			weight := "normal"
+			if i == 0 {
+				weight = "bold"
+			}
+			fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="middle" font-weight="%s">%s</text>`,
+				box.x+svgBoxWidth/2, box.y+svgPadding+(i+1)*svgLineHeight-4, weight, svgText(shorten(line)))
+		}
+		w.WriteString("</g>\n")
+	}
+	w.WriteString("</svg>\n")
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrUnsupportedFormat is returned for files that are neither CSV nor XLSX.
+var ErrUnsupportedFormat = errors.New("unsupported file format, use .csv or .xlsx")
+
+// ReadSpreadsheet reads a CSV file, or the first sheet of an XLSX file, into rows of cells.
+// The format is chosen from the extension of filename.
+func ReadSpreadsheet(filename string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1 // Rows may have trailing empty cells omitted.
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		// Spreadsheet programs often prepend a UTF-8 byte order mark to CSV exports.
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+		}
+		return rows, nil
+	case ".xlsx":
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return file.GetRows(file.GetSheetName(0))
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// RowWriter writes tabular data row by row, so large exports never have to be held in memory.
+type RowWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// NewRowWriter creates a RowWriter for "csv", "xlsx" or "ndjson" and writes the header.
+// For NDJSON the header is not written; it provides the keys of each JSON object instead.
+func NewRowWriter(format string, w io.Writer, header []string) (RowWriter, error) {
+	switch format {
+	case "csv":
+		writer := &csvRowWriter{writer: csv.NewWriter(w)}
+		return writer, writer.WriteRow(header)
+	case "xlsx":
+		file := excelize.NewFile()
+		stream, err := file.NewStreamWriter(file.GetSheetName(0))
+		if err != nil {
+			return nil, err
+		}
+		writer := &xlsxRowWriter{file: file, stream: stream, out: w}
+		return writer, writer.WriteRow(header)
+	case "ndjson":
+		return &ndjsonRowWriter{encoder: json.NewEncoder(w), keys: header}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvRowWriter struct {
+	writer *csv.Writer
+}
+
+// WriteRow writes the cells, defusing those that a spreadsheet program would run as a
+// formula: they are prefixed with an apostrophe, which makes the program show them as text.
+func (w *csvRowWriter) WriteRow(cells []string) error {
+	safe := make([]string, len(cells))
+	for i, cell := range cells {
+		safe[i] = cell
+		if isFormula(cell) {
+			safe[i] = "'" + cell
+		}
+	}
+	return w.writer.Write(safe)
+}
+
+// isFormula reports whether a CSV cell would be read as a formula. Plain numbers such as
+// -42 are left alone.
+func isFormula(cell string) bool {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return false
+	}
+	_, err := strconv.ParseFloat(cell, 64)
+	return err != nil
+}
+
+func (w *csvRowWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// xlsxRowWriter uses excelize's stream writer, which spills rows to a temporary file
+// instead of keeping the whole worksheet in memory.
+type xlsxRowWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	out    io.Writer
+	row    int
+}
+
+func (w *xlsxRowWriter) WriteRow(cells []string) error {
+	w.row++
+	values := make([]interface{}, len(cells))
+	for i, cell := range cells {
+		values[i] = cell
+	}
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxRowWriter) Close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
+
+type ndjsonRowWriter struct {
+	encoder *json.Encoder
+	keys    []string
+}
+
+func (w *ndjsonRowWriter) WriteRow(cells []string) error {
+	record := make(map[string]string, len(w.keys))
+	for i, key := range w.keys {
+		if i < len(cells) {
+			record[key] = cells[i]
+		}
+	}
+	return w.encoder.Encode(record)
+}
+
+func (w *ndjsonRowWriter) Close() error {
+	return nil
+}
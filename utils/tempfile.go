@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"io"
+	"os"
+)
+
+// TempFile is a temporary file that is deleted from disk when it is closed.
+type TempFile struct {
+	*os.File
+}
+
+// Close closes and removes the file.
+func (f *TempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+// TempFileFrom lets write fill a new temporary file and returns it together with its size,
+// ready to be read from the start. It is used to stage data that is too large for memory.
+func TempFileFrom(write func(w io.Writer) error) (*TempFile, int64, error) {
+	file, err := os.CreateTemp("", "onboarding-*")
+	if err != nil {
+		return nil, 0, err
+	}
+	temp := &TempFile{File: file}
+
+	if err := write(file); err != nil {
+		temp.Close()
+		return nil, 0, err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		temp.Close()
+		return nil, 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		temp.Close()
+		return nil, 0, err
+	}
+	return temp, size, nil
+}